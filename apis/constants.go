@@ -7,4 +7,8 @@ import (
 const (
 	EvaluationModeEnvoy vpol.EvaluationMode = "Envoy"
 	EvaluationModeHTTP  vpol.EvaluationMode = "HTTP"
+	EvaluationModeJSON  vpol.EvaluationMode = "JSON"
+	EvaluationModeMCP   vpol.EvaluationMode = "MCP"
+	// EvaluationModeSubjectAccessReview evaluates kube-apiserver authorization webhook requests
+	EvaluationModeSubjectAccessReview vpol.EvaluationMode = "SubjectAccessReview"
 )
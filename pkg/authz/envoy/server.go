@@ -3,59 +3,31 @@ package envoy
 import (
 	"context"
 	"net"
+	"time"
 
 	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sequential"
 	"github.com/kyverno/kyverno-authz/pkg/events"
 	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"github.com/kyverno/kyverno-authz/pkg/probes"
 	"github.com/kyverno/kyverno-authz/pkg/server"
-	"github.com/kyverno/sdk/core"
-	"github.com/kyverno/sdk/core/dispatchers"
-	"github.com/kyverno/sdk/core/handlers"
-	"github.com/kyverno/sdk/core/resulters"
-	"github.com/kyverno/sdk/extensions/policy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	"k8s.io/client-go/dynamic"
 )
 
-func NewServer(network, addr string, source engine.EnvoySource, dynclient dynamic.Interface, eventHandler events.EventIface[*authv3.CheckRequest]) server.ServerFunc {
+func NewServer(network, addr string, source engine.EnvoySource, dynclient dynamic.Interface, eventHandler events.EventIface[*authv3.CheckRequest], ready func() bool, healthCheckInterval time.Duration) server.ServerFunc {
 	return func(ctx context.Context) error {
 		// create a server
 		s := grpc.NewServer()
 		// build the engine
-		engine := core.NewEngine(
-			source,
-			handlers.Handler(
-				dispatchers.Sequential(
-					metrics.MetricsEvaluatorFactory(
-						policy.EvaluatorFactory[engine.EnvoyPolicy](),
-						func(out policy.Evaluation[*authv3.CheckResponse]) string {
-							if out.Error != nil {
-								return metrics.DecisionError
-							}
-							if out.Result == nil {
-								return metrics.DecisionNoMatch
-							}
-							if out.Result.GetDeniedResponse() != nil {
-								return metrics.DecisionDeny
-							}
-							return metrics.DecisionAllow
-						},
-					),
-					func(ctx context.Context, fc core.FactoryContext[engine.EnvoyPolicy, dynamic.Interface, *authv3.CheckRequest]) core.Breaker[engine.EnvoyPolicy, *authv3.CheckRequest, policy.Evaluation[*authv3.CheckResponse]] {
-						return core.MakeBreakerFunc(func(_ context.Context, _ engine.EnvoyPolicy, _ *authv3.CheckRequest, out policy.Evaluation[*authv3.CheckResponse]) bool {
-							return out.Result != nil
-						})
-					},
-				),
-				func(ctx context.Context, fc core.FactoryContext[engine.EnvoyPolicy, dynamic.Interface, *authv3.CheckRequest]) core.Resulter[engine.EnvoyPolicy, *authv3.CheckRequest, policy.Evaluation[*authv3.CheckResponse], policy.Evaluation[*authv3.CheckResponse]] {
-					return resulters.NewFirst[engine.EnvoyPolicy, *authv3.CheckRequest](func(out policy.Evaluation[*authv3.CheckResponse]) bool {
-						return out.Result != nil || out.Error != nil
-					})
-				},
-			),
-		)
+		engine := sequential.NewEngine(source, func(result *authv3.CheckResponse) string {
+			if result.GetDeniedResponse() != nil {
+				return metrics.DecisionDeny
+			}
+			return metrics.DecisionAllow
+		})
 		// setup our authorization service
 		svc := &service{
 			engine:       engine,
@@ -64,6 +36,8 @@ func NewServer(network, addr string, source engine.EnvoySource, dynclient dynami
 		}
 		// register our authorization service
 		authv3.RegisterAuthorizationServer(s, svc)
+		// register health service, the authorization service is reported serving only when ready
+		probes.RegisterGrpcHealth(ctx, s, ready, healthCheckInterval, authv3.Authorization_ServiceDesc.ServiceName)
 		// register reflection service
 		reflection.Register(s)
 		// create a listener
@@ -0,0 +1,49 @@
+package generic
+
+import (
+	"context"
+	"time"
+
+	genericcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"k8s.io/client-go/dynamic"
+)
+
+// checker evaluates payloads against the engine, it is shared by the http and grpc servers
+type checker struct {
+	engine       Engine
+	dyn          dynamic.Interface
+	eventHandler events.EventIface[any]
+}
+
+func (c *checker) check(ctx context.Context, payload any) (*genericcel.CheckResponse, error) {
+	start := time.Now()
+	decision := metrics.DecisionError
+	source := metrics.SourceEngine
+	defer func() {
+		metrics.RecordAuthzDecision(metrics.ModeJSON, decision, source, start)
+	}()
+	response := c.engine.Handle(ctx, c.dyn, payload)
+	if response.Error != nil {
+		c.eventHandler.Push(context.Background(), time.Now(), payload, events.NewResultAccessor(nil, response.Error))
+		return nil, response.Error
+	}
+	result := response.Result
+	if result == nil {
+		// no policy returned a decision, allow by default like the other modes
+		result = &genericcel.CheckResponse{
+			Allowed: true,
+		}
+		decision = metrics.DecisionAllow
+		source = metrics.SourceDefault
+	} else if !result.Allowed {
+		decision = metrics.DecisionDeny
+		source = metrics.SourcePolicy
+	} else {
+		decision = metrics.DecisionAllow
+		source = metrics.SourcePolicy
+	}
+	c.eventHandler.Push(context.Background(), time.Now(), payload, events.NewResultAccessor(*result, nil))
+	return result, nil
+}
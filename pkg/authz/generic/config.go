@@ -0,0 +1,15 @@
+package generic
+
+import (
+	"time"
+)
+
+type Config struct {
+	HttpAddress         string
+	GrpcNetwork         string
+	GrpcAddress         string
+	MaxBodySize         int64
+	CertFile            string
+	KeyFile             string
+	HealthCheckInterval time.Duration
+}
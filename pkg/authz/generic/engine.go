@@ -0,0 +1,20 @@
+package generic
+
+import (
+	genericcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sequential"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+)
+
+type Engine = sequential.Engine[any, genericcel.CheckResponse]
+
+// NewEngine builds an engine evaluating policies sequentially until one of them returns a result
+func NewEngine(source engine.JSONSource) Engine {
+	return sequential.NewEngine(source, func(result *genericcel.CheckResponse) string {
+		if !result.Allowed {
+			return metrics.DecisionDeny
+		}
+		return metrics.DecisionAllow
+	})
+}
@@ -0,0 +1,110 @@
+package generic
+
+import (
+	"context"
+
+	genericcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the name of the grpc authorization service, it is defined as:
+//
+//	service Authorization {
+//	  rpc Check(google.protobuf.Value) returns (google.protobuf.Struct);
+//	}
+const ServiceName = "kyverno.authz.json.v1.Authorization"
+
+// init registers the service file descriptor so that it can be discovered with the reflection service
+func init() {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String(serviceDesc.Metadata.(string)),
+		Package:    proto.String("kyverno.authz.json.v1"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Authorization"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Check"),
+				InputType:  proto.String(".google.protobuf.Value"),
+				OutputType: proto.String(".google.protobuf.Struct"),
+			}},
+		}},
+		Syntax: proto.String("proto3"),
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+		panic(err)
+	}
+}
+
+type authorizationServer interface {
+	Check(context.Context, *structpb.Value) (*structpb.Struct, error)
+}
+
+// serviceDesc is written by hand, the service only uses well known types and doesn't need generated code
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*authorizationServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Check",
+		Handler:    checkHandler,
+	}},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kyverno/authz/json/v1/authorization.proto",
+}
+
+func checkHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(structpb.Value)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(authorizationServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/Check",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(authorizationServer).Check(ctx, req.(*structpb.Value))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type service struct {
+	checker
+}
+
+func (s *service) Check(ctx context.Context, in *structpb.Value) (*structpb.Struct, error) {
+	result, err := s.check(ctx, in.AsInterface())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out, err := toStruct(result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
+
+// toStruct converts a decision to a struct with the same fields as its json representation
+func toStruct(result *genericcel.CheckResponse) (*structpb.Struct, error) {
+	fields := map[string]any{
+		"allowed": result.Allowed,
+	}
+	if result.Reason != "" {
+		fields["reason"] = result.Reason
+	}
+	if len(result.Obligations) != 0 {
+		fields["obligations"] = result.Obligations
+	}
+	return structpb.NewStruct(fields)
+}
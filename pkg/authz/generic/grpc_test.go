@@ -0,0 +1,142 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	genericcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/sdk/extensions/policy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/client-go/dynamic"
+)
+
+// engineFunc returns the evaluation of the function for every payload
+type engineFunc func(any) policy.Evaluation[*genericcel.CheckResponse]
+
+func (f engineFunc) Handle(_ context.Context, _ dynamic.Interface, payload any) policy.Evaluation[*genericcel.CheckResponse] {
+	return f(payload)
+}
+
+// userEngine allows payloads with user alice, denies payloads with user bob, fails payloads with user error
+// and returns no result for everything else
+var userEngine = engineFunc(func(payload any) policy.Evaluation[*genericcel.CheckResponse] {
+	object, _ := payload.(map[string]any)
+	switch object["user"] {
+	case "alice":
+		return policy.Evaluation[*genericcel.CheckResponse]{
+			Result: &genericcel.CheckResponse{Allowed: true, Obligations: map[string]any{"log": true}},
+		}
+	case "bob":
+		return policy.Evaluation[*genericcel.CheckResponse]{
+			Result: &genericcel.CheckResponse{Reason: "bob is not allowed"},
+		}
+	case "error":
+		return policy.Evaluation[*genericcel.CheckResponse]{Error: errors.New("boom")}
+	default:
+		return policy.Evaluation[*genericcel.CheckResponse]{}
+	}
+})
+
+// dialService starts a grpc server with the authorization service and returns a client connection to it
+func dialService(t *testing.T, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+	l := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	s.RegisterService(&serviceDesc, &service{checker: checker{engine: userEngine, eventHandler: events.NewComposite[any]()}})
+	go func() {
+		_ = s.Serve(l)
+	}()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestServiceDescriptor(t *testing.T) {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(ServiceName)
+	assert.NoError(t, err)
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	assert.True(t, ok)
+	method := service.Methods().ByName("Check")
+	assert.NotNil(t, method)
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Value"), method.Input().FullName())
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Struct"), method.Output().FullName())
+	assert.Equal(t, serviceDesc.Metadata, service.ParentFile().Path())
+}
+
+func TestServiceCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  map[string]any
+		want     map[string]any
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:    "allowed with obligations",
+		payload: map[string]any{"user": "alice"},
+		want:    map[string]any{"allowed": true, "obligations": map[string]any{"log": true}},
+	}, {
+		name:    "denied with reason",
+		payload: map[string]any{"user": "bob"},
+		want:    map[string]any{"allowed": false, "reason": "bob is not allowed"},
+	}, {
+		name:    "no result allows by default",
+		payload: map[string]any{"user": "carol"},
+		want:    map[string]any{"allowed": true},
+	}, {
+		name:     "engine error",
+		payload:  map[string]any{"user": "error"},
+		wantCode: codes.Internal,
+		wantMsg:  "boom",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialService(t)
+			in, err := structpb.NewValue(tt.payload)
+			assert.NoError(t, err)
+			var out structpb.Struct
+			err = conn.Invoke(context.Background(), "/"+ServiceName+"/Check", in, &out)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.Equal(t, tt.wantMsg, status.Convert(err).Message())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.AsMap())
+		})
+	}
+}
+
+func TestServiceInterceptor(t *testing.T) {
+	var fullMethod string
+	conn := dialService(t, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		fullMethod = info.FullMethod
+		return handler(ctx, req)
+	}))
+	in, err := structpb.NewValue(map[string]any{"user": "alice"})
+	assert.NoError(t, err)
+	var out structpb.Struct
+	assert.NoError(t, conn.Invoke(context.Background(), "/"+ServiceName+"/Check", in, &out))
+	assert.Equal(t, "/"+ServiceName+"/Check", fullMethod)
+	assert.Equal(t, true, out.AsMap()["allowed"])
+}
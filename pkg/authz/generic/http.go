@@ -0,0 +1,56 @@
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// httpHandler decodes the json request body and answers with the json encoded decision
+type httpHandler struct {
+	checker
+	maxBodySize int64
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := ctrl.LoggerFrom(r.Context()).WithValues("from", r.RemoteAddr)
+	logger.Info("received request")
+	if h.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	}
+	var payload any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrResp(logger, w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			writeErrResp(logger, w, http.StatusBadRequest, fmt.Errorf("failed to decode payload: %w", err))
+		}
+		return
+	}
+	result, err := h.check(r.Context(), payload)
+	if err != nil {
+		writeErrResp(logger, w, http.StatusInternalServerError, err)
+		return
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeErrResp(logger, w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logger.Error(err, "failed to write body")
+	}
+}
+
+func writeErrResp(logger logr.Logger, w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, err.Error()) //nolint:errcheck
+	logger.Error(err, "an error has occurred")
+}
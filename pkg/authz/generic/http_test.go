@@ -0,0 +1,68 @@
+package generic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		maxBodySize int64
+		wantStatus  int
+		wantBody    string
+	}{{
+		name:       "allowed with obligations",
+		body:       `{"user":"alice"}`,
+		wantStatus: http.StatusOK,
+		wantBody:   `{"allowed":true,"obligations":{"log":true}}`,
+	}, {
+		name:       "denied with reason",
+		body:       `{"user":"bob"}`,
+		wantStatus: http.StatusOK,
+		wantBody:   `{"allowed":false,"reason":"bob is not allowed"}`,
+	}, {
+		name:       "no result allows by default",
+		body:       `{"user":"carol"}`,
+		wantStatus: http.StatusOK,
+		wantBody:   `{"allowed":true}`,
+	}, {
+		name:       "engine error",
+		body:       `{"user":"error"}`,
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "boom",
+	}, {
+		name:       "invalid payload",
+		body:       `{`,
+		wantStatus: http.StatusBadRequest,
+		wantBody:   "failed to decode payload: unexpected EOF",
+	}, {
+		name:        "body too large",
+		body:        `{"user":"alice"}`,
+		maxBodySize: 4,
+		wantStatus:  http.StatusRequestEntityTooLarge,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &httpHandler{
+				checker:     checker{engine: userEngine, eventHandler: events.NewComposite[any]()},
+				maxBodySize: tt.maxBodySize,
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			} else if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package generic
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/probes"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// NewServer serves the same engine over http and grpc, a server is disabled when its address is empty
+func NewServer(config Config, source engine.JSONSource, dyn dynamic.Interface, eventIface events.EventIface[any], ready func() bool) server.ServerFunc {
+	return func(ctx context.Context) error {
+		// build the engine
+		c := checker{
+			engine:       NewEngine(source),
+			dyn:          dyn,
+			eventHandler: eventIface,
+		}
+		var servers []server.ServerFunc
+		if config.HttpAddress != "" {
+			// create mux
+			mux := http.NewServeMux()
+			mux.Handle("POST /{$}", &httpHandler{checker: c, maxBodySize: config.MaxBodySize})
+			// create server
+			s := &http.Server{
+				Addr:    config.HttpAddress,
+				Handler: mux,
+			}
+			// serve TLS if a certfile and a keyfile are provided
+			if config.CertFile != "" && config.KeyFile != "" {
+				s.TLSConfig = server.TLSConfig()
+			}
+			servers = append(servers, func(ctx context.Context) error {
+				return server.RunHttp(ctx, s, config.CertFile, config.KeyFile)
+			})
+		}
+		if config.GrpcAddress != "" {
+			// create a server
+			s := grpc.NewServer()
+			// register our authorization service
+			s.RegisterService(&serviceDesc, &service{checker: c})
+			// register health service, the authorization service is reported serving only when ready
+			probes.RegisterGrpcHealth(ctx, s, ready, config.HealthCheckInterval, ServiceName)
+			// register reflection service
+			reflection.Register(s)
+			// create a listener
+			l, err := net.Listen(config.GrpcNetwork, config.GrpcAddress)
+			if err != nil {
+				return err
+			}
+			servers = append(servers, func(ctx context.Context) error {
+				return server.RunGrpc(ctx, s, l)
+			})
+		}
+		// create a cancellable context
+		ctx, cancel := context.WithCancel(ctx)
+		// cancel context at the end
+		defer cancel()
+		// run servers, when one of them stops the others are stopped too
+		var group wait.Group
+		errs := make([]error, len(servers))
+		for i, s := range servers {
+			group.StartWithContext(ctx, func(ctx context.Context) {
+				defer cancel()
+				errs[i] = s.Run(ctx)
+			})
+		}
+		// wait all tasks in the group are over
+		group.Wait()
+		return multierr.Combine(errs...)
+	}
+}
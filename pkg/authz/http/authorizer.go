@@ -3,6 +3,7 @@ package http
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -26,6 +27,8 @@ type authorizer struct {
 	inputProgram  cel.Program
 	outputProgram cel.Program
 	nestedRequest bool
+	maxBodySize   int64
+	profile       *Profile
 	eventHandler  events.EventIface[httpcel.CheckRequest]
 }
 
@@ -35,6 +38,8 @@ func NewAuthorizer(
 	inputProg cel.Program,
 	outputProg cel.Program,
 	nestedRequest bool,
+	maxBodySize int64,
+	profile *Profile,
 	eventIface events.EventIface[httpcel.CheckRequest]) *authorizer {
 	return &authorizer{
 		engine:        e,
@@ -42,6 +47,8 @@ func NewAuthorizer(
 		inputProgram:  inputProg,
 		outputProgram: outputProg,
 		nestedRequest: nestedRequest,
+		maxBodySize:   maxBodySize,
+		profile:       profile,
 		eventHandler:  eventIface,
 	}
 }
@@ -55,6 +62,10 @@ func (a *authorizer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}()
 	logger := ctrl.LoggerFrom(r.Context()).WithValues("from", r.RemoteAddr)
 	logger.Info("received request")
+	// limit the size of the body, when the request is nested this also limits the size of the nested request
+	if a.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, a.maxBodySize)
+	}
 	if a.nestedRequest {
 		reader := bufio.NewReader(r.Body)
 		req, err := http.ReadRequest(reader)
@@ -66,7 +77,7 @@ func (a *authorizer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	httpReq, err := httpcel.NewRequest(r)
 	if err != nil {
-		writeErrResp(logger, w, err)
+		writeErrResp(logger, w, &badRequestError{err})
 		return
 	}
 	if a.inputProgram != nil {
@@ -110,6 +121,16 @@ func (a *authorizer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// result will never be nil here because we set it in the block above
 	a.eventHandler.Push(context.Background(), time.Now(), httpReq, events.NewResultAccessor(*result, nil))
 	defer metrics.RecordHTTPRequest(r.Context(), start, httpReq, result)
+	if a.profile != nil && result.Ok != nil {
+		ok, err := a.profile.translateMutations(result.Ok)
+		if err != nil {
+			decision = metrics.DecisionError
+			source = metrics.SourceServer
+			writeErrResp(logger, w, err)
+			return
+		}
+		result = &httpcel.CheckResponse{Ok: ok}
+	}
 	out, _, err := a.outputProgram.Eval(map[string]any{
 		"object": result,
 	})
@@ -128,8 +149,30 @@ func (a *authorizer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// badRequestError is answered with a 400 status instead of a 500 status
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *badRequestError) Unwrap() error {
+	return e.err
+}
+
 func writeErrResp(logger logr.Logger, w http.ResponseWriter, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
+	var maxBytesErr *http.MaxBytesError
+	var badRequestErr *badRequestError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case errors.As(err, &badRequestErr):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
 	fmt.Fprint(w, err.Error()) //nolint:errcheck
 	logger.Error(err, "an error has occurred")
 }
@@ -137,8 +180,9 @@ func writeErrResp(logger logr.Logger, w http.ResponseWriter, err error) {
 func writeResponse(logger logr.Logger, w http.ResponseWriter, resp httpserver.HttpResponse) {
 	if resp.Header != nil {
 		for k, v := range resp.Header {
+			w.Header().Del(k)
 			for _, val := range v {
-				w.Header().Set(k, val)
+				w.Header().Add(k, val)
 			}
 		}
 	}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/google/cel-go/cel"
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// BatchRequest holds the requests of a batch, they are decoded one by one so that an invalid
+// request only fails its own decision
+type BatchRequest struct {
+	Requests []json.RawMessage `json:"requests"`
+}
+
+type BatchResponse struct {
+	Responses []BatchResult `json:"responses"`
+}
+
+// BatchResult holds the decision for a single request, exactly one of the fields is set
+type BatchResult struct {
+	Ok     *httpcel.CheckResponseOk     `json:"ok,omitempty"`
+	Denied *httpcel.CheckResponseDenied `json:"denied,omitempty"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+// BatchDecoder decodes a single request of a batch
+type BatchDecoder func([]byte) (httpcel.CheckRequest, error)
+
+// DecodeRequest decodes an http.CheckRequest
+func DecodeRequest(data []byte) (httpcel.CheckRequest, error) {
+	var request httpcel.CheckRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return httpcel.CheckRequest{}, err
+	}
+	return request, nil
+}
+
+// DecodeEnvoyRequest decodes an Envoy CheckRequest and maps its http attributes to an http.CheckRequest
+func DecodeEnvoyRequest(data []byte) (httpcel.CheckRequest, error) {
+	var envoyReq authv3.CheckRequest
+	if err := protojson.Unmarshal(data, &envoyReq); err != nil {
+		return httpcel.CheckRequest{}, err
+	}
+	attrs := envoyReq.GetAttributes().GetRequest().GetHttp()
+	if attrs == nil {
+		return httpcel.CheckRequest{}, errors.New("envoy request has no http attributes")
+	}
+	u, err := url.ParseRequestURI(attrs.GetPath())
+	if err != nil {
+		return httpcel.CheckRequest{}, fmt.Errorf("invalid envoy request path: %w", err)
+	}
+	header := http.Header{}
+	for key, value := range attrs.GetHeaders() {
+		// pseudo headers are mapped to the request attributes
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+		header.Set(key, value)
+	}
+	body := attrs.GetRawBody()
+	if body == nil && attrs.GetBody() != "" {
+		body = []byte(attrs.GetBody())
+	}
+	contentLength := attrs.GetSize()
+	if contentLength < 0 {
+		contentLength = int64(len(body))
+	}
+	return httpcel.CheckRequest{
+		Attributes: httpcel.CheckRequestAttributes{
+			Method:        attrs.GetMethod(),
+			Header:        header,
+			Host:          attrs.GetHost(),
+			Protocol:      attrs.GetProtocol(),
+			ContentLength: contentLength,
+			Body:          body,
+			Scheme:        attrs.GetScheme(),
+			Path:          u.Path,
+			Query:         u.Query(),
+			Fragment:      attrs.GetFragment(),
+		},
+	}, nil
+}
+
+// batcher evaluates a list of requests concurrently and returns a decision per request
+type batcher struct {
+	engine       Engine
+	dyn          dynamic.Interface
+	inputProgram cel.Program
+	decode       BatchDecoder
+	maxBodySize  int64
+	maxBatchSize int
+	concurrency  int
+	eventHandler events.EventIface[httpcel.CheckRequest]
+}
+
+func NewBatcher(
+	e Engine,
+	dyn dynamic.Interface,
+	inputProg cel.Program,
+	decode BatchDecoder,
+	maxBodySize int64,
+	maxBatchSize int,
+	concurrency int,
+	eventIface events.EventIface[httpcel.CheckRequest]) *batcher {
+	return &batcher{
+		engine:       e,
+		dyn:          dyn,
+		inputProgram: inputProg,
+		decode:       decode,
+		maxBodySize:  maxBodySize,
+		maxBatchSize: maxBatchSize,
+		concurrency:  max(concurrency, 1),
+		eventHandler: eventIface,
+	}
+}
+
+func (b *batcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := ctrl.LoggerFrom(r.Context()).WithValues("from", r.RemoteAddr)
+	logger.Info("received batch request")
+	if b.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, b.maxBodySize)
+	}
+	var batch BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		writeErrResp(logger, w, &badRequestError{err: fmt.Errorf("failed to decode batch request: %w", err)})
+		return
+	}
+	if b.maxBatchSize > 0 && len(batch.Requests) > b.maxBatchSize {
+		writeErrResp(logger, w, &badRequestError{err: fmt.Errorf("batch contains %d requests, the maximum is %d", len(batch.Requests), b.maxBatchSize)})
+		return
+	}
+	results := make([]BatchResult, len(batch.Requests))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, b.concurrency)
+	for i := range batch.Requests {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			results[i] = b.check(r.Context(), batch.Requests[i])
+		})
+	}
+	wg.Wait()
+	body, err := json.Marshal(BatchResponse{Responses: results})
+	if err != nil {
+		writeErrResp(logger, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logger.Error(err, "failed to write body")
+	}
+}
+
+func (b *batcher) check(ctx context.Context, data []byte) BatchResult {
+	start := time.Now()
+	decision := metrics.DecisionError
+	source := metrics.SourceServer
+	defer func() {
+		metrics.RecordAuthzDecision(metrics.ModeHTTP, decision, source, start)
+	}()
+	httpReq, err := b.decode(data)
+	if err != nil {
+		return BatchResult{Error: fmt.Sprintf("failed to decode request: %s", err)}
+	}
+	if b.inputProgram != nil {
+		out, _, err := b.inputProgram.Eval(map[string]any{
+			"object": &httpReq,
+		})
+		if err != nil {
+			return BatchResult{Error: err.Error()}
+		}
+		if out, ok := out.Value().(*httpcel.CheckRequest); ok && out != nil {
+			httpReq = *out
+		}
+	}
+	source = metrics.SourceEngine
+	response := b.engine.Handle(ctx, b.dyn, &httpReq)
+	if response.Error != nil {
+		metrics.RecordHTTPRequestError(ctx, httpReq, response.Error)
+		b.eventHandler.Push(context.Background(), time.Now(), httpReq, events.NewResultAccessor(nil, response.Error))
+		return BatchResult{Error: response.Error.Error()}
+	}
+	result := response.Result
+	if result == nil {
+		result = &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{},
+		}
+		decision = metrics.DecisionAllow
+		source = metrics.SourceDefault
+	} else if result.Denied != nil {
+		decision = metrics.DecisionDeny
+		source = metrics.SourcePolicy
+	} else {
+		decision = metrics.DecisionAllow
+		source = metrics.SourcePolicy
+	}
+	b.eventHandler.Push(context.Background(), time.Now(), httpReq, events.NewResultAccessor(*result, nil))
+	metrics.RecordHTTPRequest(ctx, start, httpReq, result)
+	return BatchResult{Ok: result.Ok, Denied: result.Denied}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/sdk/extensions/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+// pathEngine denies requests to /admin, fails requests to /error and allows everything else
+var pathEngine = engineFunc(func(r *httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+	switch r.Attributes.Path {
+	case "/admin":
+		return policy.Evaluation[*httpcel.CheckResponse]{
+			Result: &httpcel.CheckResponse{Denied: &httpcel.CheckResponseDenied{Reason: "admins only", Status: 403}},
+		}
+	case "/error":
+		return policy.Evaluation[*httpcel.CheckResponse]{Error: errors.New("boom")}
+	case "/nomatch":
+		return policy.Evaluation[*httpcel.CheckResponse]{}
+	default:
+		return policy.Evaluation[*httpcel.CheckResponse]{
+			Result: &httpcel.CheckResponse{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {r.Attributes.Method}}}},
+		}
+	}
+})
+
+func TestBatcher(t *testing.T) {
+	tests := []struct {
+		name            string
+		decode          BatchDecoder
+		inputExpression string
+		maxBodySize     int64
+		maxBatchSize    int
+		body            string
+		wantStatus      int
+		wantBody        string
+		want            []BatchResult
+	}{{
+		name:       "decisions in order",
+		decode:     DecodeRequest,
+		body:       `{"requests":[{"attributes":{"method":"GET","path":"/orders"}},{"attributes":{"path":"/admin"}},{"attributes":{"path":"/nomatch"}}]}`,
+		wantStatus: http.StatusOK,
+		want: []BatchResult{
+			{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {"GET"}}}},
+			{Denied: &httpcel.CheckResponseDenied{Reason: "admins only", Status: 403}},
+			{Ok: &httpcel.CheckResponseOk{}},
+		},
+	}, {
+		name:       "per item errors",
+		decode:     DecodeRequest,
+		body:       `{"requests":[{"attributes":{"path":"/error"}},"not a request",{"attributes":{"method":"POST","path":"/orders"}}]}`,
+		wantStatus: http.StatusOK,
+		want: []BatchResult{
+			{Error: "boom"},
+			{Error: "failed to decode request: json: cannot unmarshal string into Go value of type http.CheckRequest"},
+			{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {"POST"}}}},
+		},
+	}, {
+		name:            "input expression",
+		decode:          DecodeRequest,
+		inputExpression: `http.CheckRequest{ attributes: http.CheckRequestAttributes{ method: "PUT", path: object.attributes.path } }`,
+		body:            `{"requests":[{"attributes":{"method":"GET","path":"/orders"}}]}`,
+		wantStatus:      http.StatusOK,
+		want: []BatchResult{
+			{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {"PUT"}}}},
+		},
+	}, {
+		name:            "input expression error",
+		decode:          DecodeRequest,
+		inputExpression: `http.CheckRequest{ attributes: http.CheckRequestAttributes{ method: object.attributes.Header("x-method")[0] } }`,
+		body:            `{"requests":[{"attributes":{"method":"GET","path":"/orders"}}]}`,
+		wantStatus:      http.StatusOK,
+		want: []BatchResult{
+			{Error: "index out of bounds: 0"},
+		},
+	}, {
+		name:   "envoy requests",
+		decode: DecodeEnvoyRequest,
+		body: `{"requests":[
+			{"attributes":{"request":{"http":{"method":"DELETE","path":"/admin?force=true","host":"app.example.com","headers":{":authority":"app.example.com","x-user":"alice"}}}}},
+			{"attributes":{"request":{"http":{"method":"GET","path":"/orders"}}}},
+			{"attributes":{}}
+		]}`,
+		wantStatus: http.StatusOK,
+		want: []BatchResult{
+			{Denied: &httpcel.CheckResponseDenied{Reason: "admins only", Status: 403}},
+			{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {"GET"}}}},
+			{Error: "failed to decode request: envoy request has no http attributes"},
+		},
+	}, {
+		name:         "too many requests",
+		decode:       DecodeRequest,
+		maxBatchSize: 2,
+		body:         `{"requests":[{},{},{}]}`,
+		wantStatus:   http.StatusBadRequest,
+		wantBody:     "batch contains 3 requests, the maximum is 2",
+	}, {
+		name:        "body too large",
+		decode:      DecodeRequest,
+		maxBodySize: 8,
+		body:        `{"requests":[{},{},{}]}`,
+		wantStatus:  http.StatusRequestEntityTooLarge,
+	}, {
+		name:       "invalid batch",
+		decode:     DecodeRequest,
+		body:       `[]`,
+		wantStatus: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, _, err := compileExpressions(Config{InputExpression: tt.inputExpression}, nil)
+			assert.NoError(t, err)
+			b := NewBatcher(pathEngine, nil, input, tt.decode, tt.maxBodySize, tt.maxBatchSize, 4, events.NewComposite[httpcel.CheckRequest]())
+			w := httptest.NewRecorder()
+			b.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			if tt.want == nil {
+				return
+			}
+			var got BatchResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got.Responses)
+		})
+	}
+}
+
+func TestBatcherConcurrency(t *testing.T) {
+	var inflight, maxInflight atomic.Int32
+	engine := engineFunc(func(*httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+		current := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			seen := maxInflight.Load()
+			if current <= seen || maxInflight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return policy.Evaluation[*httpcel.CheckResponse]{}
+	})
+	b := NewBatcher(engine, nil, nil, DecodeRequest, 0, 0, 3, events.NewComposite[httpcel.CheckRequest]())
+	body := `{"requests":[` + strings.TrimSuffix(strings.Repeat(`{},`, 20), ",") + `]}`
+	w := httptest.NewRecorder()
+	b.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got BatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got.Responses, 20)
+	assert.LessOrEqual(t, maxInflight.Load(), int32(3))
+	assert.Positive(t, maxInflight.Load())
+}
+
+func TestBatcherWithProfile(t *testing.T) {
+	for _, profile := range []string{ProfileCaddy, ProfileOAuth2Proxy, ProfileTraefik} {
+		t.Run(profile, func(t *testing.T) {
+			mux, err := newMux(Config{Profile: profile, BatchConcurrency: 1}, pathEngine, nil, events.NewComposite[httpcel.CheckRequest]())
+			assert.NoError(t, err)
+			// batch items keep their own path, the profile input expression is not applied to them
+			body := `{"requests":[{"attributes":{"method":"GET","path":"/admin"}},{"attributes":{"method":"POST","path":"/orders"}}]}`
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)))
+			assert.Equal(t, http.StatusOK, w.Code)
+			var got BatchResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, []BatchResult{
+				{Denied: &httpcel.CheckResponseDenied{Reason: "admins only", Status: 403}},
+				{Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-Method": {"POST"}}}},
+			}, got.Responses)
+			// forward auth requests are still mapped by the profile
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("X-Forwarded-Uri", "/admin")
+			w = httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+}
@@ -2,9 +2,22 @@ package http
 
 type Config struct {
 	Address          string
+	Profile          string
 	NestedRequest    bool
 	InputExpression  string
 	OutputExpression string
+	MaxBodySize      int64
+	MaxBatchSize     int
+	BatchConcurrency int
 	CertFile         string
 	KeyFile          string
 }
+
+type ProxyConfig struct {
+	Address      string
+	Upstream     string
+	PreserveHost bool
+	MaxBodySize  int64
+	CertFile     string
+	KeyFile      string
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sequential"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+)
+
+type Engine = sequential.Engine[*httpcel.CheckRequest, httpcel.CheckResponse]
+
+// NewEngine builds an engine evaluating policies sequentially until one of them returns a result
+func NewEngine(source engine.HTTPSource) Engine {
+	return sequential.NewEngine(source, func(result *httpcel.CheckResponse) string {
+		if result.Denied != nil {
+			return metrics.DecisionDeny
+		}
+		return metrics.DecisionAllow
+	})
+}
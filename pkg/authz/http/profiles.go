@@ -0,0 +1,171 @@
+package http
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+)
+
+// Profile holds the input and output expressions used to integrate with a forward auth proxy
+type Profile struct {
+	Name             string
+	InputExpression  string
+	OutputExpression string
+	// HeadersToRemoveHeader is the response header the proxy reads the request headers to remove from,
+	// when empty the proxy can't remove request headers
+	HeadersToRemoveHeader string
+}
+
+const (
+	ProfileCaddy        = "caddy"
+	ProfileEnvoy        = "envoy"
+	ProfileIngressNginx = "ingress-nginx"
+	ProfileOAuth2Proxy  = "oauth2-proxy"
+	ProfileTraefik      = "traefik"
+)
+
+// forwardedInputExpression maps the X-Forwarded-* headers sent by traefik and caddy
+const forwardedInputExpression = `
+cel.bind(uri, url(object.attributes.Header("x-forwarded-uri")[?0].orValue("/")),
+	http.CheckRequest{
+		attributes: http.CheckRequestAttributes{
+			method: object.attributes.Header("x-forwarded-method")[?0].orValue(object.attributes.method),
+			header: object.attributes.header,
+			host: object.attributes.Header("x-forwarded-host")[?0].orValue(object.attributes.host),
+			scheme: object.attributes.Header("x-forwarded-proto")[?0].orValue("http"),
+			protocol: object.attributes.protocol,
+			path: uri.getEscapedPath(),
+			query: uri.getQuery(),
+			body: object.attributes.body,
+			contentLength: object.attributes.contentLength,
+		}
+	}
+)
+`
+
+// originalInputExpression maps the X-Original-* headers sent by ingress-nginx, the request url is used
+// when the X-Original-Url header is missing
+const originalInputExpression = `
+cel.bind(original, object.attributes.Header("x-original-url")[?0],
+	cel.bind(u, url(original.orValue("/")),
+		http.CheckRequest{
+			attributes: http.CheckRequestAttributes{
+				method: object.attributes.Header("x-original-method")[?0].orValue(object.attributes.method),
+				header: object.attributes.header,
+				host: original.hasValue() ? u.getHostname() : object.attributes.host,
+				scheme: original.hasValue() ? u.getScheme() : object.attributes.scheme,
+				protocol: object.attributes.protocol,
+				path: original.hasValue() ? u.getEscapedPath() : object.attributes.path,
+				query: original.hasValue() ? u.getQuery() : object.attributes.query,
+				body: object.attributes.body,
+				contentLength: object.attributes.contentLength,
+			}
+		}
+	)
+)
+`
+
+// authRequestInputExpression maps the headers usually configured with nginx auth_request in front of oauth2-proxy
+const authRequestInputExpression = `
+cel.bind(uri, url(object.attributes.Header("x-original-uri")[?0].orValue(object.attributes.Header("x-forwarded-uri")[?0].orValue("/"))),
+	http.CheckRequest{
+		attributes: http.CheckRequestAttributes{
+			method: object.attributes.Header("x-original-method")[?0].orValue(object.attributes.Header("x-forwarded-method")[?0].orValue(object.attributes.method)),
+			header: object.attributes.header,
+			host: object.attributes.Header("x-forwarded-host")[?0].orValue(object.attributes.host),
+			scheme: object.attributes.Header("x-forwarded-proto")[?0].orValue("http"),
+			protocol: object.attributes.protocol,
+			path: uri.getEscapedPath(),
+			query: uri.getQuery(),
+			body: object.attributes.body,
+			contentLength: object.attributes.contentLength,
+		}
+	}
+)
+`
+
+// deniedOutputExpression returns the status, headers and body set by policies, when no status is set
+// it returns 403
+const deniedOutputExpression = `
+httpserver.HttpResponse{
+	status: object.denied.status != 0 ? object.denied.status : 403,
+	header: object.denied.header,
+	body: size(object.denied.body) != 0 ? object.denied.body : bytes(object.denied.reason),
+}
+`
+
+// outputExpression returns the allowed status with the headers added by policies, proxies
+// are expected to copy them to the upstream request
+func outputExpression(allowedStatus int) string {
+	return fmt.Sprintf(`has(object.ok) ? httpserver.HttpResponse{ status: %d, header: object.ok.header } : `, allowedStatus) + deniedOutputExpression
+}
+
+var profiles = map[string]Profile{
+	ProfileCaddy: {
+		InputExpression:  forwardedInputExpression,
+		OutputExpression: outputExpression(http.StatusOK),
+	},
+	ProfileEnvoy: {
+		// envoy forwards the original method, path and headers as is
+		OutputExpression:      outputExpression(http.StatusOK),
+		HeadersToRemoveHeader: "x-envoy-auth-headers-to-remove",
+	},
+	ProfileIngressNginx: {
+		InputExpression:  originalInputExpression,
+		OutputExpression: outputExpression(http.StatusOK),
+	},
+	ProfileOAuth2Proxy: {
+		InputExpression: authRequestInputExpression,
+		// oauth2-proxy answers auth requests with 202 Accepted
+		OutputExpression: outputExpression(http.StatusAccepted),
+	},
+	ProfileTraefik: {
+		InputExpression:  forwardedInputExpression,
+		OutputExpression: outputExpression(http.StatusOK),
+	},
+}
+
+// Profiles returns the names of the available profiles
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func GetProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, supported profiles are: %s", name, strings.Join(Profiles(), ", "))
+	}
+	profile.Name = name
+	return profile, nil
+}
+
+// translateMutations turns the mutations of an allowed response into response headers the proxy understands,
+// mutations the proxy can't apply fail the request instead of being silently dropped
+func (p Profile) translateMutations(ok *httpcel.CheckResponseOk) (*httpcel.CheckResponseOk, error) {
+	if len(ok.Query) != 0 || len(ok.QueryParamsToRemove) != 0 {
+		return nil, fmt.Errorf("the %s profile can't set or remove query parameters", p.Name)
+	}
+	if len(ok.HeadersToRemove) == 0 {
+		return ok, nil
+	}
+	if p.HeadersToRemoveHeader == "" {
+		return nil, fmt.Errorf("the %s profile can't remove headers", p.Name)
+	}
+	out := *ok
+	out.Header = maps.Clone(ok.Header)
+	if out.Header == nil {
+		out.Header = map[string][]string{}
+	}
+	out.Header[p.HeadersToRemoveHeader] = []string{strings.Join(ok.HeadersToRemove, ",")}
+	out.HeadersToRemove = nil
+	return &out, nil
+}
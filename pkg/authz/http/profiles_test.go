@@ -0,0 +1,364 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	httpserver "github.com/kyverno/kyverno-authz/pkg/cel/libs/httpserver"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/sdk/extensions/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilesInput(t *testing.T) {
+	type attributes struct {
+		method string
+		scheme string
+		host   string
+		path   string
+		query  map[string][]string
+	}
+	tests := []struct {
+		name    string
+		profile string
+		target  string
+		header  map[string]string
+		want    attributes
+	}{{
+		name:    "traefik",
+		profile: ProfileTraefik,
+		target:  "http://authz.local/",
+		header: map[string]string{
+			"X-Forwarded-Method": "DELETE",
+			"X-Forwarded-Proto":  "https",
+			"X-Forwarded-Host":   "app.example.com",
+			"X-Forwarded-Uri":    "/api/orders/42?dry-run=true",
+		},
+		want: attributes{
+			method: "DELETE",
+			scheme: "https",
+			host:   "app.example.com",
+			path:   "/api/orders/42",
+			query:  map[string][]string{"dry-run": {"true"}},
+		},
+	}, {
+		name:    "traefik without forwarded headers",
+		profile: ProfileTraefik,
+		target:  "http://authz.local/",
+		want: attributes{
+			method: "GET",
+			scheme: "http",
+			host:   "authz.local",
+			path:   "/",
+			query:  map[string][]string{},
+		},
+	}, {
+		name:    "caddy",
+		profile: ProfileCaddy,
+		target:  "http://authz.local/",
+		header: map[string]string{
+			"X-Forwarded-Method": "POST",
+			"X-Forwarded-Proto":  "https",
+			"X-Forwarded-Host":   "app.example.com",
+			"X-Forwarded-Uri":    "/api/orders",
+		},
+		want: attributes{
+			method: "POST",
+			scheme: "https",
+			host:   "app.example.com",
+			path:   "/api/orders",
+			query:  map[string][]string{},
+		},
+	}, {
+		name:    "ingress-nginx",
+		profile: ProfileIngressNginx,
+		target:  "http://authz.local/",
+		header: map[string]string{
+			"X-Original-Method": "PUT",
+			"X-Original-Url":    "https://app.example.com/api/orders/42?force=1",
+		},
+		want: attributes{
+			method: "PUT",
+			scheme: "https",
+			host:   "app.example.com",
+			path:   "/api/orders/42",
+			query:  map[string][]string{"force": {"1"}},
+		},
+	}, {
+		name:    "ingress-nginx without original url",
+		profile: ProfileIngressNginx,
+		target:  "http://authz.local/api/orders?page=2",
+		want: attributes{
+			method: "GET",
+			scheme: "http",
+			host:   "authz.local",
+			path:   "/api/orders",
+			query:  map[string][]string{"page": {"2"}},
+		},
+	}, {
+		name:    "oauth2-proxy",
+		profile: ProfileOAuth2Proxy,
+		target:  "http://authz.local/oauth2/auth",
+		header: map[string]string{
+			"X-Original-Method": "PATCH",
+			"X-Original-Uri":    "/api/orders/42",
+			"X-Forwarded-Proto": "https",
+			"X-Forwarded-Host":  "app.example.com",
+		},
+		want: attributes{
+			method: "PATCH",
+			scheme: "https",
+			host:   "app.example.com",
+			path:   "/api/orders/42",
+			query:  map[string][]string{},
+		},
+	}, {
+		name:    "oauth2-proxy with forwarded headers",
+		profile: ProfileOAuth2Proxy,
+		target:  "http://authz.local/oauth2/auth",
+		header: map[string]string{
+			"X-Forwarded-Method": "HEAD",
+			"X-Forwarded-Uri":    "/health",
+		},
+		want: attributes{
+			method: "HEAD",
+			scheme: "http",
+			host:   "authz.local",
+			path:   "/health",
+			query:  map[string][]string{},
+		},
+	}, {
+		name:    "envoy",
+		profile: ProfileEnvoy,
+		target:  "http://app.example.com/api/orders?page=2",
+		want: attributes{
+			method: "GET",
+			scheme: "http",
+			host:   "app.example.com",
+			path:   "/api/orders",
+			query:  map[string][]string{"page": {"2"}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := GetProfile(tt.profile)
+			assert.NoError(t, err)
+			input, _, err := compileExpressions(Config{
+				InputExpression:  profile.InputExpression,
+				OutputExpression: profile.OutputExpression,
+			}, nil)
+			assert.NoError(t, err)
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			req, err := httpcel.NewRequest(r)
+			assert.NoError(t, err)
+			if input != nil {
+				out, _, err := input.Eval(map[string]any{"object": &req})
+				assert.NoError(t, err)
+				req = *out.Value().(*httpcel.CheckRequest)
+			}
+			assert.Equal(t, tt.want.method, req.Attributes.Method)
+			assert.Equal(t, tt.want.scheme, req.Attributes.Scheme)
+			assert.Equal(t, tt.want.host, req.Attributes.Host)
+			assert.Equal(t, tt.want.path, req.Attributes.Path)
+			assert.Equal(t, tt.want.query, map[string][]string(req.Attributes.Query))
+		})
+	}
+}
+
+func TestProfilesOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *httpcel.CheckResponse
+		want     map[string]httpserver.HttpResponse
+	}{{
+		name: "allowed",
+		response: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Header: map[string][]string{"X-User": {"alice"}},
+			},
+		},
+		want: map[string]httpserver.HttpResponse{
+			ProfileCaddy:        {Status: 200, Header: map[string][]string{"X-User": {"alice"}}},
+			ProfileEnvoy:        {Status: 200, Header: map[string][]string{"X-User": {"alice"}}},
+			ProfileIngressNginx: {Status: 200, Header: map[string][]string{"X-User": {"alice"}}},
+			ProfileOAuth2Proxy:  {Status: 202, Header: map[string][]string{"X-User": {"alice"}}},
+			ProfileTraefik:      {Status: 200, Header: map[string][]string{"X-User": {"alice"}}},
+		},
+	}, {
+		name: "denied without status",
+		response: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Reason: "Unauthorized",
+			},
+		},
+		want: map[string]httpserver.HttpResponse{
+			ProfileCaddy:        {Status: 403, Body: []byte("Unauthorized")},
+			ProfileEnvoy:        {Status: 403, Body: []byte("Unauthorized")},
+			ProfileIngressNginx: {Status: 403, Body: []byte("Unauthorized")},
+			ProfileOAuth2Proxy:  {Status: 403, Body: []byte("Unauthorized")},
+			ProfileTraefik:      {Status: 403, Body: []byte("Unauthorized")},
+		},
+	}, {
+		name: "denied with status and body",
+		response: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Reason: "login required",
+				Status: 401,
+				Header: map[string][]string{"Www-Authenticate": {"Bearer"}},
+				Body:   []byte("please login"),
+			},
+		},
+		want: map[string]httpserver.HttpResponse{
+			ProfileCaddy:        {Status: 401, Header: map[string][]string{"Www-Authenticate": {"Bearer"}}, Body: []byte("please login")},
+			ProfileEnvoy:        {Status: 401, Header: map[string][]string{"Www-Authenticate": {"Bearer"}}, Body: []byte("please login")},
+			ProfileIngressNginx: {Status: 401, Header: map[string][]string{"Www-Authenticate": {"Bearer"}}, Body: []byte("please login")},
+			ProfileOAuth2Proxy:  {Status: 401, Header: map[string][]string{"Www-Authenticate": {"Bearer"}}, Body: []byte("please login")},
+			ProfileTraefik:      {Status: 401, Header: map[string][]string{"Www-Authenticate": {"Bearer"}}, Body: []byte("please login")},
+		},
+	}}
+	for _, tt := range tests {
+		for _, name := range Profiles() {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				profile, err := GetProfile(name)
+				assert.NoError(t, err)
+				_, output, err := compileExpressions(Config{
+					InputExpression:  profile.InputExpression,
+					OutputExpression: profile.OutputExpression,
+				}, nil)
+				assert.NoError(t, err)
+				out, _, err := output.Eval(map[string]any{"object": tt.response})
+				assert.NoError(t, err)
+				got, err := utils.ConvertToNative[httpserver.HttpResponse](out)
+				assert.NoError(t, err)
+				want := tt.want[name]
+				assert.Equal(t, want.Status, got.Status)
+				assert.Equal(t, want.Body, got.Body)
+				assert.Equal(t, len(want.Header), len(got.Header))
+				for k, v := range want.Header {
+					assert.Equal(t, v, got.Header[k])
+				}
+			})
+		}
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	_, err := GetProfile("nginx")
+	assert.EqualError(t, err, `unknown profile "nginx", supported profiles are: caddy, envoy, ingress-nginx, oauth2-proxy, traefik`)
+}
+
+func TestProfilesMutations(t *testing.T) {
+	tests := []struct {
+		name             string
+		profile          string
+		outputExpression string
+		ok               *httpcel.CheckResponseOk
+		wantStatus       int
+		wantHeader       map[string]string
+		wantBody         string
+	}{{
+		name: "default output sets headers",
+		ok: &httpcel.CheckResponseOk{
+			Header: map[string][]string{"X-User": {"alice"}},
+		},
+		wantStatus: http.StatusOK,
+		wantHeader: map[string]string{"X-User": "alice"},
+	}, {
+		name: "default output can't remove headers",
+		ok: &httpcel.CheckResponseOk{
+			HeadersToRemove: []string{"cookie"},
+		},
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "the default profile can't remove headers",
+	}, {
+		name: "default output can't set query parameters",
+		ok: &httpcel.CheckResponseOk{
+			Query: map[string][]string{"tenant": {"acme"}},
+		},
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "the default profile can't set or remove query parameters",
+	}, {
+		name:             "custom output receives the mutations",
+		outputExpression: `httpserver.HttpResponse{ status: 200, header: {"X-Remove": [object.ok.headersToRemove.join(",")]} }`,
+		ok: &httpcel.CheckResponseOk{
+			HeadersToRemove: []string{"cookie", "x-debug"},
+		},
+		wantStatus: http.StatusOK,
+		wantHeader: map[string]string{"X-Remove": "cookie,x-debug"},
+	}, {
+		name:    "envoy removes headers",
+		profile: ProfileEnvoy,
+		ok: &httpcel.CheckResponseOk{
+			Header:          map[string][]string{"X-User": {"alice"}},
+			HeadersToRemove: []string{"cookie", "x-debug"},
+		},
+		wantStatus: http.StatusOK,
+		wantHeader: map[string]string{"X-User": "alice", "X-Envoy-Auth-Headers-To-Remove": "cookie,x-debug"},
+	}, {
+		name:    "envoy removes headers without setting headers",
+		profile: ProfileEnvoy,
+		ok: &httpcel.CheckResponseOk{
+			HeadersToRemove: []string{"cookie"},
+		},
+		wantStatus: http.StatusOK,
+		wantHeader: map[string]string{"X-Envoy-Auth-Headers-To-Remove": "cookie"},
+	}, {
+		name:    "envoy can't set query parameters",
+		profile: ProfileEnvoy,
+		ok: &httpcel.CheckResponseOk{
+			Query: map[string][]string{"tenant": {"acme"}},
+		},
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "the envoy profile can't set or remove query parameters",
+	}, {
+		name:    "traefik can't remove headers",
+		profile: ProfileTraefik,
+		ok: &httpcel.CheckResponseOk{
+			HeadersToRemove: []string{"cookie"},
+		},
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "the traefik profile can't remove headers",
+	}, {
+		name:    "ingress-nginx can't remove query parameters",
+		profile: ProfileIngressNginx,
+		ok: &httpcel.CheckResponseOk{
+			QueryParamsToRemove: []string{"debug"},
+		},
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "the ingress-nginx profile can't set or remove query parameters",
+	}, {
+		name:    "oauth2-proxy sets headers",
+		profile: ProfileOAuth2Proxy,
+		ok: &httpcel.CheckResponseOk{
+			Header: map[string][]string{"X-User": {"alice"}},
+		},
+		wantStatus: http.StatusAccepted,
+		wantHeader: map[string]string{"X-User": "alice"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := engineFunc(func(*httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+				return policy.Evaluation[*httpcel.CheckResponse]{Result: &httpcel.CheckResponse{Ok: tt.ok}}
+			})
+			mux, err := newMux(Config{Profile: tt.profile, OutputExpression: tt.outputExpression}, engine, nil, events.NewComposite[httpcel.CheckRequest]())
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://authz.local/", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			// the policy response is left untouched
+			assert.Nil(t, tt.ok.Header["X-Envoy-Auth-Headers-To-Remove"])
+		})
+	}
+}
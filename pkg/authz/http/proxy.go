@@ -0,0 +1,190 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	httpserver "github.com/kyverno/kyverno-authz/pkg/cel/libs/httpserver"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// proxy evaluates policies inline and forwards allowed requests to the upstream
+type proxy struct {
+	engine       Engine
+	dyn          dynamic.Interface
+	upstream     http.Handler
+	maxBodySize  int64
+	eventHandler events.EventIface[httpcel.CheckRequest]
+}
+
+func NewProxy(
+	e Engine,
+	dyn dynamic.Interface,
+	upstream http.Handler,
+	maxBodySize int64,
+	eventIface events.EventIface[httpcel.CheckRequest]) *proxy {
+	return &proxy{
+		engine:       e,
+		dyn:          dyn,
+		upstream:     upstream,
+		maxBodySize:  maxBodySize,
+		eventHandler: eventIface,
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	decision := metrics.DecisionError
+	source := metrics.SourceServer
+	defer func() {
+		metrics.RecordAuthzDecision(metrics.ModeHTTP, decision, source, start)
+	}()
+	logger := ctrl.LoggerFrom(r.Context()).WithValues("from", r.RemoteAddr)
+	logger.Info("received request")
+	if p.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, p.maxBodySize)
+	}
+	// the body is forwarded to the upstream, it can't be streamed
+	httpReq, err := httpcel.NewBufferedRequest(r)
+	if err != nil {
+		writeErrResp(logger, w, err)
+		return
+	}
+	// the body has been consumed, restore it so that it can be forwarded
+	r.Body = io.NopCloser(bytes.NewReader(httpReq.Attributes.Body))
+	// the url of incoming server requests has no scheme
+	if httpReq.Attributes.Scheme == "" {
+		httpReq.Attributes.Scheme = "http"
+		if r.TLS != nil {
+			httpReq.Attributes.Scheme = "https"
+		}
+	}
+	response := p.engine.Handle(r.Context(), p.dyn, &httpReq)
+	if response.Error != nil {
+		source = metrics.SourceEngine
+		metrics.RecordHTTPRequestError(r.Context(), httpReq, response.Error)
+		p.eventHandler.Push(context.Background(), time.Now(), httpReq, events.NewResultAccessor(nil, response.Error))
+		writeErrResp(logger, w, response.Error)
+		return
+	}
+	result := response.Result
+	if result == nil {
+		result = &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{},
+		}
+		decision = metrics.DecisionAllow
+		source = metrics.SourceDefault
+	} else if result.Denied != nil {
+		decision = metrics.DecisionDeny
+		source = metrics.SourcePolicy
+	} else {
+		decision = metrics.DecisionAllow
+		source = metrics.SourcePolicy
+	}
+	// result will never be nil here because we set it in the block above
+	p.eventHandler.Push(context.Background(), time.Now(), httpReq, events.NewResultAccessor(*result, nil))
+	defer metrics.RecordHTTPRequest(r.Context(), start, httpReq, result)
+	if result.Denied != nil {
+		writeDenied(logger, w, result.Denied)
+		return
+	}
+	if result.Ok != nil {
+		applyMutations(r, result.Ok)
+	}
+	p.upstream.ServeHTTP(w, r)
+}
+
+// applyMutations applies the headers and query parameters mutations returned by policies,
+// values set by policies replace the ones sent by the client
+func applyMutations(r *http.Request, ok *httpcel.CheckResponseOk) {
+	for _, key := range ok.HeadersToRemove {
+		r.Header.Del(key)
+	}
+	for key, values := range ok.Header {
+		r.Header.Del(key)
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	if len(ok.Query) == 0 && len(ok.QueryParamsToRemove) == 0 {
+		return
+	}
+	query := r.URL.Query()
+	for _, key := range ok.QueryParamsToRemove {
+		query.Del(key)
+	}
+	for key, values := range ok.Query {
+		query[key] = values
+	}
+	r.URL.RawQuery = query.Encode()
+}
+
+// writeDenied mirrors the default output expression of the authz server
+func writeDenied(logger logr.Logger, w http.ResponseWriter, denied *httpcel.CheckResponseDenied) {
+	resp := httpserver.HttpResponse{
+		Status: denied.Status,
+		Header: denied.Header,
+		Body:   denied.Body,
+	}
+	if resp.Status == 0 {
+		resp.Status = http.StatusForbidden
+	}
+	if len(resp.Body) == 0 {
+		resp.Body = []byte(denied.Reason)
+	}
+	writeResponse(logger, w, resp)
+}
+
+func NewProxyServer(config ProxyConfig, source engine.HTTPSource,
+	dyn dynamic.Interface, eventIface events.EventIface[httpcel.CheckRequest]) server.ServerFunc {
+	return func(ctx context.Context) error {
+		upstream, err := url.Parse(config.Upstream)
+		if err != nil {
+			return fmt.Errorf("invalid upstream url: %w", err)
+		}
+		if upstream.Scheme == "" || upstream.Host == "" {
+			return fmt.Errorf("invalid upstream url %q: scheme and host are required", config.Upstream)
+		}
+		// create reverse proxy
+		reverseProxy := newReverseProxy(upstream, config.PreserveHost)
+		// build the engine
+		engine := NewEngine(source)
+		// create server
+		s := &http.Server{
+			Addr:    config.Address,
+			Handler: NewProxy(engine, dyn, reverseProxy, config.MaxBodySize, eventIface),
+		}
+		// serve TLS if a certfile and a keyfile are provided
+		if config.CertFile != "" && config.KeyFile != "" {
+			s.TLSConfig = server.TLSConfig()
+		}
+		// run server
+		return server.RunHttp(ctx, s, config.CertFile, config.KeyFile)
+	}
+}
+
+// newReverseProxy forwards requests to the upstream with the X-Forwarded-* headers set
+func newReverseProxy(upstream *url.URL, preserveHost bool) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+			if preserveHost {
+				r.Out.Host = r.In.Host
+			}
+		},
+	}
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/sdk/extensions/policy"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/dynamic"
+)
+
+// engineFunc returns the evaluation of the function for every request
+type engineFunc func(*httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse]
+
+func (f engineFunc) Handle(_ context.Context, _ dynamic.Interface, r *httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+	return f(r)
+}
+
+// upstreamRequest is what the upstream received
+type upstreamRequest struct {
+	method string
+	path   string
+	query  string
+	header http.Header
+	body   string
+}
+
+func TestProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		header      map[string]string
+		body        string
+		maxBodySize int64
+		result      *httpcel.CheckResponse
+		err         error
+		// wantUpstream is nil when the request must not be forwarded
+		wantUpstream *upstreamRequest
+		wantStatus   int
+		wantHeader   map[string]string
+		wantBody     string
+	}{{
+		name:   "no result forwards the request",
+		method: http.MethodPost,
+		target: "/api/orders?page=2",
+		header: map[string]string{"Content-Type": "application/json"},
+		body:   `{"item":"book"}`,
+		wantUpstream: &upstreamRequest{
+			method: http.MethodPost,
+			path:   "/api/orders",
+			query:  "page=2",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"item":"book"}`,
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "upstream",
+	}, {
+		name:   "allowed forwards the request body",
+		method: http.MethodPut,
+		target: "/api/orders/42",
+		body:   "payload",
+		result: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{},
+		},
+		wantUpstream: &upstreamRequest{
+			method: http.MethodPut,
+			path:   "/api/orders/42",
+			body:   "payload",
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "upstream",
+	}, {
+		name:   "allowed applies header mutations",
+		method: http.MethodGet,
+		target: "/",
+		header: map[string]string{"Cookie": "session=1", "X-User": "mallory"},
+		result: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Header:          map[string][]string{"X-User": {"alice"}, "X-Groups": {"dev", "ops"}},
+				HeadersToRemove: []string{"cookie"},
+			},
+		},
+		wantUpstream: &upstreamRequest{
+			method: http.MethodGet,
+			path:   "/",
+			header: http.Header{"X-User": {"alice"}, "X-Groups": {"dev", "ops"}, "Cookie": nil},
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "upstream",
+	}, {
+		name:   "allowed applies query mutations",
+		method: http.MethodGet,
+		target: "/search?q=books&debug=true&tenant=evil",
+		result: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Query:               map[string][]string{"tenant": {"acme"}},
+				QueryParamsToRemove: []string{"debug"},
+			},
+		},
+		wantUpstream: &upstreamRequest{
+			method: http.MethodGet,
+			path:   "/search",
+			query:  "q=books&tenant=acme",
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "upstream",
+	}, {
+		name:   "denied without status",
+		method: http.MethodDelete,
+		target: "/api/orders/42",
+		result: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{Reason: "only admins can delete orders"},
+		},
+		wantStatus: http.StatusForbidden,
+		wantBody:   "only admins can delete orders",
+	}, {
+		name:   "denied with status, header and body",
+		method: http.MethodGet,
+		target: "/",
+		result: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Reason: "login required",
+				Status: http.StatusUnauthorized,
+				Header: map[string][]string{"Www-Authenticate": {"Bearer"}},
+				Body:   []byte("please login"),
+			},
+		},
+		wantStatus: http.StatusUnauthorized,
+		wantHeader: map[string]string{"Www-Authenticate": "Bearer"},
+		wantBody:   "please login",
+	}, {
+		name:       "engine error",
+		method:     http.MethodGet,
+		target:     "/",
+		err:        errors.New("boom"),
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "boom",
+	}, {
+		name:        "body too large",
+		method:      http.MethodPost,
+		target:      "/",
+		body:        "0123456789",
+		maxBodySize: 4,
+		wantStatus:  http.StatusRequestEntityTooLarge,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *upstreamRequest
+			upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				got = &upstreamRequest{
+					method: r.Method,
+					path:   r.URL.Path,
+					query:  r.URL.RawQuery,
+					header: r.Header,
+					body:   string(body),
+				}
+				_, _ = w.Write([]byte("upstream"))
+			})
+			var evaluated *httpcel.CheckRequest
+			engine := engineFunc(func(r *httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+				evaluated = r
+				return policy.Evaluation[*httpcel.CheckResponse]{Result: tt.result, Error: tt.err}
+			})
+			p := NewProxy(engine, nil, upstream, tt.maxBodySize, events.NewComposite[httpcel.CheckRequest]())
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, r)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, w.Header().Get(k))
+			}
+			if tt.wantUpstream == nil {
+				assert.Nil(t, got)
+				return
+			}
+			// policies see the same body as the upstream
+			assert.Equal(t, tt.body, string(evaluated.Attributes.Body))
+			assert.NotNil(t, got)
+			assert.Equal(t, tt.wantUpstream.method, got.method)
+			assert.Equal(t, tt.wantUpstream.path, got.path)
+			assert.Equal(t, tt.wantUpstream.query, got.query)
+			assert.Equal(t, tt.wantUpstream.body, got.body)
+			for k, v := range tt.wantUpstream.header {
+				assert.Equal(t, v, got.header.Values(k), k)
+			}
+		})
+	}
+}
+
+func TestProxyForwarding(t *testing.T) {
+	tests := []struct {
+		name         string
+		preserveHost bool
+		wantHost     func(upstream *url.URL) string
+	}{{
+		name:     "upstream host",
+		wantHost: func(upstream *url.URL) string { return upstream.Host },
+	}, {
+		name:         "preserve host",
+		preserveHost: true,
+		wantHost:     func(*url.URL) string { return "app.example.com" },
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var gotBody string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				got, gotBody = r, string(body)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer upstream.Close()
+			upstreamURL, err := url.Parse(upstream.URL)
+			assert.NoError(t, err)
+			engine := engineFunc(func(*httpcel.CheckRequest) policy.Evaluation[*httpcel.CheckResponse] {
+				return policy.Evaluation[*httpcel.CheckResponse]{
+					Result: &httpcel.CheckResponse{
+						Ok: &httpcel.CheckResponseOk{Header: map[string][]string{"X-User": {"alice"}}},
+					},
+				}
+			})
+			p := NewProxy(engine, nil, newReverseProxy(upstreamURL, tt.preserveHost), 0, events.NewComposite[httpcel.CheckRequest]())
+			r := httptest.NewRequest(http.MethodPost, "http://app.example.com/api/orders?page=2", strings.NewReader(`{"item":"book"}`))
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.NotNil(t, got)
+			assert.Equal(t, tt.wantHost(upstreamURL), got.Host)
+			assert.Equal(t, "/api/orders", got.URL.Path)
+			assert.Equal(t, "page=2", got.URL.RawQuery)
+			assert.Equal(t, `{"item":"book"}`, gotBody)
+			assert.Equal(t, "alice", got.Header.Get("X-User"))
+			assert.Equal(t, "app.example.com", got.Header.Get("X-Forwarded-Host"))
+			assert.Equal(t, "http", got.Header.Get("X-Forwarded-Proto"))
+		})
+	}
+}
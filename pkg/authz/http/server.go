@@ -2,7 +2,6 @@ package http
 
 import (
 	"context"
-	"crypto/tls"
 	"net/http"
 
 	"github.com/google/cel-go/cel"
@@ -12,113 +11,18 @@ import (
 	httpserver "github.com/kyverno/kyverno-authz/pkg/cel/libs/httpserver"
 	"github.com/kyverno/kyverno-authz/pkg/engine"
 	"github.com/kyverno/kyverno-authz/pkg/events"
-	"github.com/kyverno/kyverno-authz/pkg/metrics"
 	"github.com/kyverno/kyverno-authz/pkg/server"
-	"github.com/kyverno/sdk/core"
-	"github.com/kyverno/sdk/core/dispatchers"
-	"github.com/kyverno/sdk/core/handlers"
-	"github.com/kyverno/sdk/core/resulters"
-	"github.com/kyverno/sdk/extensions/policy"
 	"k8s.io/client-go/dynamic"
 )
 
 func NewServer(config Config, source engine.HTTPSource,
 	dyn dynamic.Interface, eventIface events.EventIface[httpcel.CheckRequest]) server.ServerFunc {
 	return func(ctx context.Context) error {
-		base, err := kcel.NewEnv(apis.EvaluationModeHTTP, dyn)
-		if err != nil {
-			return err
-		}
-		var inputProgram cel.Program
-		if config.InputExpression != "" {
-			// 			config.InputExpression = `
-			// http.CheckRequest{
-			// 	attributes: http.CheckRequestAttributes{
-			// 		method: object.attributes.Header("x-original-method")[0],
-			// 		header: object.attributes.header,
-			// 		host: url(object.attributes.Header("x-original-url")[0]).getHostname(),
-			// 		scheme: url(object.attributes.Header("x-original-url")[0]).getScheme(),
-			// 		path: url(object.attributes.Header("x-original-url")[0]).getEscapedPath(),
-			// 		query: url(object.attributes.Header("x-original-url")[0]).getQuery(),
-			// 		body: object.attributes.body,
-			// 		fragment: "todo",
-			// 	}
-			// }
-			// `
-			inputEnv, err := base.Extend(cel.Variable("object", httpcel.RequestType))
-			if err != nil {
-				return err
-			}
-			inputAst, issues := inputEnv.Compile(config.InputExpression)
-			if err := issues.Err(); err != nil {
-				return err
-			}
-			program, err := inputEnv.Program(inputAst)
-			if err != nil {
-				return err
-			}
-			inputProgram = program
-		}
-		if config.OutputExpression == "" {
-			config.OutputExpression = `
-has(object.ok)
-	? httpserver.HttpResponse{ status: 200 }
-	: httpserver.HttpResponse{ status: 403, body: bytes(object.denied.reason) }
-`
-		}
-		outputEnv, err := base.Extend(
-			cel.Variable("object", httpcel.ResponseType),
-			httpserver.Lib(),
-		)
-		if err != nil {
-			return err
-		}
-		outputAst, issues := outputEnv.Compile(config.OutputExpression)
-		if err := issues.Err(); err != nil {
-			return err
-		}
-		outputProgram, err := outputEnv.Program(outputAst)
+		// create mux
+		mux, err := newMux(config, NewEngine(source), dyn, eventIface)
 		if err != nil {
 			return err
 		}
-		// create mux
-		mux := http.NewServeMux()
-		// build the engine
-		engine := core.NewEngine(
-			source,
-			handlers.Handler(
-				dispatchers.Sequential(
-					metrics.MetricsEvaluatorFactory(
-						policy.EvaluatorFactory[engine.HTTPPolicy](),
-						func(out policy.Evaluation[*httpcel.CheckResponse]) string {
-							if out.Error != nil {
-								return metrics.DecisionError
-							}
-							if out.Result == nil {
-								return metrics.DecisionNoMatch
-							}
-							if out.Result.Denied != nil {
-								return metrics.DecisionDeny
-							}
-							return metrics.DecisionAllow
-						},
-					),
-					func(ctx context.Context, fc core.FactoryContext[engine.HTTPPolicy, dynamic.Interface, *httpcel.CheckRequest]) core.Breaker[engine.HTTPPolicy, *httpcel.CheckRequest, policy.Evaluation[*httpcel.CheckResponse]] {
-						return core.MakeBreakerFunc(func(_ context.Context, _ engine.HTTPPolicy, _ *httpcel.CheckRequest, out policy.Evaluation[*httpcel.CheckResponse]) bool {
-							return out.Result != nil
-						})
-					},
-				),
-				func(ctx context.Context, fc core.FactoryContext[engine.HTTPPolicy, dynamic.Interface, *httpcel.CheckRequest]) core.Resulter[engine.HTTPPolicy, *httpcel.CheckRequest, policy.Evaluation[*httpcel.CheckResponse], policy.Evaluation[*httpcel.CheckResponse]] {
-					return resulters.NewFirst[engine.HTTPPolicy, *httpcel.CheckRequest](func(out policy.Evaluation[*httpcel.CheckResponse]) bool {
-						return out.Result != nil || out.Error != nil
-					})
-				},
-			),
-		)
-		// register service
-		a := NewAuthorizer(engine, dyn, inputProgram, outputProgram, config.NestedRequest, eventIface)
-		mux.Handle("POST /{$}", a)
 		// create server
 		s := &http.Server{
 			Addr:    config.Address,
@@ -126,20 +30,105 @@ has(object.ok)
 		}
 		// serve TLS if a certfile and a keyfile are provided
 		if config.CertFile != "" && config.KeyFile != "" {
-			s.TLSConfig = &tls.Config{
-				MinVersion: tls.VersionTLS12,
-				CipherSuites: []uint16{
-					// AEADs w/ ECDHE
-					tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-					tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-					tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
-					tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-					tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
-					tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
-				},
-			}
+			s.TLSConfig = server.TLSConfig()
 		}
 		// run server
 		return server.RunHttp(ctx, s, config.CertFile, config.KeyFile)
 	}
 }
+
+// newMux registers the check and batch services
+func newMux(config Config, engine Engine, dyn dynamic.Interface, eventIface events.EventIface[httpcel.CheckRequest]) (*http.ServeMux, error) {
+	// batch requests are structured, profile input expressions map forward auth headers and don't apply to them
+	batchInputProgram, err := compileInputExpression(config.InputExpression, dyn)
+	if err != nil {
+		return nil, err
+	}
+	// profile expressions are used unless explicitly overridden
+	var profile *Profile
+	if config.Profile != "" {
+		p, err := GetProfile(config.Profile)
+		if err != nil {
+			return nil, err
+		}
+		profile = &p
+		if config.InputExpression == "" {
+			config.InputExpression = profile.InputExpression
+		}
+		if config.OutputExpression == "" {
+			config.OutputExpression = profile.OutputExpression
+		}
+		// forward auth proxies send the original request attributes in headers
+		config.NestedRequest = false
+	}
+	// the default output only sets headers, the other mutations are rejected as profiles do
+	if profile == nil && config.OutputExpression == "" {
+		profile = &Profile{Name: "default", OutputExpression: outputExpression(http.StatusOK)}
+	}
+	inputProgram, outputProgram, err := compileExpressions(config, dyn)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	// register service
+	a := NewAuthorizer(engine, dyn, inputProgram, outputProgram, config.NestedRequest, config.MaxBodySize, profile, eventIface)
+	mux.Handle("POST /{$}", a)
+	// register batch services
+	b := NewBatcher(engine, dyn, batchInputProgram, DecodeRequest, config.MaxBodySize, config.MaxBatchSize, config.BatchConcurrency, eventIface)
+	mux.Handle("POST /batch", b)
+	eb := NewBatcher(engine, dyn, batchInputProgram, DecodeEnvoyRequest, config.MaxBodySize, config.MaxBatchSize, config.BatchConcurrency, eventIface)
+	mux.Handle("POST /batch/envoy", eb)
+	return mux, nil
+}
+
+// compileExpressions compiles the input and output expressions, the input program is nil when no input expression is set
+func compileExpressions(config Config, dyn dynamic.Interface) (cel.Program, cel.Program, error) {
+	base, err := kcel.NewEnv(apis.EvaluationModeHTTP, dyn)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputProgram, err := compileInputExpression(config.InputExpression, dyn)
+	if err != nil {
+		return nil, nil, err
+	}
+	expression := config.OutputExpression
+	if expression == "" {
+		expression = outputExpression(http.StatusOK)
+	}
+	outputEnv, err := base.Extend(
+		cel.Variable("object", httpcel.ResponseType),
+		httpserver.Lib(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputAst, issues := outputEnv.Compile(expression)
+	if err := issues.Err(); err != nil {
+		return nil, nil, err
+	}
+	outputProgram, err := outputEnv.Program(outputAst)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputProgram, outputProgram, nil
+}
+
+// compileInputExpression compiles an input expression, the program is nil when the expression is empty
+func compileInputExpression(expression string, dyn dynamic.Interface) (cel.Program, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	base, err := kcel.NewEnv(apis.EvaluationModeHTTP, dyn)
+	if err != nil {
+		return nil, err
+	}
+	inputEnv, err := base.Extend(cel.Variable("object", httpcel.RequestType))
+	if err != nil {
+		return nil, err
+	}
+	inputAst, issues := inputEnv.Compile(expression)
+	if err := issues.Err(); err != nil {
+		return nil, err
+	}
+	return inputEnv.Program(inputAst)
+}
@@ -0,0 +1,15 @@
+package mcpgateway
+
+import (
+	"time"
+)
+
+type Config struct {
+	Address            string
+	Upstream           string
+	MaxBodySize        int64
+	SessionIdleTimeout time.Duration
+	MaxSessions        int
+	CertFile           string
+	KeyFile            string
+}
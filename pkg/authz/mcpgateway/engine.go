@@ -0,0 +1,20 @@
+package mcpgateway
+
+import (
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sequential"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+)
+
+type Engine = sequential.Engine[*mcpgatewaycel.CheckRequest, mcpgatewaycel.CheckResponse]
+
+// NewEngine builds an engine evaluating policies sequentially until one of them returns a result
+func NewEngine(source engine.MCPSource) Engine {
+	return sequential.NewEngine(source, func(result *mcpgatewaycel.CheckResponse) string {
+		if !result.Allowed {
+			return metrics.DecisionDeny
+		}
+		return metrics.DecisionAllow
+	})
+}
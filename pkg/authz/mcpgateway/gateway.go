@@ -0,0 +1,307 @@
+package mcpgateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	mcpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/mcp"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	sessionIDHeader  = "Mcp-Session-Id"
+	initializeMethod = "initialize"
+	// json-rpc error codes, denied requests use a code of the range reserved for implementation defined errors
+	parseErrorCode     = -32700
+	invalidRequestCode = -32600
+	internalErrorCode  = -32603
+	deniedErrorCode    = -32001
+)
+
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string                       `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage   `json:"capabilities"`
+	ClientInfo      mcpgatewaycel.Implementation `json:"clientInfo"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string                       `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage   `json:"capabilities"`
+	ServerInfo      mcpgatewaycel.Implementation `json:"serverInfo"`
+}
+
+// initialize is attached to the context of initialize requests until the upstream answers with a session id
+type initialize struct {
+	id      json.RawMessage
+	session mcpgatewaycel.Session
+}
+
+type initializeKey struct{}
+
+// gateway evaluates policies against mcp messages and forwards allowed messages to the upstream mcp server,
+// sessions are tracked from the initialize handshake so that policies can rely on the session state
+type gateway struct {
+	engine       Engine
+	dyn          dynamic.Interface
+	upstream     http.Handler
+	sessions     *sessions
+	maxBodySize  int64
+	eventHandler events.EventIface[mcpgatewaycel.CheckRequest]
+}
+
+func NewGateway(
+	e Engine,
+	dyn dynamic.Interface,
+	upstream *url.URL,
+	sessionIdleTimeout time.Duration,
+	maxSessions int,
+	maxBodySize int64,
+	eventIface events.EventIface[mcpgatewaycel.CheckRequest]) *gateway {
+	g := &gateway{
+		engine:       e,
+		dyn:          dyn,
+		sessions:     newSessions(sessionIdleTimeout, maxSessions),
+		maxBodySize:  maxBodySize,
+		eventHandler: eventIface,
+	}
+	g.upstream = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+		},
+		ModifyResponse: g.modifyResponse,
+	}
+	return g
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	decision := metrics.DecisionError
+	source := metrics.SourceServer
+	defer func() {
+		metrics.RecordAuthzDecision(metrics.ModeMCP, decision, source, start)
+	}()
+	logger := ctrl.LoggerFrom(r.Context()).WithValues("from", r.RemoteAddr)
+	logger.Info("received request")
+	if g.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, g.maxBodySize)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrResp(logger, w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			writeErrResp(logger, w, http.StatusBadRequest, err)
+		}
+		return
+	}
+	// the body has been consumed, restore it so that it can be forwarded
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	request := mcpgatewaycel.CheckRequest{
+		HttpMethod: r.Method,
+		Path:       r.URL.Path,
+		Header:     r.Header,
+		Body:       string(body),
+	}
+	var msg message
+	if trimmed := bytes.TrimSpace(body); len(trimmed) != 0 {
+		// batching was removed from the protocol, every message must be evaluated on its own
+		if mcpcel.IsBatch(trimmed) {
+			writeRPCError(logger, w, http.StatusBadRequest, nil, invalidRequestCode, "batch messages are not supported")
+			return
+		}
+		if err := json.Unmarshal(trimmed, &msg); err != nil {
+			writeRPCError(logger, w, http.StatusBadRequest, nil, parseErrorCode, err.Error())
+			return
+		}
+		request.Method = msg.Method
+	}
+	if id := r.Header.Get(sessionIDHeader); id != "" {
+		request.Session = g.sessions.get(id)
+		// a session id is not a credential, the session can only be used by the principal that initialized it.
+		// the upstream is not asked, the 404 status asks the client to initialize a new session, for example
+		// when its token was refreshed
+		if request.Session != nil && request.Session.Principal != principal(r) {
+			decision = metrics.DecisionDeny
+			writeErrResp(logger, w, http.StatusNotFound, errors.New("session was initialized by another principal"))
+			return
+		}
+	}
+	response := g.engine.Handle(r.Context(), g.dyn, &request)
+	if response.Error != nil {
+		source = metrics.SourceEngine
+		g.eventHandler.Push(context.Background(), time.Now(), request, events.NewResultAccessor(nil, response.Error))
+		writeRPCError(logger, w, http.StatusInternalServerError, msg.ID, internalErrorCode, response.Error.Error())
+		return
+	}
+	result := response.Result
+	if result == nil {
+		result = &mcpgatewaycel.CheckResponse{
+			Allowed: true,
+		}
+		decision = metrics.DecisionAllow
+		source = metrics.SourceDefault
+	} else if !result.Allowed {
+		decision = metrics.DecisionDeny
+		source = metrics.SourcePolicy
+	} else {
+		decision = metrics.DecisionAllow
+		source = metrics.SourcePolicy
+	}
+	g.eventHandler.Push(context.Background(), time.Now(), request, events.NewResultAccessor(*result, nil))
+	if !result.Allowed {
+		if msg.ID != nil && msg.Method != "" {
+			// denied requests get a json-rpc error so that clients can surface the reason
+			writeRPCError(logger, w, http.StatusOK, msg.ID, deniedErrorCode, result.Reason)
+		} else {
+			writeErrResp(logger, w, http.StatusForbidden, errors.New(result.Reason))
+		}
+		return
+	}
+	if msg.Method == initializeMethod {
+		var params initializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			writeRPCError(logger, w, http.StatusBadRequest, msg.ID, invalidRequestCode, err.Error())
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), initializeKey{}, &initialize{
+			id: msg.ID,
+			session: mcpgatewaycel.Session{
+				Principal:          principal(r),
+				ProtocolVersion:    params.ProtocolVersion,
+				ClientInfo:         params.ClientInfo,
+				ClientCapabilities: capabilities(params.Capabilities),
+			},
+		}))
+	}
+	g.upstream.ServeHTTP(w, r)
+}
+
+// modifyResponse maintains sessions from the upstream responses
+func (g *gateway) modifyResponse(resp *http.Response) error {
+	if id := resp.Request.Header.Get(sessionIDHeader); id != "" {
+		// the upstream answers 404 for sessions it doesn't know anymore, clients are expected to initialize
+		// a new session, sessions terminated by the client are forgotten too
+		if resp.StatusCode == http.StatusNotFound || (resp.Request.Method == http.MethodDelete && resp.StatusCode < 300) {
+			g.sessions.delete(id)
+		}
+	}
+	pending, _ := resp.Request.Context().Value(initializeKey{}).(*initialize)
+	if pending == nil || resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	sessionID := resp.Header.Get(sessionIDHeader)
+	// stateless upstreams don't assign session ids
+	if sessionID == "" {
+		return nil
+	}
+	session := pending.session
+	session.ID = sessionID
+	session.CreatedAt = time.Now()
+	g.sessions.put(session)
+	// the server side of the session is known once the initialize response is read
+	onMessage := func(data []byte) bool {
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil || !bytes.Equal(msg.ID, pending.id) {
+			return false
+		}
+		var result initializeResult
+		if err := json.Unmarshal(msg.Result, &result); err == nil {
+			g.sessions.update(sessionID, func(s *mcpgatewaycel.Session) {
+				// the server answers with the negotiated protocol version
+				if result.ProtocolVersion != "" {
+					s.ProtocolVersion = result.ProtocolVersion
+				}
+				s.ServerInfo = result.ServerInfo
+				s.ServerCapabilities = capabilities(result.Capabilities)
+			})
+		}
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close() //nolint:errcheck
+		if err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		onMessage(body)
+	case "text/event-stream":
+		resp.Body = &sseWatcher{ReadCloser: resp.Body, onMessage: onMessage}
+	}
+	return nil
+}
+
+// principal identifies who sends the request, it is the hash of the authorization header or empty without one
+func principal(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:])
+}
+
+func capabilities(c map[string]json.RawMessage) []string {
+	return slices.Sorted(maps.Keys(c))
+}
+
+func writeRPCError(logger logr.Logger, w http.ResponseWriter, status int, id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+		},
+	})
+	if err != nil {
+		writeErrResp(logger, w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		logger.Error(err, "failed to write body")
+	}
+	if status != http.StatusOK {
+		logger.Error(errors.New(message), "an error has occurred")
+	}
+}
+
+func writeErrResp(logger logr.Logger, w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, err.Error()) //nolint:errcheck
+	logger.Error(err, "an error has occurred")
+}
@@ -0,0 +1,260 @@
+package mcpgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/sdk/extensions/policy"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/dynamic"
+)
+
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"sampling":{},"roots":{}},"clientInfo":{"name":"acme-agent","version":"1.0.0"}}}`
+
+// engineFunc returns the evaluation of the function for every request
+type engineFunc func(*mcpgatewaycel.CheckRequest) policy.Evaluation[*mcpgatewaycel.CheckResponse]
+
+func (f engineFunc) Handle(_ context.Context, _ dynamic.Interface, r *mcpgatewaycel.CheckRequest) policy.Evaluation[*mcpgatewaycel.CheckResponse] {
+	return f(r)
+}
+
+// fakeUpstream is a minimal mcp server, it assigns a session id on initialize and answers 404 for unknown sessions
+type fakeUpstream struct {
+	lock     sync.Mutex
+	sse      bool
+	sessions map[string]bool
+	next     int
+	received []string
+}
+
+func (u *fakeUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	u.received = append(u.received, string(body))
+	id := r.Header.Get(sessionIDHeader)
+	if id != "" && !u.sessions[id] {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(u.sessions, id)
+		return
+	}
+	var msg message
+	_ = json.Unmarshal(body, &msg)
+	if msg.Method != initializeMethod {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{}}`, msg.ID)
+		return
+	}
+	u.next++
+	id = fmt.Sprintf("session-%d", u.next)
+	u.sessions[id] = true
+	w.Header().Set(sessionIDHeader, id)
+	result := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":"2025-03-26","capabilities":{"tools":{},"prompts":{}},"serverInfo":{"name":"orders","version":"2.0.0"}}}`, msg.ID)
+	if u.sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// a notification is sent before the response, the data of the response is split over two lines
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\"}\n\n")
+		fmt.Fprintf(w, "event: message\r\ndata: %s\r\ndata: \r\n\r\n", result)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, result)
+}
+
+// newTestGateway returns a gateway in front of a fake upstream, the returned function lists the requests
+// seen by the engine
+func newTestGateway(t *testing.T, sse bool, evaluate func(*mcpgatewaycel.CheckRequest) policy.Evaluation[*mcpgatewaycel.CheckResponse]) (*gateway, *fakeUpstream, func() []mcpgatewaycel.CheckRequest) {
+	t.Helper()
+	upstream := &fakeUpstream{sse: sse, sessions: map[string]bool{}}
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+	upstreamURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	var lock sync.Mutex
+	var seen []mcpgatewaycel.CheckRequest
+	engine := engineFunc(func(r *mcpgatewaycel.CheckRequest) policy.Evaluation[*mcpgatewaycel.CheckResponse] {
+		lock.Lock()
+		seen = append(seen, *r)
+		lock.Unlock()
+		if evaluate == nil {
+			return policy.Evaluation[*mcpgatewaycel.CheckResponse]{}
+		}
+		return evaluate(r)
+	})
+	g := NewGateway(engine, nil, upstreamURL, 0, 0, 0, events.NewComposite[mcpgatewaycel.CheckRequest]())
+	return g, upstream, func() []mcpgatewaycel.CheckRequest {
+		lock.Lock()
+		defer lock.Unlock()
+		return seen
+	}
+}
+
+func send(g *gateway, method, sessionID, authorization, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		r.Header.Set(sessionIDHeader, sessionID)
+	}
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	return w
+}
+
+func TestGatewaySessions(t *testing.T) {
+	for _, sse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sse=%t", sse), func(t *testing.T) {
+			g, _, seen := newTestGateway(t, sse, nil)
+			// initialize creates the session
+			w := send(g, http.MethodPost, "", "Bearer alice", initializeBody)
+			assert.Equal(t, http.StatusOK, w.Code)
+			// the response is passed through untouched
+			assert.Contains(t, w.Body.String(), `"serverInfo":{"name":"orders","version":"2.0.0"}`)
+			sessionID := w.Header().Get(sessionIDHeader)
+			assert.Equal(t, "session-1", sessionID)
+			session := g.sessions.get(sessionID)
+			assert.NotNil(t, session)
+			assert.Equal(t, sessionID, session.ID)
+			assert.NotEmpty(t, session.Principal)
+			assert.Equal(t, "2025-03-26", session.ProtocolVersion)
+			assert.Equal(t, mcpgatewaycel.Implementation{Name: "acme-agent", Version: "1.0.0"}, session.ClientInfo)
+			assert.Equal(t, []string{"roots", "sampling"}, session.ClientCapabilities)
+			assert.Equal(t, mcpgatewaycel.Implementation{Name: "orders", Version: "2.0.0"}, session.ServerInfo)
+			assert.Equal(t, []string{"prompts", "tools"}, session.ServerCapabilities)
+			assert.False(t, session.CreatedAt.IsZero())
+			// the initialize request itself has no session
+			assert.Nil(t, seen()[0].Session)
+			assert.Equal(t, initializeMethod, seen()[0].Method)
+			// the session is attached to the next messages
+			w = send(g, http.MethodPost, sessionID, "Bearer alice", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotNil(t, seen()[1].Session)
+			assert.Equal(t, "orders", seen()[1].Session.ServerInfo.Name)
+			assert.Equal(t, "tools/list", seen()[1].Method)
+			// terminating the session forgets it
+			w = send(g, http.MethodDelete, sessionID, "Bearer alice", "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Nil(t, g.sessions.get(sessionID))
+		})
+	}
+}
+
+func TestGatewayUpstreamNotFound(t *testing.T) {
+	g, upstream, _ := newTestGateway(t, false, nil)
+	w := send(g, http.MethodPost, "", "", initializeBody)
+	sessionID := w.Header().Get(sessionIDHeader)
+	assert.NotNil(t, g.sessions.get(sessionID))
+	// the upstream forgot the session, for example after a restart
+	upstream.lock.Lock()
+	upstream.sessions = map[string]bool{}
+	upstream.lock.Unlock()
+	w = send(g, http.MethodPost, sessionID, "", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Nil(t, g.sessions.get(sessionID))
+}
+
+func TestGatewayPrincipal(t *testing.T) {
+	g, upstream, seen := newTestGateway(t, false, nil)
+	w := send(g, http.MethodPost, "", "Bearer alice", initializeBody)
+	sessionID := w.Header().Get(sessionIDHeader)
+	// another principal can't use the session, the request is neither evaluated nor forwarded
+	w = send(g, http.MethodPost, sessionID, "Bearer mallory", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "session was initialized by another principal", w.Body.String())
+	assert.Len(t, seen(), 1)
+	upstream.lock.Lock()
+	assert.Len(t, upstream.received, 1)
+	upstream.lock.Unlock()
+	// the session is kept for its principal
+	w = send(g, http.MethodPost, sessionID, "Bearer alice", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotNil(t, seen()[1].Session)
+}
+
+func TestGatewayDecisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		result     *mcpgatewaycel.CheckResponse
+		err        error
+		wantStatus int
+		// wantBody is compared as json when it starts with a brace
+		wantBody string
+	}{{
+		name:       "allowed",
+		body:       `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		result:     &mcpgatewaycel.CheckResponse{Allowed: true},
+		wantStatus: http.StatusOK,
+		wantBody:   `{"jsonrpc":"2.0","id":1,"result":{}}`,
+	}, {
+		name:       "denied request",
+		body:       `{"jsonrpc":"2.0","id":"abc","method":"tools/call"}`,
+		result:     &mcpgatewaycel.CheckResponse{Reason: "tool not allowed"},
+		wantStatus: http.StatusOK,
+		wantBody:   `{"jsonrpc":"2.0","id":"abc","error":{"code":-32001,"message":"tool not allowed"}}`,
+	}, {
+		name:       "denied notification",
+		body:       `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		result:     &mcpgatewaycel.CheckResponse{Reason: "notifications not allowed"},
+		wantStatus: http.StatusForbidden,
+		wantBody:   "notifications not allowed",
+	}, {
+		name:       "engine error",
+		body:       `{"jsonrpc":"2.0","id":7,"method":"tools/list"}`,
+		err:        errors.New("boom"),
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   `{"jsonrpc":"2.0","id":7,"error":{"code":-32603,"message":"boom"}}`,
+	}, {
+		name:       "batch",
+		body:       ` [{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`,
+		wantStatus: http.StatusBadRequest,
+		wantBody:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch messages are not supported"}}`,
+	}, {
+		name:       "parse error",
+		body:       `{"jsonrpc":`,
+		wantStatus: http.StatusBadRequest,
+		wantBody:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"unexpected end of JSON input"}}`,
+	}, {
+		name:       "invalid initialize params",
+		body:       `{"jsonrpc":"2.0","id":1,"method":"initialize","params":[]}`,
+		wantStatus: http.StatusBadRequest,
+		wantBody:   `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"json: cannot unmarshal array into Go value of type mcpgateway.initializeParams"}}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, upstream, _ := newTestGateway(t, false, func(*mcpgatewaycel.CheckRequest) policy.Evaluation[*mcpgatewaycel.CheckResponse] {
+				return policy.Evaluation[*mcpgatewaycel.CheckResponse]{Result: tt.result, Error: tt.err}
+			})
+			w := send(g, http.MethodPost, "", "", tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if strings.HasPrefix(tt.wantBody, "{") {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			upstream.lock.Lock()
+			defer upstream.lock.Unlock()
+			if tt.wantStatus == http.StatusOK && tt.result.Allowed {
+				assert.Equal(t, []string{tt.body}, upstream.received)
+			} else {
+				assert.Empty(t, upstream.received)
+			}
+		})
+	}
+}
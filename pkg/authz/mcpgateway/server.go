@@ -0,0 +1,39 @@
+package mcpgateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"k8s.io/client-go/dynamic"
+)
+
+func NewServer(config Config, source engine.MCPSource, dyn dynamic.Interface, eventIface events.EventIface[mcpgatewaycel.CheckRequest]) server.ServerFunc {
+	return func(ctx context.Context) error {
+		upstream, err := url.Parse(config.Upstream)
+		if err != nil {
+			return fmt.Errorf("invalid upstream url: %w", err)
+		}
+		if upstream.Scheme == "" || upstream.Host == "" {
+			return fmt.Errorf("invalid upstream url %q: scheme and host are required", config.Upstream)
+		}
+		// create the gateway
+		gateway := NewGateway(NewEngine(source), dyn, upstream, config.SessionIdleTimeout, config.MaxSessions, config.MaxBodySize, eventIface)
+		// create server
+		s := &http.Server{
+			Addr:    config.Address,
+			Handler: gateway,
+		}
+		// serve TLS if a certfile and a keyfile are provided
+		if config.CertFile != "" && config.KeyFile != "" {
+			s.TLSConfig = server.TLSConfig()
+		}
+		// run server
+		return server.RunHttp(ctx, s, config.CertFile, config.KeyFile)
+	}
+}
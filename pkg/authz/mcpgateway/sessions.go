@@ -0,0 +1,96 @@
+package mcpgateway
+
+import (
+	"sync"
+	"time"
+
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+)
+
+type sessionEntry struct {
+	session  mcpgatewaycel.Session
+	lastSeen time.Time
+}
+
+// sessions tracks the sessions initialized through the gateway, they are kept in memory
+// and are local to a gateway replica
+type sessions struct {
+	lock        sync.Mutex
+	idleTimeout time.Duration
+	maxSessions int
+	entries     map[string]*sessionEntry
+	now         func() time.Time
+}
+
+func newSessions(idleTimeout time.Duration, maxSessions int) *sessions {
+	return &sessions{
+		idleTimeout: idleTimeout,
+		maxSessions: maxSessions,
+		entries:     map[string]*sessionEntry{},
+		now:         time.Now,
+	}
+}
+
+// get returns a copy of the session and records the session activity
+func (s *sessions) get(id string) *mcpgatewaycel.Session {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil
+	}
+	now := s.now()
+	if s.expired(entry, now) {
+		delete(s.entries, id)
+		return nil
+	}
+	entry.lastSeen = now
+	session := entry.session
+	return &session
+}
+
+func (s *sessions) put(session mcpgatewaycel.Session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	if _, ok := s.entries[session.ID]; !ok && s.maxSessions > 0 && len(s.entries) >= s.maxSessions {
+		s.evict(now)
+	}
+	s.entries[session.ID] = &sessionEntry{
+		session:  session,
+		lastSeen: now,
+	}
+}
+
+func (s *sessions) update(id string, f func(*mcpgatewaycel.Session)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if entry, ok := s.entries[id]; ok {
+		f(&entry.session)
+	}
+}
+
+func (s *sessions) delete(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.entries, id)
+}
+
+// evict removes expired sessions, if none expired the least recently used session is removed
+func (s *sessions) evict(now time.Time) {
+	var oldest string
+	for id, entry := range s.entries {
+		if s.expired(entry, now) {
+			delete(s.entries, id)
+		} else if oldest == "" || entry.lastSeen.Before(s.entries[oldest].lastSeen) {
+			oldest = id
+		}
+	}
+	if len(s.entries) >= s.maxSessions && oldest != "" {
+		delete(s.entries, oldest)
+	}
+}
+
+func (s *sessions) expired(entry *sessionEntry, now time.Time) bool {
+	return s.idleTimeout > 0 && now.Sub(entry.lastSeen) > s.idleTimeout
+}
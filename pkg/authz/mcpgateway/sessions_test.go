@@ -0,0 +1,49 @@
+package mcpgateway
+
+import (
+	"testing"
+	"time"
+
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionsEviction(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newSessions(time.Minute, 2)
+	s.now = func() time.Time { return now }
+	s.put(mcpgatewaycel.Session{ID: "a"})
+	now = now.Add(time.Second)
+	s.put(mcpgatewaycel.Session{ID: "b"})
+	now = now.Add(time.Second)
+	// a is used after b was created, b is now the least recently used
+	assert.NotNil(t, s.get("a"))
+	s.put(mcpgatewaycel.Session{ID: "c"})
+	assert.NotNil(t, s.get("a"))
+	assert.Nil(t, s.get("b"))
+	assert.NotNil(t, s.get("c"))
+	// putting a known session doesn't evict
+	s.put(mcpgatewaycel.Session{ID: "c", ProtocolVersion: "2025-06-18"})
+	assert.Len(t, s.entries, 2)
+	assert.Equal(t, "2025-06-18", s.get("c").ProtocolVersion)
+}
+
+func TestSessionsIdleTimeout(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newSessions(time.Minute, 2)
+	s.now = func() time.Time { return now }
+	s.put(mcpgatewaycel.Session{ID: "a"})
+	s.put(mcpgatewaycel.Session{ID: "b"})
+	now = now.Add(30 * time.Second)
+	assert.NotNil(t, s.get("b"))
+	now = now.Add(45 * time.Second)
+	// a expired, it is evicted instead of the least recently used session
+	s.put(mcpgatewaycel.Session{ID: "c"})
+	assert.Len(t, s.entries, 2)
+	assert.NotNil(t, s.get("b"))
+	assert.NotNil(t, s.get("c"))
+	// expired sessions are forgotten when they are read
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, s.get("b"))
+	assert.Len(t, s.entries, 1)
+}
@@ -0,0 +1,60 @@
+package mcpgateway
+
+import (
+	"bytes"
+	"io"
+)
+
+// maxEventSize bounds the memory used to watch a stream, events or lines larger than that stop the watch
+const maxEventSize = 1 << 20
+
+// sseWatcher passes a server sent events stream through and calls onMessage with the
+// data of each event until onMessage returns true
+type sseWatcher struct {
+	io.ReadCloser
+	onMessage func([]byte) bool
+	line      []byte
+	data      []byte
+	done      bool
+}
+
+func (w *sseWatcher) Read(p []byte) (int, error) {
+	n, err := w.ReadCloser.Read(p)
+	if !w.done {
+		w.feed(p[:n])
+	}
+	return n, err
+}
+
+func (w *sseWatcher) feed(b []byte) {
+	w.line = append(w.line, b...)
+	for !w.done {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			if len(w.line) <= maxEventSize {
+				return
+			}
+			// the line never ends, stop watching instead of buffering the whole stream
+			w.done = true
+			break
+		}
+		line := bytes.TrimSuffix(w.line[:i], []byte("\r"))
+		w.line = w.line[i+1:]
+		if len(line) == 0 {
+			// an empty line dispatches the event
+			if len(w.data) != 0 {
+				w.done = w.onMessage(w.data)
+				w.data = nil
+			}
+		} else if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			if len(w.data) != 0 {
+				w.data = append(w.data, '\n')
+			}
+			w.data = append(w.data, bytes.TrimPrefix(value, []byte(" "))...)
+			if len(w.data) > maxEventSize {
+				w.done = true
+			}
+		}
+	}
+	w.line, w.data = nil, nil
+}
@@ -0,0 +1,67 @@
+package mcpgateway
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEWatcher(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		// stop is the message on which onMessage returns true
+		stop string
+		want []string
+	}{{
+		name:   "events",
+		stream: "event: message\ndata: one\n\n: comment\ndata:two\n\n",
+		want:   []string{"one", "two"},
+	}, {
+		name:   "multi line data and crlf",
+		stream: "data: first\r\ndata: second\r\n\r\n",
+		want:   []string{"first\nsecond"},
+	}, {
+		name:   "stops when the message is found",
+		stream: "data: one\n\ndata: two\n\ndata: three\n\n",
+		stop:   "two",
+		want:   []string{"one", "two"},
+	}, {
+		name:   "unterminated event",
+		stream: "data: one\n\ndata: two\n",
+		want:   []string{"one"},
+	}, {
+		name:   "line too long",
+		stream: "data: " + strings.Repeat("x", maxEventSize+1) + "\n\ndata: next\n\n",
+		want:   nil,
+	}, {
+		name:   "event too large",
+		stream: strings.Repeat("data: "+strings.Repeat("x", 1024)+"\n", 1025) + "\ndata: next\n\n",
+		want:   nil,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			// read small streams one byte at a time so that lines are split over several reads
+			var r io.Reader = strings.NewReader(tt.stream)
+			if len(tt.stream) < 1024 {
+				r = iotest.OneByteReader(r)
+			}
+			w := &sseWatcher{
+				ReadCloser: io.NopCloser(r),
+				onMessage: func(data []byte) bool {
+					got = append(got, string(data))
+					return string(data) == tt.stop
+				},
+			}
+			out, err := io.ReadAll(w)
+			assert.NoError(t, err)
+			// the stream is passed through untouched
+			assert.Equal(t, tt.stream, string(out))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sar
+
+import (
+	"context"
+	"time"
+
+	sarcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type authorizer struct {
+	engine       Engine
+	dyn          dynamic.Interface
+	eventHandler events.EventIface[sarcel.CheckRequest]
+}
+
+func NewAuthorizer(e Engine, dyn dynamic.Interface, eventIface events.EventIface[sarcel.CheckRequest]) *authorizer {
+	return &authorizer{
+		engine:       e,
+		dyn:          dyn,
+		eventHandler: eventIface,
+	}
+}
+
+// Review evaluates the review spec against the engine, when no policy returns a decision the webhook
+// has no opinion and the kube-apiserver falls back to the next authorizer (usually RBAC).
+// Evaluation errors are reported in EvaluationError, the kube-apiserver treats them as no opinion too,
+// a failing policy that would have denied the request therefore fails open to the next authorizer.
+func (a *authorizer) Review(ctx context.Context, review *authorizationv1.SubjectAccessReview) authorizationv1.SubjectAccessReviewStatus {
+	start := time.Now()
+	decision := metrics.DecisionError
+	source := metrics.SourceEngine
+	defer func() {
+		metrics.RecordAuthzDecision(metrics.ModeSAR, decision, source, start)
+	}()
+	logger := ctrl.LoggerFrom(ctx).WithValues("user", review.Spec.User)
+	logger.Info("received review")
+	request := sarcel.NewRequest(review.Spec)
+	response := a.engine.Handle(ctx, a.dyn, &request)
+	if response.Error != nil {
+		logger.Error(response.Error, "failed to evaluate review")
+		a.eventHandler.Push(context.Background(), time.Now(), request, events.NewResultAccessor(nil, response.Error))
+		// the kube-apiserver logs evaluation errors and continues with the next authorizer
+		return authorizationv1.SubjectAccessReviewStatus{
+			EvaluationError: response.Error.Error(),
+		}
+	}
+	result := response.Result
+	if result == nil {
+		result = &sarcel.CheckResponse{}
+		decision = metrics.DecisionNoMatch
+		source = metrics.SourceDefault
+	} else if result.Denied {
+		decision = metrics.DecisionDeny
+		source = metrics.SourcePolicy
+	} else {
+		// the engine drops results without opinion, a result is either denied or allowed
+		decision = metrics.DecisionAllow
+		source = metrics.SourcePolicy
+	}
+	a.eventHandler.Push(context.Background(), time.Now(), request, events.NewResultAccessor(*result, nil))
+	return authorizationv1.SubjectAccessReviewStatus{
+		// a policy can set both allowed and denied in the same decision, denied takes precedence
+		Allowed: result.Allowed && !result.Denied,
+		Denied:  result.Denied,
+		Reason:  result.Reason,
+	}
+}
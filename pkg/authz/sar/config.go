@@ -0,0 +1,7 @@
+package sar
+
+type Config struct {
+	Address  string
+	CertFile string
+	KeyFile  string
+}
@@ -0,0 +1,97 @@
+package sar
+
+import (
+	"context"
+
+	sarcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sequential"
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"github.com/kyverno/sdk/core"
+	"github.com/kyverno/sdk/core/dispatchers"
+	"github.com/kyverno/sdk/core/handlers"
+	"github.com/kyverno/sdk/extensions/policy"
+	"k8s.io/client-go/dynamic"
+)
+
+type Engine = sequential.Engine[*sarcel.CheckRequest, sarcel.CheckResponse]
+
+type (
+	sarPolicy  = policy.Policy[dynamic.Interface, *sarcel.CheckRequest, *sarcel.CheckResponse]
+	evaluation = policy.Evaluation[*sarcel.CheckResponse]
+)
+
+// NewEngine builds an engine evaluating policies sequentially until one of them denies the request,
+// the decisions of the evaluated policies are combined by the combiner
+func NewEngine(source engine.SubjectAccessReviewSource) Engine {
+	return core.NewEngine(
+		source,
+		handlers.Handler(
+			dispatchers.Sequential(
+				metrics.MetricsEvaluatorFactory(
+					policy.EvaluatorFactory[sarPolicy](),
+					func(out evaluation) string {
+						switch rank(out) {
+						case rankDenied:
+							return metrics.DecisionDeny
+						case rankError:
+							return metrics.DecisionError
+						case rankAllowed:
+							return metrics.DecisionAllow
+						default:
+							return metrics.DecisionNoMatch
+						}
+					},
+				),
+				func(ctx context.Context, fc core.FactoryContext[sarPolicy, dynamic.Interface, *sarcel.CheckRequest]) core.Breaker[sarPolicy, *sarcel.CheckRequest, evaluation] {
+					// only a denied decision is final, an allowed decision can still be denied by the next policies
+					return core.MakeBreakerFunc(func(_ context.Context, _ sarPolicy, _ *sarcel.CheckRequest, out evaluation) bool {
+						return rank(out) == rankDenied
+					})
+				},
+			),
+			func(ctx context.Context, fc core.FactoryContext[sarPolicy, dynamic.Interface, *sarcel.CheckRequest]) core.Resulter[sarPolicy, *sarcel.CheckRequest, evaluation, evaluation] {
+				return &combiner{}
+			},
+		),
+	)
+}
+
+const (
+	rankNoOpinion = iota
+	rankAllowed
+	rankError
+	rankDenied
+)
+
+// rank orders evaluations by precedence: denied, error, allowed then no opinion.
+// An error wins over an allowed decision because the failing policy could have denied the request,
+// the authorizer reports it as an evaluation error and the kube-apiserver asks the next authorizer.
+func rank(out evaluation) int {
+	switch {
+	case out.Result != nil && out.Result.Denied:
+		return rankDenied
+	case out.Error != nil:
+		return rankError
+	case out.Result != nil && out.Result.Allowed:
+		return rankAllowed
+	default:
+		// no result and results with neither allowed nor denied set have no opinion
+		return rankNoOpinion
+	}
+}
+
+// combiner keeps the first evaluation with the highest precedence
+type combiner struct {
+	result evaluation
+}
+
+func (c *combiner) Collect(_ context.Context, _ sarPolicy, _ *sarcel.CheckRequest, out evaluation) {
+	if rank(out) > rank(c.result) {
+		c.result = out
+	}
+}
+
+func (c *combiner) Result() evaluation {
+	return c.result
+}
@@ -0,0 +1,107 @@
+package sar
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sarcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/dynamic"
+)
+
+// policyFunc returns the result of the function, it records that it was evaluated
+type policyFunc struct {
+	result    *sarcel.CheckResponse
+	err       error
+	evaluated *bool
+}
+
+func (p policyFunc) Evaluate(context.Context, dynamic.Interface, *sarcel.CheckRequest) (*sarcel.CheckResponse, error) {
+	*p.evaluated = true
+	return p.result, p.err
+}
+
+// staticSource always loads the same policies
+type staticSource []sarPolicy
+
+func (s staticSource) Load(context.Context) ([]sarPolicy, error) {
+	return s, nil
+}
+
+func TestEngine(t *testing.T) {
+	allowed := &sarcel.CheckResponse{Allowed: true, Reason: "allowed"}
+	denied := &sarcel.CheckResponse{Denied: true, Reason: "denied"}
+	noOpinion := &sarcel.CheckResponse{}
+	boom := errors.New("boom")
+	type result struct {
+		result *sarcel.CheckResponse
+		err    error
+	}
+	tests := []struct {
+		name    string
+		results []result
+		want    *sarcel.CheckResponse
+		wantErr error
+		// wantEvaluated is the number of evaluated policies
+		wantEvaluated int
+	}{{
+		name:          "no policies",
+		want:          nil,
+		wantEvaluated: 0,
+	}, {
+		name:          "no opinion is skipped",
+		results:       []result{{result: noOpinion}, {result: nil}},
+		want:          nil,
+		wantEvaluated: 2,
+	}, {
+		name:          "no opinion followed by denied",
+		results:       []result{{result: noOpinion}, {result: denied}, {result: allowed}},
+		want:          denied,
+		wantEvaluated: 2,
+	}, {
+		name:          "allowed followed by denied",
+		results:       []result{{result: allowed}, {result: denied}},
+		want:          denied,
+		wantEvaluated: 2,
+	}, {
+		name:          "allowed alongside denied",
+		results:       []result{{result: noOpinion}, {result: &sarcel.CheckResponse{Allowed: true, Denied: true, Reason: "both"}}, {result: allowed}},
+		want:          &sarcel.CheckResponse{Allowed: true, Denied: true, Reason: "both"},
+		wantEvaluated: 2,
+	}, {
+		name:          "first allowed wins",
+		results:       []result{{result: allowed}, {result: noOpinion}, {result: &sarcel.CheckResponse{Allowed: true, Reason: "second"}}},
+		want:          allowed,
+		wantEvaluated: 3,
+	}, {
+		name:          "error wins over allowed",
+		results:       []result{{result: allowed}, {err: boom}, {result: noOpinion}},
+		wantErr:       boom,
+		wantEvaluated: 3,
+	}, {
+		name:          "denied wins over error",
+		results:       []result{{err: boom}, {result: denied}},
+		want:          denied,
+		wantEvaluated: 2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := make([]bool, len(tt.results))
+			var source staticSource
+			for i, r := range tt.results {
+				source = append(source, policyFunc{result: r.result, err: r.err, evaluated: &evaluated[i]})
+			}
+			out := NewEngine(source).Handle(context.Background(), nil, &sarcel.CheckRequest{User: "alice"})
+			assert.Equal(t, tt.want, out.Result)
+			assert.Equal(t, tt.wantErr, out.Error)
+			count := 0
+			for _, e := range evaluated {
+				if e {
+					count++
+				}
+			}
+			assert.Equal(t, tt.wantEvaluated, count)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package sar
+
+import (
+	"context"
+	"net/http"
+
+	sarcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/kyverno/kyverno-authz/pkg/engine"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/kyverno/kyverno-authz/pkg/server/handlers"
+	"k8s.io/client-go/dynamic"
+)
+
+func NewServer(config Config, source engine.SubjectAccessReviewSource, dyn dynamic.Interface, eventIface events.EventIface[sarcel.CheckRequest]) server.ServerFunc {
+	return func(ctx context.Context) error {
+		// create the authorizer
+		a := NewAuthorizer(NewEngine(source), dyn, eventIface)
+		// create mux
+		mux := http.NewServeMux()
+		mux.Handle("POST /{$}", handlers.SubjectAccessReview(a.Review))
+		// create server
+		s := &http.Server{
+			Addr:    config.Address,
+			Handler: mux,
+		}
+		// the kube-apiserver requires https, certificates are either provided or bootstrapped by the command
+		if config.CertFile != "" && config.KeyFile != "" {
+			s.TLSConfig = server.TLSConfig()
+		}
+		// run server
+		return server.RunHttp(ctx, s, config.CertFile, config.KeyFile)
+	}
+}
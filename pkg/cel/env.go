@@ -8,11 +8,26 @@ import (
 	vpol "github.com/kyverno/api/api/policies.kyverno.io/v1"
 	"github.com/kyverno/kyverno-authz/apis"
 	impl "github.com/kyverno/kyverno-authz/pkg/cel/impl"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/a2a"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/envoy"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
 	httpauth "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/body"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/crypto"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/geoip"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/graphql"
+	grpccel "github.com/kyverno/kyverno-authz/pkg/cel/libs/grpc"
 	jsoncel "github.com/kyverno/kyverno-authz/pkg/cel/libs/json"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/jwt"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/llm"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/mcp"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/oauth2"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/oidc"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/rate"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/timecel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/x509"
 	"github.com/kyverno/kyverno-authz/pkg/engine/variables"
 	"github.com/kyverno/sdk/cel/libs/http"
 	"github.com/kyverno/sdk/cel/libs/image"
@@ -51,7 +66,8 @@ func NewBaseEnv() (*cel.Env, error) {
 	)
 }
 
-func NewEnv(evalMode vpol.EvaluationMode, d dynamic.Interface) (*cel.Env, error) {
+func NewEnv(evalMode vpol.EvaluationMode, d dynamic.Interface, opts ...Option) (*cel.Env, error) {
+	options := newOptions(opts...)
 	base, err := NewBaseEnv()
 	if err != nil {
 		return nil, err
@@ -61,11 +77,24 @@ func NewEnv(evalMode vpol.EvaluationMode, d dynamic.Interface) (*cel.Env, error)
 	case apis.EvaluationModeEnvoy:
 		base, err = base.Extend(
 			envoy.Lib(),
+			grpccel.Lib(options.descriptors),
 		)
 	case apis.EvaluationModeHTTP:
 		base, err = base.Extend(
 			httpauth.Lib(),
 		)
+	case apis.EvaluationModeJSON:
+		base, err = base.Extend(
+			generic.Lib(),
+		)
+	case apis.EvaluationModeMCP:
+		base, err = base.Extend(
+			mcpgateway.Lib(),
+		)
+	case apis.EvaluationModeSubjectAccessReview:
+		base, err = base.Extend(
+			sar.Lib(),
+		)
 	default:
 		err = fmt.Errorf("invalid evaluation mode passed for env builder")
 	}
@@ -76,12 +105,28 @@ func NewEnv(evalMode vpol.EvaluationMode, d dynamic.Interface) (*cel.Env, error)
 	if err != nil {
 		return nil, err
 	}
+	// kubernetes secrets can only be read with a cluster
+	var secrets crypto.SecretGetter
+	if d != nil {
+		secrets = crypto.NewSecretGetter(d)
+	}
 	// create new cel env
 	return base.Extend(
 		http.Lib(http.Context{ContextInterface: http.NewHTTP()}, http.Latest()),
 		jwt.Lib(),
+		body.Lib(),
 		jsoncel.Lib(&impl.JsonImpl{}),
 		mcp.Lib(&impl.MCPImpl{}),
+		a2a.Lib(),
+		llm.Lib(),
+		graphql.Lib(),
+		oidc.Lib(),
+		oauth2.Lib(),
+		crypto.Lib(secrets),
+		rate.Lib(rate.Default()),
+		timecel.Lib(),
+		geoip.Lib(),
+		x509.Lib(),
 		resource.Lib(resource.Context{ContextInterface: variables.NewResourceProvider(d)}, "", resource.Latest()),
 		image.Lib(image.Latest()),
 		imagedata.Lib(imagedata.Context{ContextInterface: loader}, image.Latest()),
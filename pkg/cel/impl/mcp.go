@@ -2,7 +2,7 @@ package impl
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 
 	mcpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/mcp"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,16 +13,73 @@ type MCPImpl struct {
 }
 
 func (m *MCPImpl) Parse(content []byte) (*mcpcel.MCPRequest, error) {
-	var jsonRPCReq mcp.JSONRPCRequest
-	if err := json.Unmarshal(content, &jsonRPCReq); err != nil {
+	// a batch can carry any message, parsing only the first one would let the others through unchecked
+	if mcpcel.IsBatch(content) {
+		return nil, errors.New("json-rpc batches must be parsed with ParseBatch")
+	}
+	return parseMessage(content)
+}
+
+func (m *MCPImpl) ParseBatch(content []byte) ([]*mcpcel.MCPRequest, error) {
+	if !mcpcel.IsBatch(content) {
+		mcpReq, err := parseMessage(content)
+		if err != nil {
+			return nil, err
+		}
+		return []*mcpcel.MCPRequest{mcpReq}, nil
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(content, &messages); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("json-rpc batch is empty")
+	}
+	batch := make([]*mcpcel.MCPRequest, 0, len(messages))
+	for _, message := range messages {
+		if mcpcel.IsBatch(message) {
+			return nil, errors.New("json-rpc batches can't be nested")
+		}
+		mcpReq, err := parseMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, mcpReq)
+	}
+	return batch, nil
+}
+
+func parseMessage(content []byte) (*mcpcel.MCPRequest, error) {
+	var envelope struct {
+		ID     *mcp.RequestId           `json:"id"`
+		Method string                   `json:"method"`
+		Result json.RawMessage          `json:"result"`
+		Error  *mcp.JSONRPCErrorDetails `json:"error"`
+	}
+	if err := json.Unmarshal(content, &envelope); err != nil {
 		return nil, err
 	}
 
 	mcpReq := &mcpcel.MCPRequest{
-		Method: jsonRPCReq.Method,
-		ID:     jsonRPCReq.ID,
+		Method: envelope.Method,
+	}
+	if envelope.ID != nil {
+		mcpReq.ID = *envelope.ID
+	}
+
+	// messages without method are responses to a previous request
+	if mcpReq.Method == "" {
+		if envelope.Result == nil && envelope.Error == nil {
+			return nil, errors.New("json-rpc message has neither method, result nor error")
+		}
+		mcpReq.Response = true
+		mcpReq.Result = string(envelope.Result)
+		mcpReq.Error = envelope.Error
+		return mcpReq, nil
 	}
 
+	mcpReq.Notification = mcpReq.ID.IsNil()
+
 	switch mcpReq.Method {
 	case string(mcp.MethodInitialize):
 		params, err := unmarshalParams[mcp.InitializeParams](content)
@@ -31,14 +88,22 @@ func (m *MCPImpl) Parse(content []byte) (*mcpcel.MCPRequest, error) {
 		}
 		mcpReq.Initialize = params
 
-	case string(mcp.MethodPing):
-		break // Do nothing, no params
+	// No params
+	case string(mcp.MethodPing),
+		string(mcp.MethodListRoots),
+		mcpcel.MethodNotificationInitialized,
+		mcp.MethodNotificationResourcesListChanged,
+		mcp.MethodNotificationPromptsListChanged,
+		mcp.MethodNotificationToolsListChanged,
+		mcp.MethodNotificationRootsListChanged:
+		break
 
 	// All *List are using Paginated
 	case string(mcp.MethodResourcesList),
 		string(mcp.MethodResourcesTemplatesList),
 		string(mcp.MethodToolsList),
-		string(mcp.MethodPromptsList):
+		string(mcp.MethodPromptsList),
+		string(mcp.MethodTasksList):
 		params, err := unmarshalParams[mcp.PaginatedParams](content)
 		if err != nil {
 			return nil, err
@@ -52,12 +117,32 @@ func (m *MCPImpl) Parse(content []byte) (*mcpcel.MCPRequest, error) {
 		}
 		mcpReq.ResourceRead = params
 
+	case mcpcel.MethodResourcesSubscribe:
+		params, err := unmarshalParams[mcp.SubscribeParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.ResourceSubscribe = params
+
+	case mcpcel.MethodResourcesUnsubscribe:
+		params, err := unmarshalParams[mcp.UnsubscribeParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.ResourceUnsubscribe = params
+
+	case mcp.MethodNotificationResourceUpdated:
+		params, err := unmarshalParams[mcp.ResourceUpdatedNotificationParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.ResourceUpdated = params
+
 	case string(mcp.MethodToolsCall):
 		params, err := unmarshalParams[mcp.CallToolParams](content)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println("mcp.MethodToolsCall params", params)
 		mcpReq.ToolCall = params
 
 	case string(mcp.MethodSetLogLevel):
@@ -74,12 +159,68 @@ func (m *MCPImpl) Parse(content []byte) (*mcpcel.MCPRequest, error) {
 		}
 		mcpReq.PromptGet = params
 
+	case string(mcp.MethodSamplingCreateMessage):
+		params, err := unmarshalParams[mcp.CreateMessageParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.CreateMessage = params
+
 	case string(mcp.MethodElicitationCreate):
 		params, err := unmarshalParams[mcp.ElicitationParams](content)
 		if err != nil {
 			return nil, err
 		}
 		mcpReq.Elicitation = params
+
+	case string(mcp.MethodCompletionComplete):
+		params, err := unmarshalParams[mcp.CompleteParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.Complete = params
+
+	case string(mcp.MethodTasksGet):
+		params, err := unmarshalParams[mcp.GetTaskParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.TaskGet = params
+
+	case string(mcp.MethodTasksResult):
+		params, err := unmarshalParams[mcp.TaskResultParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.TaskResult = params
+
+	case string(mcp.MethodTasksCancel):
+		params, err := unmarshalParams[mcp.CancelTaskParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.TaskCancel = params
+
+	case mcpcel.MethodNotificationCancelled:
+		params, err := unmarshalParams[mcp.CancelledNotificationParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.Cancelled = params
+
+	case mcpcel.MethodNotificationProgress:
+		params, err := unmarshalParams[mcp.ProgressNotificationParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.Progress = params
+
+	case mcpcel.MethodNotificationMessage:
+		params, err := unmarshalParams[mcp.LoggingMessageNotificationParams](content)
+		if err != nil {
+			return nil, err
+		}
+		mcpReq.LogMessage = params
 	}
 
 	return mcpReq, nil
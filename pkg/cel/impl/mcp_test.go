@@ -0,0 +1,255 @@
+package impl
+
+import (
+	"testing"
+
+	mcpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/mcp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(*testing.T, *mcpcel.MCPRequest)
+		wantErr string
+	}{{
+		name:    "request",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.Equal(t, "tools/call", req.Method)
+			assert.Equal(t, "int64:1", req.ID.String())
+			assert.False(t, req.Notification)
+			assert.False(t, req.Response)
+			require.NotNil(t, req.ToolCall)
+			assert.Equal(t, "echo", req.ToolCall.Name)
+			assert.Equal(t, map[string]any{"text": "hi"}, req.ToolCall.Arguments)
+		},
+	}, {
+		name:    "string id",
+		content: `{"jsonrpc":"2.0","id":"abc","method":"ping"}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.Equal(t, "ping", req.Method)
+			assert.Equal(t, "string:abc", req.ID.String())
+			assert.False(t, req.Notification)
+		},
+	}, {
+		name:    "notification",
+		content: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.Equal(t, "notifications/initialized", req.Method)
+			assert.True(t, req.ID.IsNil())
+			assert.True(t, req.Notification)
+			assert.False(t, req.Response)
+		},
+	}, {
+		name:    "result response",
+		content: `{"jsonrpc":"2.0","id":2,"result":{"content":[]}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.Empty(t, req.Method)
+			assert.Equal(t, "int64:2", req.ID.String())
+			assert.True(t, req.Response)
+			assert.False(t, req.Notification)
+			assert.JSONEq(t, `{"content":[]}`, req.Result)
+			assert.Nil(t, req.Error)
+		},
+	}, {
+		name:    "error response",
+		content: `{"jsonrpc":"2.0","id":3,"error":{"code":-32601,"message":"method not found"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.True(t, req.Response)
+			assert.Empty(t, req.Result)
+			require.NotNil(t, req.Error)
+			assert.Equal(t, -32601, req.Error.Code)
+			assert.Equal(t, "method not found", req.Error.Message)
+		},
+	}, {
+		name:    "resources/subscribe",
+		content: `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"file:///a"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.ResourceSubscribe)
+			assert.Equal(t, "file:///a", req.ResourceSubscribe.URI)
+		},
+	}, {
+		name:    "resources/unsubscribe",
+		content: `{"jsonrpc":"2.0","id":1,"method":"resources/unsubscribe","params":{"uri":"file:///a"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.ResourceUnsubscribe)
+			assert.Equal(t, "file:///a", req.ResourceUnsubscribe.URI)
+		},
+	}, {
+		name:    "notifications/resources/updated",
+		content: `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.True(t, req.Notification)
+			require.NotNil(t, req.ResourceUpdated)
+			assert.Equal(t, "file:///a", req.ResourceUpdated.URI)
+		},
+	}, {
+		name:    "sampling/createMessage",
+		content: `{"jsonrpc":"2.0","id":1,"method":"sampling/createMessage","params":{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}],"maxTokens":100}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.CreateMessage)
+			assert.Equal(t, 100, req.CreateMessage.MaxTokens)
+			assert.Len(t, req.CreateMessage.Messages, 1)
+		},
+	}, {
+		name:    "elicitation/create",
+		content: `{"jsonrpc":"2.0","id":1,"method":"elicitation/create","params":{"message":"your name?"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.Elicitation)
+			assert.Equal(t, "your name?", req.Elicitation.Message)
+		},
+	}, {
+		name:    "completion/complete",
+		content: `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"greet"},"argument":{"name":"lang","value":"en"}}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.Complete)
+			assert.Equal(t, "lang", req.Complete.Argument.Name)
+			assert.Equal(t, "en", req.Complete.Argument.Value)
+		},
+	}, {
+		name:    "tasks/list",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tasks/list","params":{"cursor":"next"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.Paginated)
+			assert.Equal(t, mcp.Cursor("next"), req.Paginated.Cursor)
+		},
+	}, {
+		name:    "tasks/get",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"taskId":"t1"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.TaskGet)
+			assert.Equal(t, "t1", req.TaskGet.TaskId)
+		},
+	}, {
+		name:    "tasks/result",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tasks/result","params":{"taskId":"t1"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.TaskResult)
+			assert.Equal(t, "t1", req.TaskResult.TaskId)
+		},
+	}, {
+		name:    "tasks/cancel",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tasks/cancel","params":{"taskId":"t1"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.TaskCancel)
+			assert.Equal(t, "t1", req.TaskCancel.TaskId)
+		},
+	}, {
+		name:    "notifications/cancelled",
+		content: `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"timeout"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.True(t, req.Notification)
+			require.NotNil(t, req.Cancelled)
+			assert.Equal(t, "int64:7", req.Cancelled.RequestId.String())
+			assert.Equal(t, "timeout", req.Cancelled.Reason)
+		},
+	}, {
+		name:    "notifications/progress",
+		content: `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"p1","progress":50,"total":100}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.Progress)
+			assert.Equal(t, "p1", req.Progress.ProgressToken)
+			assert.Equal(t, 50.0, req.Progress.Progress)
+			assert.Equal(t, 100.0, req.Progress.Total)
+		},
+	}, {
+		name:    "notifications/message",
+		content: `{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"error","logger":"db","data":"down"}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			require.NotNil(t, req.LogMessage)
+			assert.Equal(t, mcp.LoggingLevelError, req.LogMessage.Level)
+			assert.Equal(t, "db", req.LogMessage.Logger)
+			assert.Equal(t, "down", req.LogMessage.Data)
+		},
+	}, {
+		name:    "unknown method",
+		content: `{"jsonrpc":"2.0","id":1,"method":"custom/method","params":{"a":1}}`,
+		check: func(t *testing.T, req *mcpcel.MCPRequest) {
+			assert.Equal(t, "custom/method", req.Method)
+			assert.Nil(t, req.GetArguments())
+		},
+	}, {
+		name:    "neither method, result nor error",
+		content: `{"jsonrpc":"2.0","id":1}`,
+		wantErr: "json-rpc message has neither method, result nor error",
+	}, {
+		name:    "invalid params",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"taskId":1}}`,
+		wantErr: "cannot unmarshal",
+	}, {
+		name:    "invalid json",
+		content: `{"jsonrpc":`,
+		wantErr: "unexpected end of JSON input",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMessage([]byte(tt.content))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestMCPImpl_ParseBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		methods []string
+		wantErr string
+	}{{
+		name:    "single message",
+		content: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		methods: []string{"tools/list"},
+	}, {
+		name:    "batch",
+		content: ` [{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"result":{}}]`,
+		methods: []string{"tools/list", "notifications/initialized", ""},
+	}, {
+		name:    "empty batch",
+		content: `[]`,
+		wantErr: "json-rpc batch is empty",
+	}, {
+		name:    "nested batch",
+		content: `[{"jsonrpc":"2.0","id":1,"method":"ping"},[{"jsonrpc":"2.0","id":2,"method":"ping"}]]`,
+		wantErr: "json-rpc batches can't be nested",
+	}, {
+		name:    "invalid message",
+		content: `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2}]`,
+		wantErr: "json-rpc message has neither method, result nor error",
+	}, {
+		name:    "invalid batch",
+		content: `[{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		wantErr: "unexpected end of JSON input",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&MCPImpl{}).ParseBatch([]byte(tt.content))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			methods := make([]string, 0, len(got))
+			for _, req := range got {
+				methods = append(methods, req.Method)
+			}
+			assert.Equal(t, tt.methods, methods)
+		})
+	}
+}
+
+func TestMCPImpl_Parse(t *testing.T) {
+	got, err := (&MCPImpl{}).Parse([]byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "ping", got.Method)
+	_, err = (&MCPImpl{}).Parse([]byte(` [{"jsonrpc":"2.0","id":1,"method":"ping"}]`))
+	assert.ErrorContains(t, err, "json-rpc batches must be parsed with ParseBatch")
+}
@@ -0,0 +1,57 @@
+package a2a
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse_string(content ref.Val) ref.Val {
+	if content, err := utils.ConvertToNative[string](content); err != nil {
+		return types.WrapErr(err)
+	} else if request, err := Parse([]byte(content)); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(request)
+	}
+}
+
+func (c *impl) parse_agent_card_string(content ref.Val) ref.Val {
+	if content, err := utils.ConvertToNative[string](content); err != nil {
+		return types.WrapErr(err)
+	} else if card, err := ParseAgentCard([]byte(content)); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(card)
+	}
+}
+
+func (c *impl) is_agent_card_path_string(path ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(IsAgentCardPath(path))
+	}
+}
+
+func (c *impl) message_text(message ref.Val) ref.Val {
+	if message, err := utils.ConvertToNative[*Message](message); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(message.Text())
+	}
+}
+
+func (c *impl) agent_card_has_skill_string(card ref.Val, id ref.Val) ref.Val {
+	if card, err := utils.ConvertToNative[*AgentCard](card); err != nil {
+		return types.WrapErr(err)
+	} else if id, err := utils.ConvertToNative[string](id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(card.HasSkill(id))
+	}
+}
@@ -0,0 +1,85 @@
+package a2a
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.a2a"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Request](),
+			reflect.TypeFor[AgentCard](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// known methods are exposed as constants
+	constants := map[string]string{
+		"MessageSendMethod":                       MethodMessageSend,
+		"MessageStreamMethod":                     MethodMessageStream,
+		"TasksGetMethod":                          MethodTasksGet,
+		"TasksCancelMethod":                       MethodTasksCancel,
+		"TasksResubscribeMethod":                  MethodTasksResubscribe,
+		"TasksPushNotificationConfigSetMethod":    MethodTasksPushNotificationConfigSet,
+		"TasksPushNotificationConfigGetMethod":    MethodTasksPushNotificationConfigGet,
+		"TasksPushNotificationConfigListMethod":   MethodTasksPushNotificationConfigList,
+		"TasksPushNotificationConfigDeleteMethod": MethodTasksPushNotificationConfigDelete,
+		"AgentGetAuthenticatedExtendedCardMethod": MethodAgentGetAuthenticatedExtendedCard,
+	}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"a2a.Parse": {
+			cel.Overload("a2a_parse_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parse_string)),
+		},
+		"a2a.ParseAgentCard": {
+			cel.Overload("a2a_parse_agent_card_string", []*cel.Type{types.StringType}, AgentCardType, cel.UnaryBinding(impl.parse_agent_card_string)),
+		},
+		"a2a.IsAgentCardPath": {
+			cel.Overload("a2a_is_agent_card_path_string", []*cel.Type{types.StringType}, types.BoolType, cel.UnaryBinding(impl.is_agent_card_path_string)),
+		},
+		"text": {
+			cel.MemberOverload("a2a_message_text", []*cel.Type{MessageType}, types.StringType, cel.UnaryBinding(impl.message_text)),
+		},
+		"hasSkill": {
+			cel.MemberOverload("a2a_agent_card_has_skill_string", []*cel.Type{AgentCardType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.agent_card_has_skill_string)),
+		},
+	}
+	// create env options corresponding to our constants and function overloads
+	options := []cel.EnvOption{}
+	for name, value := range constants {
+		options = append(options, cel.Constant("a2a."+name, types.StringType, types.String(value)))
+	}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,191 @@
+package a2a
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLib(t *testing.T) {
+	const (
+		send = `{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "message/send",
+			"params": {
+				"message": {
+					"role": "user",
+					"messageId": "msg-1",
+					"taskId": "task-1",
+					"contextId": "ctx-1",
+					"parts": [
+						{ "kind": "text", "text": "book a flight" },
+						{ "kind": "file", "file": { "name": "passport.pdf", "mimeType": "application/pdf", "uri": "https://example.com/passport.pdf" } },
+						{ "kind": "data", "data": { "destination": "Paris" } },
+						{ "kind": "text", "text": "to Paris" }
+					],
+					"metadata": { "skillId": "book-flight" }
+				},
+				"configuration": { "acceptedOutputModes": ["text/plain"], "blocking": true }
+			}
+		}`
+		get        = `{ "jsonrpc": "2.0", "id": "req-1", "method": "tasks/get", "params": { "id": "task-1", "historyLength": 10 } }`
+		cancel     = `{ "jsonrpc": "2.0", "id": 2, "method": "tasks/cancel", "params": { "id": "task-1" } }`
+		pushConfig = `{
+			"jsonrpc": "2.0",
+			"id": 3,
+			"method": "tasks/pushNotificationConfig/set",
+			"params": {
+				"taskId": "task-1",
+				"pushNotificationConfig": { "id": "cfg-1", "url": "https://hooks.example.com", "authentication": { "schemes": ["Bearer"] } }
+			}
+		}`
+		card = `{
+			"name": "travel agent",
+			"url": "https://agent.example.com/a2a",
+			"version": "1.0.0",
+			"protocolVersion": "0.3.0",
+			"capabilities": { "streaming": true },
+			"skills": [
+				{ "id": "book-flight", "name": "Book flight", "tags": ["travel"] },
+				{ "id": "book-hotel", "name": "Book hotel", "tags": ["travel"] }
+			]
+		}`
+	)
+	tests := []struct {
+		name    string
+		source  string
+		body    string
+		want    any
+		wantErr bool
+	}{{
+		name:   "method",
+		source: `a2a.Parse(body).method == a2a.MessageSendMethod`,
+		body:   send,
+		want:   true,
+	}, {
+		name:   "numeric id",
+		source: `a2a.Parse(body).id`,
+		body:   send,
+		want:   "1",
+	}, {
+		name:   "string id",
+		source: `a2a.Parse(body).id`,
+		body:   get,
+		want:   "req-1",
+	}, {
+		name:   "message",
+		source: `a2a.Parse(body).message.role + "/" + a2a.Parse(body).message.contextId`,
+		body:   send,
+		want:   "user/ctx-1",
+	}, {
+		name:   "message task",
+		source: `a2a.Parse(body).taskId`,
+		body:   send,
+		want:   "task-1",
+	}, {
+		name:   "message text",
+		source: `a2a.Parse(body).message.text()`,
+		body:   send,
+		want:   "book a flight\nto Paris",
+	}, {
+		name:   "file part",
+		source: `a2a.Parse(body).message.parts.filter(p, p.kind == "file").map(p, p.file.mimeType)`,
+		body:   send,
+		want:   []string{"application/pdf"},
+	}, {
+		name:   "data part",
+		source: `a2a.Parse(body).message.parts[2].data`,
+		body:   send,
+		want:   `{ "destination": "Paris" }`,
+	}, {
+		name:   "configuration",
+		source: `a2a.Parse(body).configuration.blocking && "text/plain" in a2a.Parse(body).configuration.acceptedOutputModes`,
+		body:   send,
+		want:   true,
+	}, {
+		name:   "tasks get",
+		source: `a2a.Parse(body).taskId + "/" + string(a2a.Parse(body).historyLength)`,
+		body:   get,
+		want:   "task-1/10",
+	}, {
+		name:   "tasks cancel",
+		source: `a2a.Parse(body).method == a2a.TasksCancelMethod && a2a.Parse(body).taskId == "task-1"`,
+		body:   cancel,
+		want:   true,
+	}, {
+		name:   "push notification config",
+		source: `a2a.Parse(body).pushNotificationConfig.url + " " + a2a.Parse(body).pushNotificationConfig.schemes[0]`,
+		body:   pushConfig,
+		want:   "https://hooks.example.com Bearer",
+	}, {
+		name:   "unknown method",
+		source: `a2a.Parse(body).params`,
+		body:   `{ "jsonrpc": "2.0", "id": 4, "method": "custom/method", "params": {"foo":"bar"} }`,
+		want:   `{"foo":"bar"}`,
+	}, {
+		name:   "agent card",
+		source: `a2a.ParseAgentCard(body).skills.map(s, s.id)`,
+		body:   card,
+		want:   []string{"book-flight", "book-hotel"},
+	}, {
+		name:   "agent card has skill",
+		source: `a2a.ParseAgentCard(body).hasSkill("book-hotel") && !a2a.ParseAgentCard(body).hasSkill("rent-car")`,
+		body:   card,
+		want:   true,
+	}, {
+		name:   "agent card capabilities",
+		source: `a2a.ParseAgentCard(body).capabilities.streaming`,
+		body:   card,
+		want:   true,
+	}, {
+		name:   "agent card path",
+		source: `a2a.IsAgentCardPath("/.well-known/agent-card.json") && a2a.IsAgentCardPath("/agents/travel/.well-known/agent.json") && !a2a.IsAgentCardPath("/a2a")`,
+		want:   true,
+	}, {
+		name:    "batch",
+		source:  `a2a.Parse(body)`,
+		body:    `[` + cancel + `]`,
+		wantErr: true,
+	}, {
+		name:    "no method",
+		source:  `a2a.Parse(body)`,
+		body:    `{ "jsonrpc": "2.0", "id": 1, "result": {} }`,
+		wantErr: true,
+	}, {
+		name:    "missing message",
+		source:  `a2a.Parse(body)`,
+		body:    `{ "jsonrpc": "2.0", "id": 1, "method": "message/send", "params": {} }`,
+		wantErr: true,
+	}, {
+		name:    "invalid json",
+		source:  `a2a.Parse(body)`,
+		body:    `{`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("body", cel.StringType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"body": tt.body})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if want, ok := tt.want.([]string); ok {
+					got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				} else {
+					assert.Equal(t, tt.want, out.Value())
+				}
+			}
+		})
+	}
+}
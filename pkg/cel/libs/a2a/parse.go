@@ -0,0 +1,151 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	mcpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/mcp"
+)
+
+// agent cards are served at a well known path, agent.json was used before the 0.3 version of the protocol
+var agentCardPaths = []string{
+	"/.well-known/agent-card.json",
+	"/.well-known/agent.json",
+}
+
+func Parse(content []byte) (*Request, error) {
+	if mcpcel.IsBatch(content) {
+		return nil, errors.New("a2a doesn't support json-rpc batches")
+	}
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Method == "" {
+		return nil, errors.New("a2a request has no method")
+	}
+	request := &Request{
+		ID:     requestID(envelope.ID),
+		Method: envelope.Method,
+		Params: rawJSON(envelope.Params),
+	}
+	if len(envelope.Params) == 0 {
+		return request, nil
+	}
+	switch request.Method {
+	case MethodMessageSend, MethodMessageStream:
+		var params struct {
+			Message       *Message           `json:"message"`
+			Configuration *SendConfiguration `json:"configuration"`
+			Metadata      rawJSON            `json:"metadata"`
+		}
+		if err := json.Unmarshal(envelope.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid %s params: %w", request.Method, err)
+		}
+		if params.Message == nil {
+			return nil, fmt.Errorf("invalid %s params: message is missing", request.Method)
+		}
+		request.Message = params.Message
+		request.Configuration = params.Configuration
+		request.Metadata = params.Metadata
+		// messages sent to an existing task are authorized against that task
+		request.TaskID = params.Message.TaskID
+	case MethodTasksGet,
+		MethodTasksCancel,
+		MethodTasksResubscribe,
+		MethodTasksPushNotificationConfigGet,
+		MethodTasksPushNotificationConfigList,
+		MethodTasksPushNotificationConfigDelete:
+		var params struct {
+			ID                       string  `json:"id"`
+			HistoryLength            int     `json:"historyLength"`
+			PushNotificationConfigID string  `json:"pushNotificationConfigId"`
+			Metadata                 rawJSON `json:"metadata"`
+		}
+		if err := json.Unmarshal(envelope.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid %s params: %w", request.Method, err)
+		}
+		request.TaskID = params.ID
+		request.HistoryLength = params.HistoryLength
+		request.PushNotificationConfigID = params.PushNotificationConfigID
+		request.Metadata = params.Metadata
+	case MethodTasksPushNotificationConfigSet:
+		var params struct {
+			TaskID                 string `json:"taskId"`
+			PushNotificationConfig *struct {
+				ID             string `json:"id"`
+				URL            string `json:"url"`
+				Token          string `json:"token"`
+				Authentication *struct {
+					Schemes []string `json:"schemes"`
+				} `json:"authentication"`
+			} `json:"pushNotificationConfig"`
+		}
+		if err := json.Unmarshal(envelope.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid %s params: %w", request.Method, err)
+		}
+		request.TaskID = params.TaskID
+		if config := params.PushNotificationConfig; config != nil {
+			request.PushNotificationConfig = &PushNotificationConfig{
+				ID:    config.ID,
+				URL:   config.URL,
+				Token: config.Token,
+			}
+			if config.Authentication != nil {
+				request.PushNotificationConfig.Schemes = config.Authentication.Schemes
+			}
+			request.PushNotificationConfigID = config.ID
+		}
+	}
+	return request, nil
+}
+
+func ParseAgentCard(content []byte) (*AgentCard, error) {
+	var card AgentCard
+	if err := json.Unmarshal(content, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func IsAgentCardPath(path string) bool {
+	return slices.ContainsFunc(agentCardPaths, func(p string) bool {
+		return strings.HasSuffix(path, p)
+	})
+}
+
+// Text returns the content of the text parts of the message
+func (m *Message) Text() string {
+	var texts []string
+	for _, part := range m.Parts {
+		if part.Kind == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (c *AgentCard) HasSkill(id string) bool {
+	return slices.ContainsFunc(c.Skills, func(s Skill) bool {
+		return s.ID == id
+	})
+}
+
+// requestID returns json-rpc ids as strings, whether they were sent as strings or numbers
+func requestID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
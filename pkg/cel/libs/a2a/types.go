@@ -0,0 +1,129 @@
+package a2a
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	RequestType                = types.NewObjectType("a2a.Request")
+	MessageType                = types.NewObjectType("a2a.Message")
+	PartType                   = types.NewObjectType("a2a.Part")
+	FileType                   = types.NewObjectType("a2a.File")
+	SendConfigurationType      = types.NewObjectType("a2a.SendConfiguration")
+	PushNotificationConfigType = types.NewObjectType("a2a.PushNotificationConfig")
+	AgentCardType              = types.NewObjectType("a2a.AgentCard")
+	AgentCapabilitiesType      = types.NewObjectType("a2a.AgentCapabilities")
+	SkillType                  = types.NewObjectType("a2a.Skill")
+)
+
+// A2A json-rpc methods, see https://a2a-protocol.org/latest/specification/
+const (
+	MethodMessageSend                       = "message/send"
+	MethodMessageStream                     = "message/stream"
+	MethodTasksGet                          = "tasks/get"
+	MethodTasksCancel                       = "tasks/cancel"
+	MethodTasksResubscribe                  = "tasks/resubscribe"
+	MethodTasksPushNotificationConfigSet    = "tasks/pushNotificationConfig/set"
+	MethodTasksPushNotificationConfigGet    = "tasks/pushNotificationConfig/get"
+	MethodTasksPushNotificationConfigList   = "tasks/pushNotificationConfig/list"
+	MethodTasksPushNotificationConfigDelete = "tasks/pushNotificationConfig/delete"
+	MethodAgentGetAuthenticatedExtendedCard = "agent/getAuthenticatedExtendedCard"
+)
+
+// rawJSON holds a json value as is, policies decode it with json.Unmarshal when needed
+type rawJSON string
+
+func (r *rawJSON) UnmarshalJSON(data []byte) error {
+	*r = rawJSON(data)
+	return nil
+}
+
+// Request is a parsed A2A json-rpc request, only the fields relevant to the method are set
+type Request struct {
+	ID     string  `json:"id"     cel:"id"`
+	Method string  `json:"method" cel:"method"`
+	Params rawJSON `json:"params" cel:"params"`
+	// message/send and message/stream
+	Message       *Message           `json:"message,omitempty"       cel:"message"`
+	Configuration *SendConfiguration `json:"configuration,omitempty" cel:"configuration"`
+	// tasks/* methods
+	TaskID        string `json:"taskId"        cel:"taskId"`
+	HistoryLength int    `json:"historyLength" cel:"historyLength"`
+	// tasks/pushNotificationConfig/* methods
+	PushNotificationConfig   *PushNotificationConfig `json:"pushNotificationConfig,omitempty" cel:"pushNotificationConfig"`
+	PushNotificationConfigID string                  `json:"pushNotificationConfigId"         cel:"pushNotificationConfigId"`
+	Metadata                 rawJSON                 `json:"metadata"                         cel:"metadata"`
+}
+
+type Message struct {
+	Role             string   `json:"role"             cel:"role"`
+	MessageID        string   `json:"messageId"        cel:"messageId"`
+	TaskID           string   `json:"taskId"           cel:"taskId"`
+	ContextID        string   `json:"contextId"        cel:"contextId"`
+	ReferenceTaskIDs []string `json:"referenceTaskIds" cel:"referenceTaskIds"`
+	Extensions       []string `json:"extensions"       cel:"extensions"`
+	Parts            []Part   `json:"parts"            cel:"parts"`
+	Metadata         rawJSON  `json:"metadata"         cel:"metadata"`
+}
+
+// Part is a text, file or data part of a message, Kind tells which one is set
+type Part struct {
+	Kind     string  `json:"kind"           cel:"kind"`
+	Text     string  `json:"text"           cel:"text"`
+	File     *File   `json:"file,omitempty" cel:"file"`
+	Data     rawJSON `json:"data"           cel:"data"`
+	Metadata rawJSON `json:"metadata"       cel:"metadata"`
+}
+
+// File is either sent inline (base64 encoded Bytes) or by reference (URI)
+type File struct {
+	Name     string `json:"name"     cel:"name"`
+	MimeType string `json:"mimeType" cel:"mimeType"`
+	Bytes    string `json:"bytes"    cel:"bytes"`
+	URI      string `json:"uri"      cel:"uri"`
+}
+
+type SendConfiguration struct {
+	AcceptedOutputModes []string `json:"acceptedOutputModes" cel:"acceptedOutputModes"`
+	Blocking            bool     `json:"blocking"            cel:"blocking"`
+	HistoryLength       int      `json:"historyLength"       cel:"historyLength"`
+}
+
+type PushNotificationConfig struct {
+	ID    string `json:"id"    cel:"id"`
+	URL   string `json:"url"   cel:"url"`
+	Token string `json:"token" cel:"token"`
+	// Schemes lists the authentication schemes the agent uses to call the url
+	Schemes []string `json:"schemes" cel:"schemes"`
+}
+
+// AgentCard describes an agent, it is served at /.well-known/agent-card.json
+type AgentCard struct {
+	Name                              string            `json:"name"                              cel:"name"`
+	Description                       string            `json:"description"                       cel:"description"`
+	URL                               string            `json:"url"                               cel:"url"`
+	Version                           string            `json:"version"                           cel:"version"`
+	ProtocolVersion                   string            `json:"protocolVersion"                   cel:"protocolVersion"`
+	PreferredTransport                string            `json:"preferredTransport"                cel:"preferredTransport"`
+	Capabilities                      AgentCapabilities `json:"capabilities"                      cel:"capabilities"`
+	DefaultInputModes                 []string          `json:"defaultInputModes"                 cel:"defaultInputModes"`
+	DefaultOutputModes                []string          `json:"defaultOutputModes"                cel:"defaultOutputModes"`
+	Skills                            []Skill           `json:"skills"                            cel:"skills"`
+	SupportsAuthenticatedExtendedCard bool              `json:"supportsAuthenticatedExtendedCard" cel:"supportsAuthenticatedExtendedCard"`
+}
+
+type AgentCapabilities struct {
+	Streaming              bool `json:"streaming"              cel:"streaming"`
+	PushNotifications      bool `json:"pushNotifications"      cel:"pushNotifications"`
+	StateTransitionHistory bool `json:"stateTransitionHistory" cel:"stateTransitionHistory"`
+}
+
+type Skill struct {
+	ID          string   `json:"id"          cel:"id"`
+	Name        string   `json:"name"        cel:"name"`
+	Description string   `json:"description" cel:"description"`
+	Tags        []string `json:"tags"        cel:"tags"`
+	Examples    []string `json:"examples"    cel:"examples"`
+	InputModes  []string `json:"inputModes"  cel:"inputModes"`
+	OutputModes []string `json:"outputModes" cel:"outputModes"`
+}
@@ -0,0 +1,99 @@
+package envoy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typesv3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcDeniedResponse builds a denied response carrying the grpc status in the response headers,
+// the http status is derived from the grpc code for clients that don't understand grpc
+func grpcDeniedResponse(st *status.Status) (*authv3.CheckResponse, error) {
+	code := codes.Code(st.GetCode())
+	if code == codes.OK || code > codes.Unauthenticated {
+		return nil, fmt.Errorf("invalid grpc status code for a denied response: %d", st.GetCode())
+	}
+	headers := []*corev3.HeaderValueOption{
+		grpcHeader("content-type", "application/grpc"),
+		grpcHeader("grpc-status", strconv.Itoa(int(code))),
+	}
+	if st.GetMessage() != "" {
+		headers = append(headers, grpcHeader("grpc-message", encodeGrpcMessage(st.GetMessage())))
+	}
+	if len(st.GetDetails()) != 0 {
+		details, err := proto.Marshal(st)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, grpcHeader("grpc-status-details-bin", base64.RawStdEncoding.EncodeToString(details)))
+	}
+	return &authv3.CheckResponse{
+		Status: st,
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status:  &typesv3.HttpStatus{Code: typesv3.StatusCode(httpStatusFromCode(code))},
+				Headers: headers,
+			},
+		},
+	}, nil
+}
+
+func grpcHeader(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
+
+// encodeGrpcMessage percent encodes the message as described in
+// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md#responses
+func encodeGrpcMessage(message string) string {
+	var sb strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// httpStatusFromCode maps grpc codes to http statuses as described in
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
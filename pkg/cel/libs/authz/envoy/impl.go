@@ -9,6 +9,8 @@ import (
 	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
 	status "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -236,3 +238,45 @@ func (c *impl) response_with_metadata(response ref.Val, metadata ref.Val) ref.Va
 		return c.NativeToValue(response)
 	}
 }
+
+func (c *impl) grpc_denied(code ref.Val) ref.Val {
+	if code, err := utils.ConvertToNative[int32](code); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(&status.Status{Code: code})
+	}
+}
+
+func (c *impl) grpc_status_with_message(st ref.Val, message ref.Val) ref.Val {
+	if st, err := utils.ConvertToNative[*status.Status](st); err != nil {
+		return types.WrapErr(err)
+	} else if message, err := utils.ConvertToNative[string](message); err != nil {
+		return types.WrapErr(err)
+	} else {
+		st.Message = message
+		return c.NativeToValue(st)
+	}
+}
+
+func (c *impl) grpc_status_with_detail(st ref.Val, detail ref.Val) ref.Val {
+	if st, err := utils.ConvertToNative[*status.Status](st); err != nil {
+		return types.WrapErr(err)
+	} else if detail, err := utils.ConvertToNative[proto.Message](detail); err != nil {
+		return types.WrapErr(err)
+	} else if detail, err := anypb.New(detail); err != nil {
+		return types.WrapErr(err)
+	} else {
+		st.Details = append(st.Details, detail)
+		return c.NativeToValue(st)
+	}
+}
+
+func (c *impl) response_grpc_status(st ref.Val) ref.Val {
+	if st, err := utils.ConvertToNative[*status.Status](st); err != nil {
+		return types.WrapErr(err)
+	} else if response, err := grpcDeniedResponse(st); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(response)
+	}
+}
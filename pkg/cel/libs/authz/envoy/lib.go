@@ -5,6 +5,7 @@ import (
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	status "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -18,6 +19,8 @@ var (
 	Metadata           = types.NewObjectType("google.protobuf.Struct")
 	OkHttpResponse     = types.NewObjectType("envoy.service.auth.v3.OkHttpResponse")
 	QueryParameter     = types.NewObjectType("envoy.config.core.v3.QueryParameter")
+	// grpc status type
+	Status = types.NewObjectType("google.rpc.Status")
 )
 
 type lib struct{}
@@ -41,6 +44,19 @@ func (c *lib) CompileOptions() []cel.EnvOption {
 			(*status.Status)(nil),
 			(*structpb.Struct)(nil),
 		),
+		// register grpc error details messages
+		cel.Types(
+			(*errdetails.BadRequest)(nil),
+			(*errdetails.DebugInfo)(nil),
+			(*errdetails.ErrorInfo)(nil),
+			(*errdetails.Help)(nil),
+			(*errdetails.LocalizedMessage)(nil),
+			(*errdetails.PreconditionFailure)(nil),
+			(*errdetails.QuotaFailure)(nil),
+			(*errdetails.RequestInfo)(nil),
+			(*errdetails.ResourceInfo)(nil),
+			(*errdetails.RetryInfo)(nil),
+		),
 		// extend environment with function overloads
 		c.extendEnv,
 	}
@@ -63,6 +79,9 @@ func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		"envoy.Denied": {
 			cel.Overload("denied", []*cel.Type{types.IntType}, DeniedHttpResponse, cel.UnaryBinding(impl.denied)),
 		},
+		"envoy.GrpcDenied": {
+			cel.Overload("grpc_denied", []*cel.Type{types.IntType}, Status, cel.UnaryBinding(impl.grpc_denied)),
+		},
 		"envoy.Header": {
 			cel.Overload("header_key_value", []*cel.Type{types.StringType, types.StringType}, HeaderValueOption, cel.BinaryBinding(impl.header_key_value)),
 		},
@@ -99,9 +118,14 @@ func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		"Response": {
 			cel.MemberOverload("ok_response", []*cel.Type{OkHttpResponse}, CheckResponse, cel.UnaryBinding(impl.response_ok)),
 			cel.MemberOverload("denied_response", []*cel.Type{DeniedHttpResponse}, CheckResponse, cel.UnaryBinding(impl.response_denied)),
+			cel.MemberOverload("grpc_status_response", []*cel.Type{Status}, CheckResponse, cel.UnaryBinding(impl.response_grpc_status)),
 		},
 		"WithMessage": {
 			cel.MemberOverload("response_ok_with_message", []*cel.Type{CheckResponse, types.StringType}, CheckResponse, cel.BinaryBinding(impl.response_with_message)),
+			cel.MemberOverload("grpc_status_with_message", []*cel.Type{Status, types.StringType}, Status, cel.BinaryBinding(impl.grpc_status_with_message)),
+		},
+		"WithDetail": {
+			cel.MemberOverload("grpc_status_with_detail", []*cel.Type{Status, types.DynType}, Status, cel.BinaryBinding(impl.grpc_status_with_detail)),
 		},
 		"WithMetadata": {
 			cel.MemberOverload("response_ok_with_metadata", []*cel.Type{CheckResponse, Metadata}, CheckResponse, cel.BinaryBinding(impl.response_with_metadata)),
@@ -1,6 +1,7 @@
 package envoy_test
 
 import (
+	"encoding/base64"
 	"reflect"
 	"testing"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/google/cel-go/interpreter"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/envoy"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	status "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -114,3 +118,114 @@ func TestOkResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestGrpcDeniedResponse(t *testing.T) {
+	errorInfo, err := anypb.New(&errdetails.ErrorInfo{Reason: "TENANT_MISMATCH", Domain: "kyverno.io", Metadata: map[string]string{"tenant": "acme"}})
+	assert.NoError(t, err)
+	quotaFailure, err := anypb.New(&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "tenant:acme", Description: "daily limit"}}})
+	assert.NoError(t, err)
+	tests := []struct {
+		name        string
+		source      string
+		wantStatus  *status.Status
+		wantHttp    int32
+		wantHeaders map[string]string
+		wantErr     bool
+	}{{
+		name:       "code only",
+		source:     `envoy.GrpcDenied(7).Response()`,
+		wantStatus: &status.Status{Code: 7},
+		wantHttp:   403,
+		wantHeaders: map[string]string{
+			"content-type": "application/grpc",
+			"grpc-status":  "7",
+		},
+	}, {
+		name:       "with message",
+		source:     `envoy.GrpcDenied(16).WithMessage("token expired: 100%").Response()`,
+		wantStatus: &status.Status{Code: 16, Message: "token expired: 100%"},
+		wantHttp:   401,
+		wantHeaders: map[string]string{
+			"content-type": "application/grpc",
+			"grpc-status":  "16",
+			"grpc-message": "token expired: 100%25",
+		},
+	}, {
+		name: "with details",
+		source: `
+		envoy
+			.GrpcDenied(8)
+			.WithDetail(google.rpc.ErrorInfo{reason: "TENANT_MISMATCH", domain: "kyverno.io", metadata: {"tenant": "acme"}})
+			.WithDetail(google.rpc.QuotaFailure{violations: [google.rpc.QuotaFailure.Violation{subject: "tenant:acme", description: "daily limit"}]})
+			.Response()
+		`,
+		wantStatus: &status.Status{Code: 8, Details: []*anypb.Any{errorInfo, quotaFailure}},
+		wantHttp:   429,
+	}, {
+		name:    "ok code",
+		source:  `envoy.GrpcDenied(0).Response()`,
+		wantErr: true,
+	}, {
+		name:    "invalid code",
+		source:  `envoy.GrpcDenied(42).Response()`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(envoy.Lib())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.Nil(t, issues)
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(interpreter.EmptyActivation())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*authv3.CheckResponse]())
+			assert.NoError(t, err)
+			response := got.(*authv3.CheckResponse)
+			assertStatus(t, tt.wantStatus, response.Status)
+			denied := response.GetDeniedResponse()
+			assert.NotNil(t, denied)
+			assert.Equal(t, tt.wantHttp, int32(denied.Status.Code))
+			headers := map[string]string{}
+			for _, header := range denied.Headers {
+				assert.Equal(t, corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD, header.AppendAction)
+				headers[header.Header.Key] = header.Header.Value
+			}
+			if details, ok := headers["grpc-status-details-bin"]; ok {
+				decoded, err := base64.RawStdEncoding.DecodeString(details)
+				assert.NoError(t, err)
+				var st status.Status
+				assert.NoError(t, proto.Unmarshal(decoded, &st))
+				assertStatus(t, tt.wantStatus, &st)
+				delete(headers, "grpc-status-details-bin")
+			} else {
+				assert.Empty(t, tt.wantStatus.Details)
+			}
+			if tt.wantHeaders != nil {
+				assert.Equal(t, tt.wantHeaders, headers)
+			}
+		})
+	}
+}
+
+// assertStatus compares the details unpacked, the fields of a message built in cel are not always encoded in the same order
+func assertStatus(t *testing.T, want, got *status.Status) {
+	t.Helper()
+	assert.Equal(t, want.GetCode(), got.GetCode())
+	assert.Equal(t, want.GetMessage(), got.GetMessage())
+	if !assert.Len(t, got.GetDetails(), len(want.GetDetails())) {
+		return
+	}
+	for i, detail := range want.GetDetails() {
+		wantDetail, err := detail.UnmarshalNew()
+		assert.NoError(t, err)
+		gotDetail, err := got.GetDetails()[i].UnmarshalNew()
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(wantDetail, gotDetail))
+	}
+}
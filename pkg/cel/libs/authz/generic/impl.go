@@ -0,0 +1,63 @@
+package generic
+
+import (
+	"maps"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) allowed() ref.Val {
+	r := &CheckResponse{
+		Allowed: true,
+	}
+	return c.NativeToValue(r)
+}
+
+func (c *impl) denied(reason ref.Val) ref.Val {
+	if reason, err := utils.ConvertToNative[string](reason); err != nil {
+		return types.WrapErr(err)
+	} else {
+		r := &CheckResponse{
+			Reason: reason,
+		}
+		return c.NativeToValue(r)
+	}
+}
+
+func (c *impl) response_with_reason(response ref.Val, reason ref.Val) ref.Val {
+	if response, err := utils.ConvertToNative[*CheckResponse](response); err != nil {
+		return types.WrapErr(err)
+	} else if reason, err := utils.ConvertToNative[string](reason); err != nil {
+		return types.WrapErr(err)
+	} else {
+		r := *response
+		r.Reason = reason
+		return c.NativeToValue(&r)
+	}
+}
+
+func (c *impl) response_with_obligation(values ...ref.Val) ref.Val {
+	if response, err := utils.ConvertToNative[*CheckResponse](values[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](values[1]); err != nil {
+		return types.WrapErr(err)
+	} else if value, err := utils.ConvertToNative[*structpb.Value](values[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		// cel values must not be mutated in place, obligations are stored as json compatible values
+		r := *response
+		r.Obligations = maps.Clone(r.Obligations)
+		if r.Obligations == nil {
+			r.Obligations = map[string]any{}
+		}
+		r.Obligations[key] = value.AsInterface()
+		return c.NativeToValue(&r)
+	}
+}
@@ -0,0 +1,66 @@
+package generic_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *generic.CheckResponse
+	}{{
+		name:   "allowed",
+		source: `generic.Allowed()`,
+		want:   &generic.CheckResponse{Allowed: true},
+	}, {
+		name:   "denied",
+		source: `generic.Denied("queue not allowed")`,
+		want:   &generic.CheckResponse{Reason: "queue not allowed"},
+	}, {
+		name:   "with reason",
+		source: `generic.Allowed().WithReason("owner")`,
+		want:   &generic.CheckResponse{Allowed: true, Reason: "owner"},
+	}, {
+		name: "with obligations",
+		source: `
+		generic
+			.Allowed()
+			.WithObligation("log", true)
+			.WithObligation("mask", ["ssn", "email"])
+			.WithObligation("ttl", 30)
+			.WithObligation("owner", object.owner)
+		`,
+		want: &generic.CheckResponse{
+			Allowed: true,
+			Obligations: map[string]any{
+				"log":   true,
+				"mask":  []any{"ssn", "email"},
+				"ttl":   float64(30),
+				"owner": map[string]any{"name": "alice"},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(generic.Lib(), cel.Variable("object", cel.DynType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{
+				"object": map[string]any{"owner": map[string]any{"name": "alice"}},
+			})
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*generic.CheckResponse]())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package generic
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	ResponseType = types.NewObjectType("generic.CheckResponse")
+)
+
+// CheckResponse is the decision returned by policies evaluating arbitrary json payloads,
+// obligations are opaque to the authz server and returned as is to the caller
+type CheckResponse struct {
+	Allowed bool   `json:"allowed"          cel:"allowed"`
+	Reason  string `json:"reason,omitempty" cel:"reason"`
+	// obligations hold json values, they can't be declared as a cel field and are set with WithObligation
+	Obligations map[string]any `json:"obligations,omitempty"`
+}
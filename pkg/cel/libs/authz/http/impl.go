@@ -1,7 +1,10 @@
 package http
 
 import (
+	"fmt"
+	"maps"
 	"net/textproto"
+	"slices"
 
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
@@ -17,6 +20,80 @@ func (c *impl) allowed() ref.Val {
 	return c.NativeToValue(r)
 }
 
+func (c *impl) ok_with_header_string_string(values ...ref.Val) ref.Val {
+	if ok, err := utils.ConvertToNative[CheckResponseOk](values[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](values[1]); err != nil {
+		return types.WrapErr(err)
+	} else if value, err := utils.ConvertToNative[string](values[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		ok.Header = withValue(ok.Header, key, value)
+		ok.HeadersToRemove = without(ok.HeadersToRemove, key)
+		return c.NativeToValue(ok)
+	}
+}
+
+func (c *impl) ok_without_header_string(ok ref.Val, key ref.Val) ref.Val {
+	if ok, err := utils.ConvertToNative[CheckResponseOk](ok); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](key); err != nil {
+		return types.WrapErr(err)
+	} else {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		ok.Header = withoutKey(ok.Header, key)
+		ok.HeadersToRemove = append(without(ok.HeadersToRemove, key), key)
+		return c.NativeToValue(ok)
+	}
+}
+
+func (c *impl) ok_with_queryparam_string_string(values ...ref.Val) ref.Val {
+	if ok, err := utils.ConvertToNative[CheckResponseOk](values[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](values[1]); err != nil {
+		return types.WrapErr(err)
+	} else if value, err := utils.ConvertToNative[string](values[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		ok.Query = withValue(ok.Query, key, value)
+		ok.QueryParamsToRemove = without(ok.QueryParamsToRemove, key)
+		return c.NativeToValue(ok)
+	}
+}
+
+func (c *impl) ok_without_queryparam_string(ok ref.Val, key ref.Val) ref.Val {
+	if ok, err := utils.ConvertToNative[CheckResponseOk](ok); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](key); err != nil {
+		return types.WrapErr(err)
+	} else {
+		ok.Query = withoutKey(ok.Query, key)
+		ok.QueryParamsToRemove = append(without(ok.QueryParamsToRemove, key), key)
+		return c.NativeToValue(ok)
+	}
+}
+
+// withValue returns a copy of values with value appended to key, cel values must not be mutated in place
+func withValue(values map[string][]string, key, value string) map[string][]string {
+	out := maps.Clone(values)
+	if out == nil {
+		out = map[string][]string{}
+	}
+	out[key] = append(slices.Clone(out[key]), value)
+	return out
+}
+
+func withoutKey(values map[string][]string, key string) map[string][]string {
+	out := maps.Clone(values)
+	delete(out, key)
+	return out
+}
+
+func without(keys []string, key string) []string {
+	return slices.DeleteFunc(slices.Clone(keys), func(k string) bool { return k == key })
+}
+
 func (c *impl) denied(reason ref.Val) ref.Val {
 	if reason, err := utils.ConvertToNative[string](reason); err != nil {
 		return types.WrapErr(err)
@@ -28,6 +105,65 @@ func (c *impl) denied(reason ref.Val) ref.Val {
 	}
 }
 
+func (c *impl) denied_int(status ref.Val) ref.Val {
+	if status, err := utils.ConvertToNative[int](status); err != nil {
+		return types.WrapErr(err)
+	} else if status < 300 || status > 599 {
+		return types.WrapErr(fmt.Errorf("invalid denied status %d, expected a redirection, client or server error status", status))
+	} else {
+		r := CheckResponseDenied{
+			Status: status,
+		}
+		return c.NativeToValue(r)
+	}
+}
+
+func (c *impl) denied_with_header_string_string(values ...ref.Val) ref.Val {
+	if denied, err := utils.ConvertToNative[CheckResponseDenied](values[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](values[1]); err != nil {
+		return types.WrapErr(err)
+	} else if value, err := utils.ConvertToNative[string](values[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		denied.Header = withValue(denied.Header, textproto.CanonicalMIMEHeaderKey(key), value)
+		return c.NativeToValue(denied)
+	}
+}
+
+func (c *impl) denied_with_body_string(denied ref.Val, body ref.Val) ref.Val {
+	if denied, err := utils.ConvertToNative[CheckResponseDenied](denied); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := utils.ConvertToNative[string](body); err != nil {
+		return types.WrapErr(err)
+	} else {
+		denied.Body = []byte(body)
+		return c.NativeToValue(denied)
+	}
+}
+
+func (c *impl) denied_with_body_bytes(denied ref.Val, body ref.Val) ref.Val {
+	if denied, err := utils.ConvertToNative[CheckResponseDenied](denied); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := utils.ConvertToNative[[]byte](body); err != nil {
+		return types.WrapErr(err)
+	} else {
+		denied.Body = body
+		return c.NativeToValue(denied)
+	}
+}
+
+func (c *impl) denied_with_reason_string(denied ref.Val, reason ref.Val) ref.Val {
+	if denied, err := utils.ConvertToNative[CheckResponseDenied](denied); err != nil {
+		return types.WrapErr(err)
+	} else if reason, err := utils.ConvertToNative[string](reason); err != nil {
+		return types.WrapErr(err)
+	} else {
+		denied.Reason = reason
+		return c.NativeToValue(denied)
+	}
+}
+
 func (c *impl) get_header(request ref.Val, key ref.Val) ref.Val {
 	if request, err := utils.ConvertToNative[CheckRequestAttributes](request); err != nil {
 		return types.WrapErr(err)
@@ -48,6 +48,27 @@ func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		},
 		"http.Denied": {
 			cel.Overload("http_denied_string", []*cel.Type{cel.StringType}, ResponseDeniedType, cel.UnaryBinding(impl.denied)),
+			cel.Overload("http_denied_int", []*cel.Type{cel.IntType}, ResponseDeniedType, cel.UnaryBinding(impl.denied_int)),
+		},
+		"WithHeader": {
+			cel.MemberOverload("http_ok_with_header_string_string", []*cel.Type{ResponseOkType, cel.StringType, cel.StringType}, ResponseOkType, cel.FunctionBinding(impl.ok_with_header_string_string)),
+			cel.MemberOverload("http_denied_with_header_string_string", []*cel.Type{ResponseDeniedType, cel.StringType, cel.StringType}, ResponseDeniedType, cel.FunctionBinding(impl.denied_with_header_string_string)),
+		},
+		"WithBody": {
+			cel.MemberOverload("http_denied_with_body_string", []*cel.Type{ResponseDeniedType, cel.StringType}, ResponseDeniedType, cel.BinaryBinding(impl.denied_with_body_string)),
+			cel.MemberOverload("http_denied_with_body_bytes", []*cel.Type{ResponseDeniedType, cel.BytesType}, ResponseDeniedType, cel.BinaryBinding(impl.denied_with_body_bytes)),
+		},
+		"WithReason": {
+			cel.MemberOverload("http_denied_with_reason_string", []*cel.Type{ResponseDeniedType, cel.StringType}, ResponseDeniedType, cel.BinaryBinding(impl.denied_with_reason_string)),
+		},
+		"WithoutHeader": {
+			cel.MemberOverload("http_ok_without_header_string", []*cel.Type{ResponseOkType, cel.StringType}, ResponseOkType, cel.BinaryBinding(impl.ok_without_header_string)),
+		},
+		"WithQueryParam": {
+			cel.MemberOverload("http_ok_with_queryparam_string_string", []*cel.Type{ResponseOkType, cel.StringType, cel.StringType}, ResponseOkType, cel.FunctionBinding(impl.ok_with_queryparam_string_string)),
+		},
+		"WithoutQueryParam": {
+			cel.MemberOverload("http_ok_without_queryparam_string", []*cel.Type{ResponseOkType, cel.StringType}, ResponseOkType, cel.BinaryBinding(impl.ok_without_queryparam_string)),
 		},
 		"Header": {
 			cel.MemberOverload("http_get_header_string", []*cel.Type{RequestAttributesType, cel.StringType}, types.NewListType(cel.StringType), cel.BinaryBinding(impl.get_header)),
@@ -0,0 +1,237 @@
+package http_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/interpreter"
+	httpcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/body"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOkResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *httpcel.CheckResponse
+	}{{
+		name:   "empty",
+		source: `http.Allowed().Response()`,
+		want: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{},
+		},
+	}, {
+		name: "with headers",
+		source: `
+		http
+			.Allowed()
+			.WithHeader("x-user-id", "alice")
+			.WithHeader("X-Groups", "dev")
+			.WithHeader("x-groups", "ops")
+			.WithoutHeader("cookie")
+			.Response()
+		`,
+		want: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Header: map[string][]string{
+					"X-User-Id": {"alice"},
+					"X-Groups":  {"dev", "ops"},
+				},
+				HeadersToRemove: []string{"Cookie"},
+			},
+		},
+	}, {
+		name:   "last mutation wins",
+		source: `http.Allowed().WithHeader("foo", "bar").WithoutHeader("foo").WithoutHeader("baz").WithHeader("baz", "qux").Response()`,
+		want: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Header:          map[string][]string{"Baz": {"qux"}},
+				HeadersToRemove: []string{"Foo"},
+			},
+		},
+	}, {
+		name:   "with query params",
+		source: `http.Allowed().WithQueryParam("tenant", "acme").WithoutQueryParam("token").Response()`,
+		want: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Query:               map[string][]string{"tenant": {"acme"}},
+				QueryParamsToRemove: []string{"token"},
+			},
+		},
+	}, {
+		name:   "builders don't mutate shared values",
+		source: `cel.bind(ok, http.Allowed().WithHeader("foo", "bar"), [ok.WithHeader("foo", "baz"), ok][1]).Response()`,
+		want: &httpcel.CheckResponse{
+			Ok: &httpcel.CheckResponseOk{
+				Header: map[string][]string{"Foo": {"bar"}},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(httpcel.Lib(), ext.Bindings())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.Nil(t, issues)
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(interpreter.EmptyActivation())
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*httpcel.CheckResponse]())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeniedResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    *httpcel.CheckResponse
+		wantErr bool
+	}{{
+		name:   "reason",
+		source: `http.Denied("Forbidden").Response()`,
+		want: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{Reason: "Forbidden"},
+		},
+	}, {
+		name:   "unauthorized",
+		source: `http.Denied(401).WithHeader("www-authenticate", "Bearer realm=\"api\"").WithReason("Unauthorized").Response()`,
+		want: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Reason: "Unauthorized",
+				Status: 401,
+				Header: map[string][]string{"Www-Authenticate": {`Bearer realm="api"`}},
+			},
+		},
+	}, {
+		name:   "redirect",
+		source: `http.Denied(302).WithHeader("location", "https://login.example.com").Response()`,
+		want: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Status: 302,
+				Header: map[string][]string{"Location": {"https://login.example.com"}},
+			},
+		},
+	}, {
+		name:   "with body",
+		source: `http.Denied(429).WithBody("slow down").Response()`,
+		want: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Status: 429,
+				Body:   []byte("slow down"),
+			},
+		},
+	}, {
+		name:   "with bytes body",
+		source: `http.Denied(503).WithBody(b"unavailable").Response()`,
+		want: &httpcel.CheckResponse{
+			Denied: &httpcel.CheckResponseDenied{
+				Status: 503,
+				Body:   []byte("unavailable"),
+			},
+		},
+	}, {
+		name:    "invalid status",
+		source:  `http.Denied(200).Response()`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(httpcel.Lib(), ext.Bindings())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.Nil(t, issues)
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(interpreter.EmptyActivation())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*httpcel.CheckResponse]())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	assert.NoError(t, writer.WriteField("tenant", "acme"))
+	file, err := writer.CreateFormFile("upload", "report.csv")
+	assert.NoError(t, err)
+	_, err = file.Write(bytes.Repeat([]byte("a"), 1<<16))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	size := int64(buf.Len())
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantBody    []byte
+		wantParts   int
+		wantLength  int64
+		wantErr     bool
+	}{{
+		name:        "json",
+		body:        `{"a":1}`,
+		contentType: "application/json",
+		wantBody:    []byte(`{"a":1}`),
+		wantLength:  7,
+	}, {
+		name:        "multipart",
+		body:        buf.String(),
+		contentType: writer.FormDataContentType(),
+		wantParts:   2,
+		wantLength:  size,
+	}, {
+		name:        "truncated multipart",
+		body:        "--" + writer.Boundary() + "\r\nContent-Disposition: form-data; name=\"tenant\"\r\n\r\nacme",
+		contentType: writer.FormDataContentType(),
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/upload?a=b", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			got, err := httpcel.NewRequest(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBody, got.Attributes.Body)
+			assert.Len(t, got.Attributes.Parts, tt.wantParts)
+			assert.Equal(t, tt.wantLength, got.Attributes.ContentLength)
+			assert.Equal(t, "/upload", got.Attributes.Path)
+		})
+	}
+	// file contents are never retained
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(buf.Bytes()))
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	got, err := httpcel.NewRequest(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []body.Part{
+		{Name: "tenant", Size: 4, Value: "acme"},
+		{Name: "upload", Filename: "report.csv", ContentType: "application/octet-stream", Size: 1 << 16},
+	}, got.Attributes.Parts)
+	// buffered requests keep the body
+	r = httptest.NewRequest("POST", "/", bytes.NewReader(buf.Bytes()))
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	got, err = httpcel.NewBufferedRequest(r)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), got.Attributes.Body)
+	assert.Nil(t, got.Attributes.Parts)
+}
@@ -2,9 +2,11 @@ package http
 
 import (
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/google/cel-go/common/types"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/body"
 )
 
 var (
@@ -35,6 +37,8 @@ type CheckRequestAttributes struct {
 	Path          string `json:"path"          cel:"path"`
 	Query         query  `json:"query"         cel:"query"`
 	Fragment      string `json:"fragment"      cel:"fragment"`
+	// Parts is set instead of Body for multipart form bodies, see NewRequest
+	Parts []body.Part `json:"parts,omitempty" cel:"parts"`
 }
 
 type CheckResponse struct {
@@ -42,17 +46,55 @@ type CheckResponse struct {
 	Denied *CheckResponseDenied `json:"denied,omitempty" cel:"denied"`
 }
 
-type CheckResponseOk struct{}
+// CheckResponseOk holds the mutations applied to the request before it is sent upstream
+type CheckResponseOk struct {
+	Header              header   `json:"header,omitempty"              cel:"header"`
+	HeadersToRemove     []string `json:"headersToRemove,omitempty"     cel:"headersToRemove"`
+	Query               query    `json:"query,omitempty"               cel:"query"`
+	QueryParamsToRemove []string `json:"queryParamsToRemove,omitempty" cel:"queryParamsToRemove"`
+}
 
+// CheckResponseDenied holds the response sent back to the client, status, header and body are
+// optional and output expressions are expected to provide defaults when they are not set
 type CheckResponseDenied struct {
-	Reason string `cel:"reason"`
+	Reason string `json:"reason"           cel:"reason"`
+	Status int    `json:"status,omitempty" cel:"status"`
+	Header header `json:"header,omitempty" cel:"header"`
+	Body   []byte `json:"body,omitempty"   cel:"body"`
 }
 
+// NewRequest builds a request from r, multipart form bodies are streamed to collect their parts
+// and file contents are never held in memory, other bodies are read entirely.
+// Body is left empty for multipart form bodies, policies read Parts instead.
 func NewRequest(r *http.Request) (CheckRequest, error) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" {
+		counter := &countingReader{reader: r.Body}
+		parts, err := body.ReadMultipart(counter, r.Header.Get("Content-Type"))
+		if err != nil {
+			return CheckRequest{}, err
+		}
+		// drain the epilogue so that the content length is accurate
+		if _, err := io.Copy(io.Discard, counter); err != nil {
+			return CheckRequest{}, err
+		}
+		request := newRequest(r, nil)
+		request.Attributes.Parts = parts
+		request.Attributes.ContentLength = counter.count
+		return request, nil
+	}
+	return NewBufferedRequest(r)
+}
+
+// NewBufferedRequest builds a request from r, the whole body is read whatever its content type
+func NewBufferedRequest(r *http.Request) (CheckRequest, error) {
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
 		return CheckRequest{}, err
 	}
+	return newRequest(r, bodyBytes), nil
+}
+
+func newRequest(r *http.Request, bodyBytes []byte) CheckRequest {
 	return CheckRequest{
 		Attributes: CheckRequestAttributes{
 			Method:        r.Method,
@@ -66,5 +108,16 @@ func NewRequest(r *http.Request) (CheckRequest, error) {
 			Fragment:      r.URL.Fragment,
 			Scheme:        r.URL.Scheme,
 		},
-	}, nil
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
 }
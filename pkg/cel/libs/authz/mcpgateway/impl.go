@@ -0,0 +1,41 @@
+package mcpgateway
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) allowed() ref.Val {
+	r := &CheckResponse{
+		Allowed: true,
+	}
+	return c.NativeToValue(r)
+}
+
+func (c *impl) denied(reason ref.Val) ref.Val {
+	if reason, err := utils.ConvertToNative[string](reason); err != nil {
+		return types.WrapErr(err)
+	} else {
+		r := &CheckResponse{
+			Reason: reason,
+		}
+		return c.NativeToValue(r)
+	}
+}
+
+func (c *impl) response_with_reason(response ref.Val, reason ref.Val) ref.Val {
+	if response, err := utils.ConvertToNative[*CheckResponse](response); err != nil {
+		return types.WrapErr(err)
+	} else if reason, err := utils.ConvertToNative[string](reason); err != nil {
+		return types.WrapErr(err)
+	} else {
+		r := *response
+		r.Reason = reason
+		return c.NativeToValue(&r)
+	}
+}
@@ -0,0 +1,62 @@
+package mcpgateway
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.authz.mcpgateway"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register types
+		ext.NativeTypes(
+			reflect.TypeFor[CheckRequest](),
+			reflect.TypeFor[CheckResponse](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	impl := impl{
+		Adapter: env.CELTypeAdapter(),
+	}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"mcpgateway.Allowed": {
+			cel.Overload("mcpgateway_allowed", []*cel.Type{}, ResponseType, cel.FunctionBinding(func(values ...ref.Val) ref.Val { return impl.allowed() })),
+		},
+		"mcpgateway.Denied": {
+			cel.Overload("mcpgateway_denied_string", []*cel.Type{cel.StringType}, ResponseType, cel.UnaryBinding(impl.denied)),
+		},
+		"WithReason": {
+			cel.MemberOverload("mcpgateway_response_with_reason_string", []*cel.Type{ResponseType, cel.StringType}, ResponseType, cel.BinaryBinding(impl.response_with_reason)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,102 @@
+package mcpgateway_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest(t *testing.T) {
+	session := &mcpgateway.Session{
+		ID:                 "abc",
+		ProtocolVersion:    "2025-06-18",
+		ClientInfo:         mcpgateway.Implementation{Name: "claude-desktop", Version: "1.0.0"},
+		ClientCapabilities: []string{"roots", "sampling"},
+		ServerInfo:         mcpgateway.Implementation{Name: "github", Version: "0.4.0"},
+		ServerCapabilities: []string{"tools"},
+		CreatedAt:          time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name   string
+		source string
+		object mcpgateway.CheckRequest
+		want   any
+	}{{
+		name:   "method",
+		source: `object.httpMethod == "POST" && object.method == "tools/call"`,
+		object: mcpgateway.CheckRequest{HttpMethod: "POST", Method: "tools/call"},
+		want:   true,
+	}, {
+		name:   "no session",
+		source: `has(object.session)`,
+		object: mcpgateway.CheckRequest{HttpMethod: "POST", Method: "initialize"},
+		want:   false,
+	}, {
+		name:   "client info",
+		source: `has(object.session) && object.session.clientInfo.name == "claude-desktop"`,
+		object: mcpgateway.CheckRequest{Session: session},
+		want:   true,
+	}, {
+		name:   "capabilities",
+		source: `"sampling" in object.session.clientCapabilities && !("prompts" in object.session.serverCapabilities)`,
+		object: mcpgateway.CheckRequest{Session: session},
+		want:   true,
+	}, {
+		name:   "created at",
+		source: `object.session.createdAt < timestamp("2025-06-01T00:00:00Z")`,
+		object: mcpgateway.CheckRequest{Session: session},
+		want:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(mcpgateway.Lib(), cel.Variable("object", mcpgateway.RequestType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"object": &tt.object})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.Value())
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *mcpgateway.CheckResponse
+	}{{
+		name:   "allowed",
+		source: `mcpgateway.Allowed()`,
+		want:   &mcpgateway.CheckResponse{Allowed: true},
+	}, {
+		name:   "denied",
+		source: `mcpgateway.Denied("client not allowed")`,
+		want:   &mcpgateway.CheckResponse{Reason: "client not allowed"},
+	}, {
+		name:   "with reason",
+		source: `mcpgateway.Allowed().WithReason("trusted client")`,
+		want:   &mcpgateway.CheckResponse{Allowed: true, Reason: "trusted client"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(mcpgateway.Lib())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(cel.NoVars())
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*mcpgateway.CheckResponse]())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package mcpgateway
+
+import (
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	RequestType        = types.NewObjectType("mcpgateway.CheckRequest")
+	SessionType        = types.NewObjectType("mcpgateway.Session")
+	ImplementationType = types.NewObjectType("mcpgateway.Implementation")
+	ResponseType       = types.NewObjectType("mcpgateway.CheckResponse")
+)
+
+type header = map[string][]string
+
+// CheckRequest describes a message sent by a client to the gateway, the json-rpc method and body are
+// empty for requests that don't carry a message (opening a sse stream or terminating a session)
+type CheckRequest struct {
+	HttpMethod string   `json:"httpMethod"        cel:"httpMethod"`
+	Path       string   `json:"path"              cel:"path"`
+	Header     header   `json:"header"            cel:"header"`
+	Method     string   `json:"method"            cel:"method"`
+	Body       string   `json:"body"              cel:"body"`
+	Session    *Session `json:"session,omitempty" cel:"session"`
+}
+
+// Session holds what the gateway learned about a session when it was initialized
+type Session struct {
+	ID                 string         `json:"id"                 cel:"id"`
+	Principal          string         `json:"principal"          cel:"principal"`
+	ProtocolVersion    string         `json:"protocolVersion"    cel:"protocolVersion"`
+	ClientInfo         Implementation `json:"clientInfo"         cel:"clientInfo"`
+	ClientCapabilities []string       `json:"clientCapabilities" cel:"clientCapabilities"`
+	ServerInfo         Implementation `json:"serverInfo"         cel:"serverInfo"`
+	ServerCapabilities []string       `json:"serverCapabilities" cel:"serverCapabilities"`
+	CreatedAt          time.Time      `json:"createdAt"          cel:"createdAt"`
+}
+
+type Implementation struct {
+	Name    string `json:"name"    cel:"name"`
+	Version string `json:"version" cel:"version"`
+}
+
+type CheckResponse struct {
+	Allowed bool   `json:"allowed"          cel:"allowed"`
+	Reason  string `json:"reason,omitempty" cel:"reason"`
+}
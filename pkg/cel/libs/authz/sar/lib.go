@@ -0,0 +1,65 @@
+package sar
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.authz.sar"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register types
+		ext.NativeTypes(
+			reflect.TypeFor[CheckRequest](),
+			reflect.TypeFor[CheckResponse](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	impl := impl{
+		Adapter: env.CELTypeAdapter(),
+	}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"sar.Allowed": {
+			cel.Overload("sar_allowed", []*cel.Type{}, ResponseType, cel.FunctionBinding(func(values ...ref.Val) ref.Val { return impl.allowed() })),
+		},
+		"sar.Denied": {
+			cel.Overload("sar_denied_string", []*cel.Type{cel.StringType}, ResponseType, cel.UnaryBinding(impl.denied)),
+		},
+		"sar.NoOpinion": {
+			cel.Overload("sar_no_opinion", []*cel.Type{}, ResponseType, cel.FunctionBinding(func(values ...ref.Val) ref.Val { return impl.no_opinion() })),
+		},
+		"WithReason": {
+			cel.MemberOverload("sar_response_with_reason_string", []*cel.Type{ResponseType, cel.StringType}, ResponseType, cel.BinaryBinding(impl.response_with_reason)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,123 @@
+package sar_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRequest(t *testing.T) {
+	resource := sar.NewRequest(authorizationv1.SubjectAccessReviewSpec{
+		User:   "alice",
+		Groups: []string{"system:authenticated", "dev"},
+		Extra:  map[string]authorizationv1.ExtraValue{"scopes": {"read"}},
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "list",
+			Resource:  "pods",
+			LabelSelector: &authorizationv1.LabelSelectorAttributes{
+				Requirements: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev"}}},
+			},
+			FieldSelector: &authorizationv1.FieldSelectorAttributes{
+				Requirements: []metav1.FieldSelectorRequirement{{Key: "spec.nodeName", Operator: metav1.FieldSelectorOpIn, Values: []string{"node-1"}}},
+			},
+		},
+	})
+	nonResource := sar.NewRequest(authorizationv1.SubjectAccessReviewSpec{
+		User:                  "bob",
+		NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: "/healthz", Verb: "get"},
+	})
+	tests := []struct {
+		name   string
+		source string
+		object sar.CheckRequest
+		want   any
+	}{{
+		name:   "user and groups",
+		source: `object.user == "alice" && "dev" in object.groups && object.extra["scopes"] == ["read"]`,
+		object: resource,
+		want:   true,
+	}, {
+		name:   "resource attributes",
+		source: `has(object.resourceAttributes) && !has(object.nonResourceAttributes) && object.resourceAttributes.resource == "pods"`,
+		object: resource,
+		want:   true,
+	}, {
+		name:   "label selector",
+		source: `object.resourceAttributes.labelSelector.requirements.exists(r, r.key == "team" && r.operator == "In" && "dev" in r.values)`,
+		object: resource,
+		want:   true,
+	}, {
+		name:   "field selector",
+		source: `object.resourceAttributes.fieldSelector.requirements[0].values[0]`,
+		object: resource,
+		want:   "node-1",
+	}, {
+		name:   "non resource attributes",
+		source: `!has(object.resourceAttributes) && object.nonResourceAttributes.path == "/healthz"`,
+		object: nonResource,
+		want:   true,
+	}, {
+		name:   "missing selector",
+		source: `has(object.resourceAttributes.fieldSelector)`,
+		object: sar.NewRequest(authorizationv1.SubjectAccessReviewSpec{ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "get"}}),
+		want:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(sar.Lib(), cel.Variable("object", sar.RequestType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"object": &tt.object})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.Value())
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *sar.CheckResponse
+	}{{
+		name:   "allowed",
+		source: `sar.Allowed()`,
+		want:   &sar.CheckResponse{Allowed: true},
+	}, {
+		name:   "denied",
+		source: `sar.Denied("secrets are off limits")`,
+		want:   &sar.CheckResponse{Denied: true, Reason: "secrets are off limits"},
+	}, {
+		name:   "no opinion",
+		source: `sar.NoOpinion()`,
+		want:   &sar.CheckResponse{},
+	}, {
+		name:   "with reason",
+		source: `sar.Allowed().WithReason("owner of the namespace")`,
+		want:   &sar.CheckResponse{Allowed: true, Reason: "owner of the namespace"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(sar.Lib())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(cel.NoVars())
+			assert.NoError(t, err)
+			got, err := out.ConvertToNative(reflect.TypeFor[*sar.CheckResponse]())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
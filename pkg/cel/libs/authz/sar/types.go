@@ -0,0 +1,117 @@
+package sar
+
+import (
+	"github.com/google/cel-go/common/types"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+var (
+	RequestType               = types.NewObjectType("sar.CheckRequest")
+	ResourceAttributesType    = types.NewObjectType("sar.ResourceAttributes")
+	NonResourceAttributesType = types.NewObjectType("sar.NonResourceAttributes")
+	SelectorAttributesType    = types.NewObjectType("sar.SelectorAttributes")
+	SelectorRequirementType   = types.NewObjectType("sar.SelectorRequirement")
+	ResponseType              = types.NewObjectType("sar.CheckResponse")
+)
+
+// CheckRequest is the spec of the SubjectAccessReview sent by the kube-apiserver,
+// exactly one of resource attributes or non resource attributes is set
+type CheckRequest struct {
+	User                  string                 `json:"user"                            cel:"user"`
+	Groups                []string               `json:"groups"                          cel:"groups"`
+	UID                   string                 `json:"uid"                             cel:"uid"`
+	Extra                 map[string][]string    `json:"extra"                           cel:"extra"`
+	ResourceAttributes    *ResourceAttributes    `json:"resourceAttributes,omitempty"    cel:"resourceAttributes"`
+	NonResourceAttributes *NonResourceAttributes `json:"nonResourceAttributes,omitempty" cel:"nonResourceAttributes"`
+}
+
+type ResourceAttributes struct {
+	Namespace     string              `json:"namespace"               cel:"namespace"`
+	Verb          string              `json:"verb"                    cel:"verb"`
+	Group         string              `json:"group"                   cel:"group"`
+	Version       string              `json:"version"                 cel:"version"`
+	Resource      string              `json:"resource"                cel:"resource"`
+	Subresource   string              `json:"subresource"             cel:"subresource"`
+	Name          string              `json:"name"                    cel:"name"`
+	FieldSelector *SelectorAttributes `json:"fieldSelector,omitempty" cel:"fieldSelector"`
+	LabelSelector *SelectorAttributes `json:"labelSelector,omitempty" cel:"labelSelector"`
+}
+
+type NonResourceAttributes struct {
+	Path string `json:"path" cel:"path"`
+	Verb string `json:"verb" cel:"verb"`
+}
+
+// SelectorAttributes holds either the raw selector or its parsed requirements
+type SelectorAttributes struct {
+	RawSelector  string                `json:"rawSelector,omitempty"  cel:"rawSelector"`
+	Requirements []SelectorRequirement `json:"requirements,omitempty" cel:"requirements"`
+}
+
+type SelectorRequirement struct {
+	Key      string   `json:"key"              cel:"key"`
+	Operator string   `json:"operator"         cel:"operator"`
+	Values   []string `json:"values,omitempty" cel:"values"`
+}
+
+// CheckResponse is the decision returned by policies, when neither allowed nor denied is set
+// the webhook has no opinion and the kube-apiserver asks the next authorizer in the chain
+type CheckResponse struct {
+	Allowed bool   `json:"allowed"          cel:"allowed"`
+	Denied  bool   `json:"denied,omitempty" cel:"denied"`
+	Reason  string `json:"reason,omitempty" cel:"reason"`
+}
+
+func NewRequest(spec authorizationv1.SubjectAccessReviewSpec) CheckRequest {
+	r := CheckRequest{
+		User:   spec.User,
+		Groups: spec.Groups,
+		UID:    spec.UID,
+	}
+	if spec.Extra != nil {
+		r.Extra = make(map[string][]string, len(spec.Extra))
+		for k, v := range spec.Extra {
+			r.Extra[k] = v
+		}
+	}
+	if attrs := spec.ResourceAttributes; attrs != nil {
+		r.ResourceAttributes = &ResourceAttributes{
+			Namespace:   attrs.Namespace,
+			Verb:        attrs.Verb,
+			Group:       attrs.Group,
+			Version:     attrs.Version,
+			Resource:    attrs.Resource,
+			Subresource: attrs.Subresource,
+			Name:        attrs.Name,
+		}
+		if selector := attrs.FieldSelector; selector != nil {
+			s := &SelectorAttributes{RawSelector: selector.RawSelector}
+			for _, req := range selector.Requirements {
+				s.Requirements = append(s.Requirements, SelectorRequirement{
+					Key:      req.Key,
+					Operator: string(req.Operator),
+					Values:   req.Values,
+				})
+			}
+			r.ResourceAttributes.FieldSelector = s
+		}
+		if selector := attrs.LabelSelector; selector != nil {
+			s := &SelectorAttributes{RawSelector: selector.RawSelector}
+			for _, req := range selector.Requirements {
+				s.Requirements = append(s.Requirements, SelectorRequirement{
+					Key:      req.Key,
+					Operator: string(req.Operator),
+					Values:   req.Values,
+				})
+			}
+			r.ResourceAttributes.LabelSelector = s
+		}
+	}
+	if attrs := spec.NonResourceAttributes; attrs != nil {
+		r.NonResourceAttributes = &NonResourceAttributes{
+			Path: attrs.Path,
+			Verb: attrs.Verb,
+		}
+	}
+	return r
+}
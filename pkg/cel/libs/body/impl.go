@@ -0,0 +1,73 @@
+package body
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse(body ref.Val, contentType ref.Val) ref.Val {
+	if body, err := content(body); err != nil {
+		return types.WrapErr(err)
+	} else if contentType, err := utils.ConvertToNative[string](contentType); err != nil {
+		return types.WrapErr(err)
+	} else if out, err := Parse(body, contentType); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(out)
+	}
+}
+
+func (c *impl) json(body ref.Val) ref.Val {
+	if body, err := content(body); err != nil {
+		return types.WrapErr(err)
+	} else if out, err := ParseJSON(body); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(out)
+	}
+}
+
+func (c *impl) ndjson(body ref.Val) ref.Val {
+	if body, err := content(body); err != nil {
+		return types.WrapErr(err)
+	} else if out, err := ParseNDJSON(body); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(out)
+	}
+}
+
+func (c *impl) form(body ref.Val) ref.Val {
+	if body, err := content(body); err != nil {
+		return types.WrapErr(err)
+	} else if out, err := ParseForm(body); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(out)
+	}
+}
+
+func (c *impl) multipart(body ref.Val, contentType ref.Val) ref.Val {
+	if body, err := content(body); err != nil {
+		return types.WrapErr(err)
+	} else if contentType, err := utils.ConvertToNative[string](contentType); err != nil {
+		return types.WrapErr(err)
+	} else if out, err := ParseMultipart(body, contentType); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(out)
+	}
+}
+
+// content accepts both bytes and strings as envoy exposes the request body as a string
+func content(body ref.Val) ([]byte, error) {
+	if body, ok := body.(types.String); ok {
+		return []byte(body), nil
+	}
+	return utils.ConvertToNative[[]byte](body)
+}
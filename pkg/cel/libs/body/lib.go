@@ -0,0 +1,73 @@
+package body
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.body"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Part](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"body.Parse": {
+			cel.Overload("body_parse_bytes_string", []*cel.Type{types.BytesType, types.StringType}, types.DynType, cel.BinaryBinding(impl.parse)),
+			cel.Overload("body_parse_string_string", []*cel.Type{types.StringType, types.StringType}, types.DynType, cel.BinaryBinding(impl.parse)),
+		},
+		"body.JSON": {
+			cel.Overload("body_json_bytes", []*cel.Type{types.BytesType}, types.DynType, cel.UnaryBinding(impl.json)),
+			cel.Overload("body_json_string", []*cel.Type{types.StringType}, types.DynType, cel.UnaryBinding(impl.json)),
+		},
+		"body.NDJSON": {
+			cel.Overload("body_ndjson_bytes", []*cel.Type{types.BytesType}, types.NewListType(types.DynType), cel.UnaryBinding(impl.ndjson)),
+			cel.Overload("body_ndjson_string", []*cel.Type{types.StringType}, types.NewListType(types.DynType), cel.UnaryBinding(impl.ndjson)),
+		},
+		"body.Form": {
+			cel.Overload("body_form_bytes", []*cel.Type{types.BytesType}, types.NewMapType(types.StringType, types.NewListType(types.StringType)), cel.UnaryBinding(impl.form)),
+			cel.Overload("body_form_string", []*cel.Type{types.StringType}, types.NewMapType(types.StringType, types.NewListType(types.StringType)), cel.UnaryBinding(impl.form)),
+		},
+		"body.Multipart": {
+			cel.Overload("body_multipart_bytes_string", []*cel.Type{types.BytesType, types.StringType}, types.NewListType(PartType), cel.BinaryBinding(impl.multipart)),
+			cel.Overload("body_multipart_string_string", []*cel.Type{types.StringType, types.StringType}, types.NewListType(PartType), cel.BinaryBinding(impl.multipart)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,195 @@
+package body
+
+import (
+	"bytes"
+	"mime/multipart"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMultipart(t *testing.T) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	assert.NoError(t, writer.WriteField("tenant", "acme"))
+	file, err := writer.CreateFormFile("upload", "report.csv")
+	assert.NoError(t, err)
+	_, err = file.Write(bytes.Repeat([]byte("a"), 1024))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+func TestLib(t *testing.T) {
+	multipartBody, multipartContentType := newMultipart(t)
+	tests := []struct {
+		name        string
+		source      string
+		body        any
+		contentType string
+		want        any
+		wantErr     bool
+	}{{
+		name:        "json",
+		source:      `body.Parse(body, contentType).tenant`,
+		body:        []byte(`{"tenant": "acme"}`),
+		contentType: "application/json; charset=utf-8",
+		want:        "acme",
+	}, {
+		name:        "json suffix",
+		source:      `body.Parse(body, contentType).items.size()`,
+		body:        `{"items": [1, 2]}`,
+		contentType: "application/merge-patch+json",
+		want:        int64(2),
+	}, {
+		name:        "form",
+		source:      `body.Parse(body, contentType).scope`,
+		body:        []byte(`grant_type=client_credentials&scope=read&scope=write`),
+		contentType: "application/x-www-form-urlencoded",
+		want:        []string{"read", "write"},
+	}, {
+		name:        "ndjson",
+		source:      `body.Parse(body, contentType).map(l, l.id)`,
+		body:        []byte("{\"id\": \"a\"}\n\n{\"id\": \"b\"}\n"),
+		contentType: "application/x-ndjson",
+		want:        []string{"a", "b"},
+	}, {
+		name:        "multipart",
+		source:      `body.Parse(body, contentType).map(p, p.name + ":" + p.filename + ":" + string(p.size) + ":" + p.value)`,
+		body:        multipartBody,
+		contentType: multipartContentType,
+		want:        []string{"tenant::4:acme", "upload:report.csv:1024:"},
+	}, {
+		name:        "text",
+		source:      `body.Parse(body, contentType)`,
+		body:        "hello",
+		contentType: "text/plain",
+		want:        "hello",
+	}, {
+		name:        "unsupported content type",
+		source:      `body.Parse(body, contentType)`,
+		body:        []byte("hello"),
+		contentType: "application/octet-stream",
+		wantErr:     true,
+	}, {
+		name:   "json function",
+		source: `body.JSON(body).tenant`,
+		body:   `{"tenant": "acme"}`,
+		want:   "acme",
+	}, {
+		name:    "invalid json",
+		source:  `body.JSON(body)`,
+		body:    `{`,
+		wantErr: true,
+	}, {
+		name:   "form function",
+		source: `body.Form(body)["a"][0]`,
+		body:   "a=b",
+		want:   "b",
+	}, {
+		name:   "ndjson function",
+		source: `body.NDJSON(body)`,
+		body:   "1\n2",
+		want:   []float64{1, 2},
+	}, {
+		name:    "invalid ndjson",
+		source:  `body.NDJSON(body)`,
+		body:    "1\n{",
+		wantErr: true,
+	}, {
+		name:        "multipart function",
+		source:      `body.Multipart(body, contentType).filter(p, p.filename != "").map(p, p.contentType)`,
+		body:        multipartBody,
+		contentType: multipartContentType,
+		want:        []string{"application/octet-stream"},
+	}, {
+		name:        "multipart without boundary",
+		source:      `body.Multipart(body, contentType)`,
+		body:        multipartBody,
+		contentType: "multipart/form-data",
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyType := cel.BytesType
+			if _, ok := tt.body.(string); ok {
+				bodyType = cel.StringType
+			}
+			env, err := cel.NewEnv(
+				Lib(),
+				cel.Variable("body", bodyType),
+				cel.Variable("contentType", cel.StringType),
+			)
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{
+				"body":        tt.body,
+				"contentType": tt.contentType,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			switch want := tt.want.(type) {
+			case []string, []float64:
+				got, err := out.ConvertToNative(reflect.TypeOf(want))
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			default:
+				assert.Equal(t, want, out.Value())
+			}
+		})
+	}
+}
+
+func TestReadMultipart(t *testing.T) {
+	newBody := func(write func(*multipart.Writer)) ([]byte, string) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		write(writer)
+		assert.NoError(t, writer.Close())
+		return buf.Bytes(), writer.FormDataContentType()
+	}
+	t.Run("form values are bounded", func(t *testing.T) {
+		content, contentType := newBody(func(w *multipart.Writer) {
+			assert.NoError(t, w.WriteField("a", string(bytes.Repeat([]byte("a"), MaxValuesSize/2))))
+			assert.NoError(t, w.WriteField("b", string(bytes.Repeat([]byte("b"), MaxValuesSize/2))))
+		})
+		parts, err := ReadMultipart(bytes.NewReader(content), contentType)
+		assert.NoError(t, err)
+		assert.Len(t, parts, 2)
+		content, contentType = newBody(func(w *multipart.Writer) {
+			assert.NoError(t, w.WriteField("a", string(bytes.Repeat([]byte("a"), MaxValuesSize/2))))
+			assert.NoError(t, w.WriteField("b", string(bytes.Repeat([]byte("b"), MaxValuesSize/2+1))))
+		})
+		_, err = ReadMultipart(bytes.NewReader(content), contentType)
+		assert.ErrorContains(t, err, "multipart form values are larger than 1048576 bytes")
+	})
+	t.Run("file contents are not bounded", func(t *testing.T) {
+		content, contentType := newBody(func(w *multipart.Writer) {
+			file, err := w.CreateFormFile("upload", "large.bin")
+			assert.NoError(t, err)
+			_, err = file.Write(bytes.Repeat([]byte("a"), 2*MaxValuesSize))
+			assert.NoError(t, err)
+		})
+		parts, err := ReadMultipart(bytes.NewReader(content), contentType)
+		assert.NoError(t, err)
+		assert.Equal(t, []Part{{Name: "upload", Filename: "large.bin", ContentType: "application/octet-stream", Size: 2 * MaxValuesSize}}, parts)
+	})
+	t.Run("parts are bounded", func(t *testing.T) {
+		content, contentType := newBody(func(w *multipart.Writer) {
+			for range MaxParts + 1 {
+				assert.NoError(t, w.WriteField("a", ""))
+			}
+		})
+		_, err := ReadMultipart(bytes.NewReader(content), contentType)
+		assert.ErrorContains(t, err, "multipart body has more than 1000 parts")
+	})
+}
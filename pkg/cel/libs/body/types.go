@@ -0,0 +1,145 @@
+package body
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+)
+
+var PartType = types.NewObjectType("body.Part")
+
+const (
+	// MaxParts bounds the number of parts of a multipart body
+	MaxParts = 1000
+	// MaxValuesSize bounds the total size in bytes of the form field values of a multipart body, they are held in
+	// memory whatever the maximum body size of the server
+	MaxValuesSize = 1 << 20
+)
+
+// Part describes a multipart form part, file contents are not retained
+type Part struct {
+	Name        string `cel:"name"`
+	Filename    string `cel:"filename"`
+	ContentType string `cel:"contentType"`
+	Size        int64  `cel:"size"`
+	// Value is set for form fields only
+	Value string `cel:"value"`
+}
+
+// Parse parses the body according to the media type of contentType
+func Parse(body []byte, contentType string) (any, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return ParseJSON(body)
+	case mediaType == "application/x-ndjson" || mediaType == "application/jsonl" || mediaType == "application/x-jsonlines":
+		return ParseNDJSON(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		return ParseForm(body)
+	case mediaType == "multipart/form-data":
+		return ParseMultipart(body, contentType)
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body), nil
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+func ParseJSON(body []byte) (any, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func ParseNDJSON(body []byte) ([]any, error) {
+	values := []any{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(nil, len(body)+1)
+	for line := 1; scanner.Scan(); line++ {
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(content, &v); err != nil {
+			return nil, fmt.Errorf("invalid json at line %d: %w", line, err)
+		}
+		values = append(values, v)
+	}
+	return values, scanner.Err()
+}
+
+func ParseForm(body []byte) (map[string][]string, error) {
+	return url.ParseQuery(string(body))
+}
+
+func ParseMultipart(body []byte, contentType string) ([]Part, error) {
+	return ReadMultipart(bytes.NewReader(body), contentType)
+}
+
+// ReadMultipart reads a multipart body from r, file contents are discarded as they are read so
+// that large uploads are never held in memory. Bodies with more than MaxParts parts or form field
+// values larger than MaxValuesSize bytes in total are rejected.
+func ReadMultipart(r io.Reader, contentType string) ([]Part, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("invalid content type %q: not a multipart content type", contentType)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("invalid multipart content type: boundary is missing")
+	}
+	parts := []Part{}
+	valuesSize := int64(0)
+	reader := multipart.NewReader(r, boundary)
+	for {
+		p, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == MaxParts {
+			return nil, fmt.Errorf("multipart body has more than %d parts", MaxParts)
+		}
+		part := Part{
+			Name:        p.FormName(),
+			Filename:    p.FileName(),
+			ContentType: p.Header.Get("Content-Type"),
+		}
+		// only keep the value of form fields, file contents are discarded
+		if part.Filename == "" {
+			var value strings.Builder
+			part.Size, err = io.Copy(&value, io.LimitReader(p, MaxValuesSize-valuesSize+1))
+			valuesSize += part.Size
+			if err == nil && valuesSize > MaxValuesSize {
+				return nil, fmt.Errorf("multipart form values are larger than %d bytes", MaxValuesSize)
+			}
+			part.Value = value.String()
+		} else {
+			part.Size, err = io.Copy(io.Discard, p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+}
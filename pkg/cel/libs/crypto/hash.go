@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Supported hash algorithms
+const (
+	SHA256 = "sha256"
+	SHA512 = "sha512"
+)
+
+func newHash(alg string) (func() hash.Hash, error) {
+	switch strings.ReplaceAll(strings.ToLower(alg), "-", "") {
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm %q", alg)
+}
+
+// Hash returns the digest of data
+func Hash(alg string, data []byte) ([]byte, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	digest := h()
+	digest.Write(data)
+	return digest.Sum(nil), nil
+}
+
+// HMAC returns the message authentication code of data
+func HMAC(alg string, key []byte, data []byte) ([]byte, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// VerifyHMAC returns true if mac is the message authentication code of data, the comparison takes constant time
+func VerifyHMAC(alg string, key []byte, data []byte, mac []byte) (bool, error) {
+	expected, err := HMAC(alg, key, data)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(expected, mac), nil
+}
+
+// Equal compares a and b in constant time
+func Equal(a []byte, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
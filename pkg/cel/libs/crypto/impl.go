@@ -0,0 +1,205 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+	secrets SecretGetter
+}
+
+func (c *impl) hash(alg string) func(ref.Val) ref.Val {
+	return func(data ref.Val) ref.Val {
+		if data, err := content(data); err != nil {
+			return types.WrapErr(err)
+		} else if digest, err := Hash(alg, data); err != nil {
+			return types.WrapErr(err)
+		} else {
+			return types.Bytes(digest)
+		}
+	}
+}
+
+func (c *impl) hmac(args ...ref.Val) ref.Val {
+	if alg, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[[]byte](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if data, err := content(args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if mac, err := HMAC(alg, key, data); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bytes(mac)
+	}
+}
+
+func (c *impl) verify_hmac(args ...ref.Val) ref.Val {
+	if alg, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[[]byte](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if data, err := content(args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if mac, err := utils.ConvertToNative[[]byte](args[3]); err != nil {
+		return types.WrapErr(err)
+	} else if ok, err := VerifyHMAC(alg, key, data, mac); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(ok)
+	}
+}
+
+func (c *impl) equal(a ref.Val, b ref.Val) ref.Val {
+	if a, err := content(a); err != nil {
+		return types.WrapErr(err)
+	} else if b, err := content(b); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(Equal(a, b))
+	}
+}
+
+func (c *impl) hex_encode(data ref.Val) ref.Val {
+	if data, err := utils.ConvertToNative[[]byte](data); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(hex.EncodeToString(data))
+	}
+}
+
+func (c *impl) hex_decode(data ref.Val) ref.Val {
+	if data, err := utils.ConvertToNative[string](data); err != nil {
+		return types.WrapErr(err)
+	} else if decoded, err := hex.DecodeString(data); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bytes(decoded)
+	}
+}
+
+func (c *impl) base64url_encode(data ref.Val) ref.Val {
+	if data, err := utils.ConvertToNative[[]byte](data); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(base64.RawURLEncoding.EncodeToString(data))
+	}
+}
+
+func (c *impl) base64url_decode(data ref.Val) ref.Val {
+	if data, err := utils.ConvertToNative[string](data); err != nil {
+		return types.WrapErr(err)
+	} else if decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bytes(decoded)
+	}
+}
+
+func (c *impl) read_secret(path ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else if data, err := ReadSecret(path); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bytes(data)
+	}
+}
+
+func (c *impl) read_kubernetes_secret(args ...ref.Val) ref.Val {
+	if namespace, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if name, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if key, err := utils.ConvertToNative[string](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if data, err := ReadKubernetesSecret(c.secrets, namespace, name, key); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bytes(data)
+	}
+}
+
+func (c *impl) verify_github(args ...ref.Val) ref.Val {
+	if secret, err := utils.ConvertToNative[[]byte](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if signature, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := content(args[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(VerifyGitHub(secret, signature, body))
+	}
+}
+
+func (c *impl) verify_stripe(args ...ref.Val) ref.Val {
+	if secret, err := utils.ConvertToNative[[]byte](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if header, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := content(args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if tolerance, err := tolerance(args, 3); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(VerifyStripe(secret, header, body, tolerance, time.Now()))
+	}
+}
+
+func (c *impl) verify_slack(args ...ref.Val) ref.Val {
+	if secret, err := utils.ConvertToNative[[]byte](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if timestamp, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if signature, err := utils.ConvertToNative[string](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := content(args[3]); err != nil {
+		return types.WrapErr(err)
+	} else if tolerance, err := tolerance(args, 4); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(VerifySlack(secret, timestamp, signature, body, tolerance, time.Now()))
+	}
+}
+
+func (c *impl) verify_standard_webhook(args ...ref.Val) ref.Val {
+	if secret, err := utils.ConvertToNative[[]byte](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if id, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if timestamp, err := utils.ConvertToNative[string](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if signature, err := utils.ConvertToNative[string](args[3]); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := content(args[4]); err != nil {
+		return types.WrapErr(err)
+	} else if tolerance, err := tolerance(args, 5); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(VerifyStandardWebhook(secret, id, timestamp, signature, body, tolerance, time.Now()))
+	}
+}
+
+// content accepts string and bytes values, envoy request bodies are strings while http request bodies are bytes
+func content(data ref.Val) ([]byte, error) {
+	if data, ok := data.(types.String); ok {
+		return []byte(data), nil
+	}
+	return utils.ConvertToNative[[]byte](data)
+}
+
+// tolerance returns the optional tolerance argument, or the default tolerance when it is not set
+func tolerance(args []ref.Val, index int) (time.Duration, error) {
+	if len(args) <= index {
+		return DefaultTolerance, nil
+	}
+	return utils.ConvertToNative[time.Duration](args[index])
+}
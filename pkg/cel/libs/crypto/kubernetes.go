@@ -0,0 +1,158 @@
+package crypto
+
+import (
+	"container/list"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// secretTTL is how long Kubernetes Secrets are cached, rotated secrets are picked up after at most that long
+	secretTTL = time.Minute
+	// secretFailureTTL is how long failures to read a Kubernetes Secret are cached
+	secretFailureTTL = 10 * time.Second
+	// secretTimeout bounds the requests made to the Kubernetes API server
+	secretTimeout = 10 * time.Second
+	// maxSecrets bounds the number of cached Kubernetes Secrets, the least recently used ones are evicted first
+	maxSecrets = 100
+)
+
+// SecretGetter reads Kubernetes Secrets
+type SecretGetter interface {
+	GetSecret(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+}
+
+type dynamicSecretGetter struct {
+	client dynamic.Interface
+}
+
+// NewSecretGetter returns a SecretGetter reading Secrets with a dynamic client
+func NewSecretGetter(client dynamic.Interface) SecretGetter {
+	return dynamicSecretGetter{client: client}
+}
+
+func (g dynamicSecretGetter) GetSecret(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return g.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+type cachedSecret struct {
+	key     string
+	data    map[string]string
+	err     error
+	expires time.Time
+}
+
+// secretCache keeps Kubernetes Secrets in memory so that policies don't call the API server on every evaluation
+type secretCache struct {
+	ttl        time.Duration
+	maxSecrets int
+	group      singleflight.Group
+	lock       sync.Mutex
+	lru        *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+var kubernetesSecrets = newSecretCache(secretTTL, maxSecrets)
+
+func newSecretCache(ttl time.Duration, maxSecrets int) *secretCache {
+	return &secretCache{
+		ttl:        ttl,
+		maxSecrets: maxSecrets,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+// ReadKubernetesSecret returns the value of a key of a Kubernetes Secret, Secrets are cached for a minute
+func ReadKubernetesSecret(secrets SecretGetter, namespace, name, key string) ([]byte, error) {
+	if secrets == nil {
+		return nil, errors.New("kubernetes secrets can't be read without a kubernetes cluster")
+	}
+	data, err := kubernetesSecrets.get(secrets, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", namespace, name, key)
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+func (c *secretCache) get(secrets SecretGetter, namespace, name string) (map[string]string, error) {
+	key := namespace + "/" + name
+	if entry, ok := c.lookup(key); ok {
+		return entry.data, entry.err
+	}
+	// concurrent evaluations share the same request
+	out, _, _ := c.group.Do(key, func() (any, error) {
+		if entry, ok := c.lookup(key); ok {
+			return entry, nil
+		}
+		data, err := fetchSecret(secrets, namespace, name)
+		ttl := c.ttl
+		if err != nil {
+			ttl = min(ttl, secretFailureTTL)
+		}
+		entry := &cachedSecret{key: key, data: data, err: err, expires: c.now().Add(ttl)}
+		c.put(entry)
+		return entry, nil
+	})
+	entry := out.(*cachedSecret)
+	return entry.data, entry.err
+}
+
+func fetchSecret(secrets SecretGetter, namespace, name string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	secret, err := secrets.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := unstructured.NestedStringMap(secret.Object, "data")
+	return data, err
+}
+
+func (c *secretCache) lookup(key string) (*cachedSecret, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cachedSecret)
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		c.lru.Remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry, true
+}
+
+func (c *secretCache) put(entry *cachedSecret) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		delete(c.entries, entry.key)
+		c.lru.Remove(element)
+	}
+	for c.maxSecrets > 0 && len(c.entries) >= c.maxSecrets {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*cachedSecret).key)
+		c.lru.Remove(oldest)
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+}
@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeSecrets holds secrets keyed by namespace/name and counts the requests made
+type fakeSecrets struct {
+	secrets  map[string]map[string]any
+	requests atomic.Int32
+}
+
+func (f *fakeSecrets) GetSecret(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	f.requests.Add(1)
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, fmt.Errorf("request without a timeout")
+	}
+	object, ok := f.secrets[namespace+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s not found", namespace, name)
+	}
+	return &unstructured.Unstructured{Object: object}, nil
+}
+
+func TestReadKubernetesSecret(t *testing.T) {
+	kubernetesSecrets = newSecretCache(secretTTL, maxSecrets)
+	secrets := &fakeSecrets{secrets: map[string]map[string]any{
+		"default/api": {"data": map[string]any{"key": "czNjcjN0", "invalid": "not base64!"}},
+	}}
+	got, err := ReadKubernetesSecret(secrets, "default", "api", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(got))
+	_, err = ReadKubernetesSecret(secrets, "default", "api", "missing")
+	assert.ErrorContains(t, err, `secret default/api has no key "missing"`)
+	_, err = ReadKubernetesSecret(secrets, "default", "api", "invalid")
+	assert.Error(t, err)
+	_, err = ReadKubernetesSecret(secrets, "default", "other", "key")
+	assert.ErrorContains(t, err, "not found")
+	_, err = ReadKubernetesSecret(nil, "default", "api", "key")
+	assert.ErrorContains(t, err, "without a kubernetes cluster")
+	// the secrets were only requested once each
+	assert.Equal(t, int32(2), secrets.requests.Load())
+}
+
+func TestSecretCache(t *testing.T) {
+	now := time.Now()
+	cache := newSecretCache(secretTTL, 2)
+	cache.now = func() time.Time { return now }
+	secrets := &fakeSecrets{secrets: map[string]map[string]any{
+		"default/a": {"data": map[string]any{"key": "YQ=="}},
+		"default/b": {"data": map[string]any{"key": "Yg=="}},
+		"default/c": {"data": map[string]any{"key": "Yw=="}},
+	}}
+	get := func(name string) error {
+		_, err := cache.get(secrets, "default", name)
+		return err
+	}
+	t.Run("secrets are cached until they expire", func(t *testing.T) {
+		secrets.requests.Store(0)
+		for range 3 {
+			require.NoError(t, get("a"))
+		}
+		assert.Equal(t, int32(1), secrets.requests.Load())
+		now = now.Add(secretTTL)
+		require.NoError(t, get("a"))
+		assert.Equal(t, int32(2), secrets.requests.Load())
+	})
+	t.Run("failures are cached for a shorter time", func(t *testing.T) {
+		secrets.requests.Store(0)
+		for range 3 {
+			assert.Error(t, get("missing"))
+		}
+		assert.Equal(t, int32(1), secrets.requests.Load())
+		now = now.Add(secretFailureTTL)
+		assert.Error(t, get("missing"))
+		assert.Equal(t, int32(2), secrets.requests.Load())
+	})
+	t.Run("least recently used secrets are evicted", func(t *testing.T) {
+		now = now.Add(secretTTL)
+		secrets.requests.Store(0)
+		require.NoError(t, get("a"))
+		require.NoError(t, get("b"))
+		require.NoError(t, get("a"))
+		require.NoError(t, get("c"))
+		assert.Equal(t, int32(3), secrets.requests.Load())
+		// b was evicted, a is still cached
+		require.NoError(t, get("a"))
+		assert.Equal(t, int32(3), secrets.requests.Load())
+		require.NoError(t, get("b"))
+		assert.Equal(t, int32(4), secrets.requests.Load())
+	})
+}
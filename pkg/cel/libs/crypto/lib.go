@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+type lib struct {
+	secrets SecretGetter
+}
+
+// Lib returns the crypto library, secrets is used to read Kubernetes Secrets and can be nil without a cluster
+func Lib(secrets SecretGetter) cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{secrets: secrets})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.crypto"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter, c.secrets}
+	// build our function overloads, data and bodies are accepted as string or bytes
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"crypto.SHA256": {
+			cel.Overload("crypto_sha256_string", []*cel.Type{types.StringType}, types.BytesType, cel.UnaryBinding(impl.hash(SHA256))),
+			cel.Overload("crypto_sha256_bytes", []*cel.Type{types.BytesType}, types.BytesType, cel.UnaryBinding(impl.hash(SHA256))),
+		},
+		"crypto.SHA512": {
+			cel.Overload("crypto_sha512_string", []*cel.Type{types.StringType}, types.BytesType, cel.UnaryBinding(impl.hash(SHA512))),
+			cel.Overload("crypto_sha512_bytes", []*cel.Type{types.BytesType}, types.BytesType, cel.UnaryBinding(impl.hash(SHA512))),
+		},
+		"crypto.HMAC": {
+			cel.Overload("crypto_hmac_string_bytes_string", []*cel.Type{types.StringType, types.BytesType, types.StringType}, types.BytesType, cel.FunctionBinding(impl.hmac)),
+			cel.Overload("crypto_hmac_string_bytes_bytes", []*cel.Type{types.StringType, types.BytesType, types.BytesType}, types.BytesType, cel.FunctionBinding(impl.hmac)),
+		},
+		"crypto.VerifyHMAC": {
+			cel.Overload("crypto_verify_hmac_string_bytes_string_bytes", []*cel.Type{types.StringType, types.BytesType, types.StringType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_hmac)),
+			cel.Overload("crypto_verify_hmac_string_bytes_bytes_bytes", []*cel.Type{types.StringType, types.BytesType, types.BytesType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_hmac)),
+		},
+		"crypto.Equal": {
+			cel.Overload("crypto_equal_string_string", []*cel.Type{types.StringType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.equal)),
+			cel.Overload("crypto_equal_bytes_bytes", []*cel.Type{types.BytesType, types.BytesType}, types.BoolType, cel.BinaryBinding(impl.equal)),
+		},
+		"crypto.HexEncode": {
+			cel.Overload("crypto_hex_encode_bytes", []*cel.Type{types.BytesType}, types.StringType, cel.UnaryBinding(impl.hex_encode)),
+		},
+		"crypto.HexDecode": {
+			cel.Overload("crypto_hex_decode_string", []*cel.Type{types.StringType}, types.BytesType, cel.UnaryBinding(impl.hex_decode)),
+		},
+		"crypto.Base64URLEncode": {
+			cel.Overload("crypto_base64url_encode_bytes", []*cel.Type{types.BytesType}, types.StringType, cel.UnaryBinding(impl.base64url_encode)),
+		},
+		"crypto.Base64URLDecode": {
+			cel.Overload("crypto_base64url_decode_string", []*cel.Type{types.StringType}, types.BytesType, cel.UnaryBinding(impl.base64url_decode)),
+		},
+		"crypto.ReadSecret": {
+			cel.Overload("crypto_read_secret_string", []*cel.Type{types.StringType}, types.BytesType, cel.UnaryBinding(impl.read_secret)),
+			cel.Overload("crypto_read_secret_string_string_string", []*cel.Type{types.StringType, types.StringType, types.StringType}, types.BytesType, cel.FunctionBinding(impl.read_kubernetes_secret)),
+		},
+		"crypto.VerifyGitHub": {
+			cel.Overload("crypto_verify_github_bytes_string_string", []*cel.Type{types.BytesType, types.StringType, types.StringType}, types.BoolType, cel.FunctionBinding(impl.verify_github)),
+			cel.Overload("crypto_verify_github_bytes_string_bytes", []*cel.Type{types.BytesType, types.StringType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_github)),
+		},
+		"crypto.VerifyStripe": {
+			cel.Overload("crypto_verify_stripe_bytes_string_string", []*cel.Type{types.BytesType, types.StringType, types.StringType}, types.BoolType, cel.FunctionBinding(impl.verify_stripe)),
+			cel.Overload("crypto_verify_stripe_bytes_string_bytes", []*cel.Type{types.BytesType, types.StringType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_stripe)),
+			cel.Overload("crypto_verify_stripe_bytes_string_string_duration", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_stripe)),
+			cel.Overload("crypto_verify_stripe_bytes_string_bytes_duration", []*cel.Type{types.BytesType, types.StringType, types.BytesType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_stripe)),
+		},
+		"crypto.VerifySlack": {
+			cel.Overload("crypto_verify_slack_bytes_string_string_string", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType}, types.BoolType, cel.FunctionBinding(impl.verify_slack)),
+			cel.Overload("crypto_verify_slack_bytes_string_string_bytes", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_slack)),
+			cel.Overload("crypto_verify_slack_bytes_string_string_string_duration", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_slack)),
+			cel.Overload("crypto_verify_slack_bytes_string_string_bytes_duration", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.BytesType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_slack)),
+		},
+		"crypto.VerifyStandardWebhook": {
+			cel.Overload("crypto_verify_standard_webhook_bytes_string_string_string_string", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType, types.StringType}, types.BoolType, cel.FunctionBinding(impl.verify_standard_webhook)),
+			cel.Overload("crypto_verify_standard_webhook_bytes_string_string_string_bytes", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType, types.BytesType}, types.BoolType, cel.FunctionBinding(impl.verify_standard_webhook)),
+			cel.Overload("crypto_verify_standard_webhook_bytes_string_string_string_string_duration", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType, types.StringType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_standard_webhook)),
+			cel.Overload("crypto_verify_standard_webhook_bytes_string_string_string_bytes_duration", []*cel.Type{types.BytesType, types.StringType, types.StringType, types.StringType, types.BytesType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.verify_standard_webhook)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,201 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func TestLib(t *testing.T) {
+	secret := []byte("s3cr3t")
+	body := `{"event":"push"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	webhookSecret := []byte("whsec_" + base64.StdEncoding.EncodeToString(secret))
+	root := t.TempDir()
+	SetSecretsRoot(root)
+	t.Cleanup(func() { SetSecretsRoot(DefaultSecretsRoot) })
+	path := filepath.Join(root, "secret")
+	require.NoError(t, os.WriteFile(path, secret, 0o600))
+	kubernetesSecrets = newSecretCache(secretTTL, maxSecrets)
+	secrets := &fakeSecrets{secrets: map[string]map[string]any{
+		"webhooks/github": map[string]any{"data": map[string]any{"secret": base64.StdEncoding.EncodeToString(secret)}},
+	}}
+	vars := map[string]any{
+		"secret":        secret,
+		"webhookSecret": webhookSecret,
+		"path":          path,
+		"body":          body,
+		"now":           now,
+		"old":           old,
+		"mac":           hex.EncodeToString(sign(secret, body)),
+		"github":        "sha256=" + hex.EncodeToString(sign(secret, body)),
+		"stripe":        "t=" + now + ",v1=" + hex.EncodeToString(sign([]byte("previous"), now+"."+body)) + ",v1=" + hex.EncodeToString(sign(secret, now+"."+body)),
+		"stripeOld":     "t=" + old + ",v1=" + hex.EncodeToString(sign(secret, old+"."+body)),
+		"slack":         "v0=" + hex.EncodeToString(sign(secret, "v0:"+now+":"+body)),
+		"slackOld":      "v0=" + hex.EncodeToString(sign(secret, "v0:"+old+":"+body)),
+		"standard":      "v1,bm90IHRoZSBzaWduYXR1cmU= v1," + base64.StdEncoding.EncodeToString(sign(secret, "msg_1."+now+"."+body)),
+	}
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "sha256",
+		source: `crypto.HexEncode(crypto.SHA256("abc"))`,
+		want:   "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	}, {
+		name:   "sha512",
+		source: `crypto.SHA512(b"abc").size()`,
+		want:   int64(64),
+	}, {
+		name:   "hmac",
+		source: `crypto.HexEncode(crypto.HMAC("sha256", secret, body))`,
+		want:   hex.EncodeToString(sign(secret, body)),
+	}, {
+		name:   "hmac bytes",
+		source: `crypto.HMAC("SHA-512", secret, bytes(body)).size()`,
+		want:   int64(64),
+	}, {
+		name:    "hmac unsupported algorithm",
+		source:  `crypto.HMAC("md5", secret, body)`,
+		wantErr: true,
+	}, {
+		name:   "verify hmac",
+		source: `crypto.VerifyHMAC("sha256", secret, body, crypto.HexDecode(mac))`,
+		want:   true,
+	}, {
+		name:   "verify hmac mismatch",
+		source: `crypto.VerifyHMAC("sha256", b"other", body, crypto.HexDecode(mac))`,
+		want:   false,
+	}, {
+		name:   "equal",
+		source: `crypto.Equal("abc", "abc") && !crypto.Equal(b"abc", b"abd")`,
+		want:   true,
+	}, {
+		name:   "base64url",
+		source: `crypto.Base64URLEncode(b"\xfb\xff") + " " + string(crypto.Base64URLDecode("aGk="))`,
+		want:   "-_8 hi",
+	}, {
+		name:    "invalid hex",
+		source:  `crypto.HexDecode("xyz")`,
+		wantErr: true,
+	}, {
+		name:   "read secret",
+		source: `crypto.VerifyGitHub(crypto.ReadSecret(path), github, body)`,
+		want:   true,
+	}, {
+		name:   "read secret relative to the root",
+		source: `crypto.ReadSecret("secret") == crypto.ReadSecret(path)`,
+		want:   true,
+	}, {
+		name:    "read missing secret",
+		source:  `crypto.ReadSecret(path + ".missing")`,
+		wantErr: true,
+	}, {
+		name:    "read secret outside of the root",
+		source:  `crypto.ReadSecret("../secret")`,
+		wantErr: true,
+	}, {
+		name:   "read kubernetes secret",
+		source: `crypto.VerifyGitHub(crypto.ReadSecret("webhooks", "github", "secret"), github, body)`,
+		want:   true,
+	}, {
+		name:    "read missing kubernetes secret key",
+		source:  `crypto.ReadSecret("webhooks", "github", "other")`,
+		wantErr: true,
+	}, {
+		name:    "read missing kubernetes secret",
+		source:  `crypto.ReadSecret("webhooks", "stripe", "secret")`,
+		wantErr: true,
+	}, {
+		name:   "github",
+		source: `crypto.VerifyGitHub(secret, github, bytes(body))`,
+		want:   true,
+	}, {
+		name:   "github tampered",
+		source: `crypto.VerifyGitHub(secret, github, body + " ")`,
+		want:   false,
+	}, {
+		name:   "github malformed",
+		source: `crypto.VerifyGitHub(secret, "sha1=abcd", body)`,
+		want:   false,
+	}, {
+		name:   "stripe",
+		source: `crypto.VerifyStripe(secret, stripe, body)`,
+		want:   true,
+	}, {
+		name:   "stripe replayed",
+		source: `crypto.VerifyStripe(secret, stripeOld, body)`,
+		want:   false,
+	}, {
+		name:   "stripe tolerance",
+		source: `crypto.VerifyStripe(secret, stripeOld, bytes(body), duration("2h"))`,
+		want:   true,
+	}, {
+		name:   "slack",
+		source: `crypto.VerifySlack(secret, now, slack, body)`,
+		want:   true,
+	}, {
+		name:   "slack replayed",
+		source: `crypto.VerifySlack(secret, old, slackOld, body, duration("1m"))`,
+		want:   false,
+	}, {
+		name:   "standard webhook",
+		source: `crypto.VerifyStandardWebhook(webhookSecret, "msg_1", now, standard, body)`,
+		want:   true,
+	}, {
+		name:   "standard webhook id mismatch",
+		source: `crypto.VerifyStandardWebhook(webhookSecret, "msg_2", now, standard, body)`,
+		want:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(
+				Lib(secrets),
+				cel.Variable("secret", cel.BytesType),
+				cel.Variable("webhookSecret", cel.BytesType),
+				cel.Variable("path", cel.StringType),
+				cel.Variable("body", cel.StringType),
+				cel.Variable("now", cel.StringType),
+				cel.Variable("old", cel.StringType),
+				cel.Variable("mac", cel.StringType),
+				cel.Variable("github", cel.StringType),
+				cel.Variable("stripe", cel.StringType),
+				cel.Variable("stripeOld", cel.StringType),
+				cel.Variable("slack", cel.StringType),
+				cel.Variable("slackOld", cel.StringType),
+				cel.Variable("standard", cel.StringType),
+			)
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kyverno/kyverno-authz/pkg/utils/filecache"
+)
+
+const (
+	// DefaultSecretsRoot is the directory secret files are read from by default
+	DefaultSecretsRoot = "/etc/kyverno-authz/secrets"
+	// maxSecretSize bounds the size of secret files
+	maxSecretSize = 1 << 20
+)
+
+var secrets = struct {
+	lock  sync.Mutex
+	root  string
+	files *filecache.Cache[[]byte]
+}{
+	root: DefaultSecretsRoot,
+	files: filecache.New(func(data []byte) ([]byte, error) {
+		return data, nil
+	}, maxSecretSize),
+}
+
+// SetSecretsRoot restricts ReadSecret to the files under root, an empty root disables secret files
+func SetSecretsRoot(root string) {
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+	if root != "" {
+		root = filepath.Clean(root)
+	}
+	secrets.root = root
+}
+
+// ReadSecret returns the content of a secret file under the secrets root, relative paths are relative to the root.
+// Files are cached and reloaded when they change.
+func ReadSecret(path string) ([]byte, error) {
+	path, err := ResolveSecret(path)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.files.Load(path)
+}
+
+// ResolveSecret returns the clean path of a secret file, it fails when the file is not under the secrets root.
+// Libraries reading secret files with their own parser use it to apply the same restrictions as ReadSecret.
+func ResolveSecret(path string) (string, error) {
+	secrets.lock.Lock()
+	root := secrets.root
+	secrets.lock.Unlock()
+	return resolveSecret(root, path)
+}
+
+// resolveSecret returns the clean path, it must be under the root before and after symlinks are resolved.
+// Secrets mounted from a Kubernetes Secret are symlinks to a directory under the mount point and are accepted.
+func resolveSecret(root, path string) (string, error) {
+	if root == "" {
+		return "", errors.New("secret files are disabled, the secrets root is not set")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	if !within(root, path) {
+		return "", fmt.Errorf("secret file %q is outside of the secrets root %q", path, root)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(resolvedRoot, resolved) {
+		return "", fmt.Errorf("secret file %q is outside of the secrets root %q", path, root)
+	}
+	return path, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSecret(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "secrets")
+	require.NoError(t, os.MkdirAll(root, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outside"), []byte("outside"), 0o600))
+	// a kubernetes secret volume links keys to a timestamped directory through ..data
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github", "..2025_01_01"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "github", "..2025_01_01", "secret"), []byte("v1"), 0o600))
+	require.NoError(t, os.Symlink("..2025_01_01", filepath.Join(root, "github", "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "secret"), filepath.Join(root, "github", "secret")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "outside"), filepath.Join(root, "escape")))
+	SetSecretsRoot(root)
+	t.Cleanup(func() { SetSecretsRoot(DefaultSecretsRoot) })
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr string
+	}{{
+		name: "absolute path",
+		path: filepath.Join(root, "github", "secret"),
+		want: "v1",
+	}, {
+		name: "relative path",
+		path: "github/secret",
+		want: "v1",
+	}, {
+		name: "path cleaned under the root",
+		path: filepath.Join(root, "other", "..", "github", "secret"),
+		want: "v1",
+	}, {
+		name:    "path escaping the root",
+		path:    filepath.Join(root, "..", "outside"),
+		wantErr: "is outside of the secrets root",
+	}, {
+		name:    "relative path escaping the root",
+		path:    "../outside",
+		wantErr: "is outside of the secrets root",
+	}, {
+		name:    "symlink escaping the root",
+		path:    "escape",
+		wantErr: "is outside of the secrets root",
+	}, {
+		name:    "missing file",
+		path:    "missing",
+		wantErr: "no such file or directory",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSecret(tt.path)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+	t.Run("rotation", func(t *testing.T) {
+		// kubernetes swaps the ..data link to a new directory when the secret is updated
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "github", "..2025_01_02"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "github", "..2025_01_02", "secret"), []byte("v2"), 0o600))
+		require.NoError(t, os.Remove(filepath.Join(root, "github", "..data")))
+		require.NoError(t, os.Symlink("..2025_01_02", filepath.Join(root, "github", "..data")))
+		got, err := ReadSecret("github/secret")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", string(got))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		SetSecretsRoot("")
+		defer SetSecretsRoot(root)
+		_, err := ReadSecret(filepath.Join(root, "github", "secret"))
+		assert.ErrorContains(t, err, "secret files are disabled")
+	})
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTolerance is the maximum difference between the signature timestamp and the current time,
+// it is the tolerance recommended by most webhook providers
+const DefaultTolerance = 5 * time.Minute
+
+// VerifyGitHub verifies a GitHub `X-Hub-Signature-256` header (`sha256=<hex>`)
+func VerifyGitHub(secret []byte, signature string, body []byte) bool {
+	encoded, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	return verifyHex(secret, body, encoded)
+}
+
+// VerifyStripe verifies a Stripe `Stripe-Signature` header (`t=<timestamp>,v1=<hex>,...`)
+func VerifyStripe(secret []byte, header string, body []byte, tolerance time.Duration, now time.Time) bool {
+	var timestamp string
+	var signatures []string
+	for _, element := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(element), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if !checkTimestamp(timestamp, tolerance, now) {
+		return false
+	}
+	payload := concat(timestamp, ".", body)
+	// several signatures are sent while the secret is rolled
+	for _, signature := range signatures {
+		if verifyHex(secret, payload, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifySlack verifies Slack `X-Slack-Request-Timestamp` and `X-Slack-Signature` (`v0=<hex>`) headers
+func VerifySlack(secret []byte, timestamp string, signature string, body []byte, tolerance time.Duration, now time.Time) bool {
+	encoded, ok := strings.CutPrefix(signature, "v0=")
+	if !ok || !checkTimestamp(timestamp, tolerance, now) {
+		return false
+	}
+	return verifyHex(secret, concat("v0:"+timestamp, ":", body), encoded)
+}
+
+// VerifyStandardWebhook verifies `webhook-id`, `webhook-timestamp` and `webhook-signature` headers,
+// see https://www.standardwebhooks.com, secrets may have the `whsec_` prefix
+func VerifyStandardWebhook(secret []byte, id string, timestamp string, signature string, body []byte, tolerance time.Duration, now time.Time) bool {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(secret)), "whsec_"))
+	if err != nil || !checkTimestamp(timestamp, tolerance, now) {
+		return false
+	}
+	mac, err := HMAC(SHA256, key, concat(id+"."+timestamp, ".", body))
+	if err != nil {
+		return false
+	}
+	// several signatures are sent while the secret is rolled
+	for _, signature := range strings.Fields(signature) {
+		encoded, ok := strings.CutPrefix(signature, "v1,")
+		if !ok {
+			continue
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil && Equal(mac, decoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyHex(secret []byte, data []byte, encoded string) bool {
+	mac, err := hex.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	ok, err := VerifyHMAC(SHA256, secret, data, mac)
+	return err == nil && ok
+}
+
+// checkTimestamp returns true if the unix timestamp is within tolerance of now, old signatures can't be replayed
+func checkTimestamp(timestamp string, tolerance time.Duration, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	delta := now.Sub(time.Unix(seconds, 0))
+	return delta <= tolerance && delta >= -tolerance
+}
+
+func concat(prefix string, separator string, body []byte) []byte {
+	out := make([]byte, 0, len(prefix)+len(separator)+len(body))
+	out = append(out, prefix...)
+	out = append(out, separator...)
+	return append(out, body...)
+}
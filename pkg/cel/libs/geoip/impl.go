@@ -0,0 +1,49 @@
+package geoip
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) lookup_string_string(path ref.Val, address ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.lookup([]string{path}, address)
+	}
+}
+
+func (c *impl) lookup_list_string(paths ref.Val, address ref.Val) ref.Val {
+	if paths, err := utils.ConvertToNative[[]string](paths); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.lookup(paths, address)
+	}
+}
+
+func (c *impl) lookup(paths []string, address ref.Val) ref.Val {
+	if address, err := utils.ConvertToNative[string](address); err != nil {
+		return types.WrapErr(err)
+	} else if location, err := Lookup(paths, address); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(location)
+	}
+}
+
+func (c *impl) forwarded_for_string_int(header ref.Val, trustedProxies ref.Val) ref.Val {
+	if header, err := utils.ConvertToNative[string](header); err != nil {
+		return types.WrapErr(err)
+	} else if trustedProxies, err := utils.ConvertToNative[int](trustedProxies); err != nil {
+		return types.WrapErr(err)
+	} else if address, err := ForwardedFor(header, trustedProxies); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(address)
+	}
+}
@@ -0,0 +1,60 @@
+package geoip
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.geoip"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Location](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"geoip.Lookup": {
+			cel.Overload("geoip_lookup_string_string", []*cel.Type{types.StringType, types.StringType}, LocationType, cel.BinaryBinding(impl.lookup_string_string)),
+			cel.Overload("geoip_lookup_list_string", []*cel.Type{types.NewListType(types.StringType), types.StringType}, LocationType, cel.BinaryBinding(impl.lookup_list_string)),
+		},
+		"geoip.ForwardedFor": {
+			cel.Overload("geoip_forwarded_for_string_int", []*cel.Type{types.StringType, types.IntType}, types.StringType, cel.BinaryBinding(impl.forwarded_for_string_int)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
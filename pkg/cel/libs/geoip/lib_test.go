@@ -0,0 +1,148 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func names(name string) map[string]any {
+	return map[string]any{"en": name}
+}
+
+func writeDatabases(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	city := newMMDBWriter()
+	city.insert(t, "81.2.69.0/24", map[string]any{
+		"city":               map[string]any{"names": names("London")},
+		"continent":          map[string]any{"code": "EU"},
+		"country":            map[string]any{"iso_code": "GB", "names": names("United Kingdom")},
+		"registered_country": map[string]any{"iso_code": "GB"},
+		"subdivisions":       []any{map[string]any{"iso_code": "ENG", "names": names("England")}},
+		"location":           map[string]any{"latitude": 51.5142, "longitude": -0.0931, "time_zone": "Europe/London"},
+	})
+	city.insert(t, "175.16.199.0/24", map[string]any{
+		"continent": map[string]any{"code": "AS"},
+		"country":   map[string]any{"iso_code": "KP", "names": names("North Korea")},
+	})
+	asn := newMMDBWriter()
+	asn.insert(t, "81.2.64.0/19", map[string]any{
+		"autonomous_system_number":       uint32(20712),
+		"autonomous_system_organization": "Andrews & Arnold Ltd",
+	})
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	require.NoError(t, os.WriteFile(cityPath, city.bytes("GeoLite2-City"), 0o600))
+	require.NoError(t, os.WriteFile(asnPath, asn.bytes("GeoLite2-ASN"), 0o600))
+	return cityPath, asnPath
+}
+
+func TestLib(t *testing.T) {
+	city, asn := writeDatabases(t)
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "country",
+		source: `geoip.Lookup(city, "81.2.69.160").country`,
+		want:   "GB",
+	}, {
+		name:   "city fields",
+		source: `[geoip.Lookup(city, "81.2.69.160")].map(l, l.countryName + "," + l.continent + "," + l.region + "," + l.regionName + "," + l.city + "," + l.timeZone + "," + l.network)[0]`,
+		want:   "United Kingdom,EU,ENG,England,London,Europe/London,81.2.69.0/24",
+	}, {
+		name:   "location",
+		source: `geoip.Lookup(city, "81.2.69.160").latitude`,
+		want:   51.5142,
+	}, {
+		name:   "asn",
+		source: `[geoip.Lookup(asn, "81.2.69.160")].map(l, string(l.asn) + " " + l.organization)[0]`,
+		want:   "20712 Andrews & Arnold Ltd",
+	}, {
+		name:   "several databases",
+		source: `[geoip.Lookup([city, asn], "81.2.69.160")].map(l, l.country + " " + string(l.asn) + " " + l.network)[0]`,
+		want:   "GB 20712 81.2.69.0/24",
+	}, {
+		name:   "partial match",
+		source: `[geoip.Lookup([city, asn], "81.2.70.1")].map(l, l.found && l.country == "" && l.asn == 20712)[0]`,
+		want:   true,
+	}, {
+		name:   "not found",
+		source: `geoip.Lookup([city, asn], "10.0.0.1").found`,
+		want:   false,
+	}, {
+		name:   "address with port",
+		source: `geoip.Lookup(city, "175.16.199.10:443").country in ["KP", "IR"]`,
+		want:   true,
+	}, {
+		name:    "invalid address",
+		source:  `geoip.Lookup(city, "not an ip")`,
+		wantErr: true,
+	}, {
+		name:    "missing database",
+		source:  `geoip.Lookup(city + ".missing", "81.2.69.160")`,
+		wantErr: true,
+	}, {
+		name:   "forwarded for",
+		source: `geoip.ForwardedFor("1.2.3.4, 81.2.69.160, 10.0.0.1", 2)`,
+		want:   "81.2.69.160",
+	}, {
+		name:   "forwarded for lookup",
+		source: `geoip.Lookup(city, geoip.ForwardedFor("81.2.69.160", 1)).country`,
+		want:   "GB",
+	}, {
+		name:    "forwarded for too short",
+		source:  `geoip.ForwardedFor("81.2.69.160", 2)`,
+		wantErr: true,
+	}, {
+		name:    "forwarded for without trusted proxy",
+		source:  `geoip.ForwardedFor("81.2.69.160", 0)`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("city", cel.StringType), cel.Variable("asn", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"city": city, "asn": asn})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.Value())
+		})
+	}
+}
+
+func TestLookup_Reload(t *testing.T) {
+	city, _ := writeDatabases(t)
+	location, err := Lookup([]string{city}, "81.2.69.160")
+	require.NoError(t, err)
+	assert.Equal(t, "GB", location.Country)
+	// databases are reloaded when the file is replaced
+	updated := newMMDBWriter()
+	updated.insert(t, "81.2.69.0/24", map[string]any{"country": map[string]any{"iso_code": "FR"}})
+	require.NoError(t, os.WriteFile(city, updated.bytes("GeoLite2-Country"), 0o600))
+	require.NoError(t, os.Chtimes(city, time.Now(), time.Now().Add(time.Minute)))
+	location, err = Lookup([]string{city}, "81.2.69.160")
+	require.NoError(t, err)
+	assert.Equal(t, "FR", location.Country)
+	// a database that can't be parsed doesn't replace the previous one
+	require.NoError(t, os.WriteFile(city, []byte("truncated"), 0o600))
+	require.NoError(t, os.Chtimes(city, time.Now(), time.Now().Add(2*time.Minute)))
+	location, err = Lookup([]string{city}, "81.2.69.160")
+	require.NoError(t, err)
+	assert.Equal(t, "FR", location.Country)
+}
@@ -0,0 +1,78 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/kyverno/kyverno-authz/pkg/utils/filecache"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// databases are loaded in memory, lookups in progress keep using the previous reader safely when a database is updated
+var databases = filecache.New(maxminddb.FromBytes, 0)
+
+// Lookup resolves the address in each database, fields found in the first databases take precedence
+func Lookup(paths []string, address string) (*Location, error) {
+	ip, err := ParseIP(address)
+	if err != nil {
+		return nil, err
+	}
+	var location Location
+	for _, path := range paths {
+		reader, err := databases.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		var r record
+		network, ok, err := reader.LookupNetwork(net.IP(ip.AsSlice()), &r)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if !location.Found {
+			location.Found = true
+			location.Network = network.String()
+		}
+		location.merge(&r)
+	}
+	return &location, nil
+}
+
+// ParseIP parses an address, with an optional port
+func ParseIP(address string) (netip.Addr, error) {
+	address = strings.TrimSpace(address)
+	if ip, err := netip.ParseAddr(address); err == nil {
+		return ip.Unmap(), nil
+	}
+	if addrPort, err := netip.ParseAddrPort(address); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	return netip.Addr{}, fmt.Errorf("invalid ip address %q", address)
+}
+
+// ForwardedFor returns the client address from an X-Forwarded-For header, trustedProxies is the number of proxies
+// in front of the gateway that append the address of their client, entries added by the client itself are ignored
+func ForwardedFor(header string, trustedProxies int) (string, error) {
+	if trustedProxies < 1 {
+		return "", errors.New("at least one trusted proxy is required to use the X-Forwarded-For header")
+	}
+	var addresses []string
+	for _, address := range strings.Split(header, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) < trustedProxies {
+		return "", fmt.Errorf("the X-Forwarded-For header has %d addresses, expected at least %d", len(addresses), trustedProxies)
+	}
+	address := addresses[len(addresses)-trustedProxies]
+	if _, err := ParseIP(address); err != nil {
+		return "", err
+	}
+	return address, nil
+}
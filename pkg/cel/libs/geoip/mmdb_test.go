@@ -0,0 +1,143 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/netip"
+	"sort"
+	"testing"
+)
+
+// mmdbWriter builds minimal IPv4 databases, see https://maxmind.github.io/MaxMind-DB
+type mmdbWriter struct {
+	root *trieNode
+	data bytes.Buffer
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	// data holds the data section offset of each child, plus one
+	data [2]int
+}
+
+func newMMDBWriter() *mmdbWriter {
+	return &mmdbWriter{root: &trieNode{}}
+}
+
+func (w *mmdbWriter) insert(t *testing.T, prefix string, value map[string]any) {
+	t.Helper()
+	network := netip.MustParsePrefix(prefix)
+	offset := w.data.Len()
+	encode(&w.data, value)
+	ip := network.Addr().As4()
+	node := w.root
+	for i := range network.Bits() {
+		bit := (ip[i/8] >> (7 - i%8)) & 1
+		if i == network.Bits()-1 {
+			node.data[bit] = offset + 1
+			break
+		}
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{}
+		}
+		node = node.children[bit]
+	}
+}
+
+func (w *mmdbWriter) bytes(databaseType string) []byte {
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	queue := []*trieNode{w.root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		index[node] = len(nodes)
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
+	}
+	var out bytes.Buffer
+	for _, node := range nodes {
+		for bit := range 2 {
+			record := len(nodes)
+			if node.children[bit] != nil {
+				record = index[node.children[bit]]
+			} else if node.data[bit] != 0 {
+				record = len(nodes) + 16 + node.data[bit] - 1
+			}
+			out.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	out.Write(make([]byte, 16))
+	out.Write(w.data.Bytes())
+	out.WriteString("\xab\xcd\xefMaxMind.com")
+	encode(&out, map[string]any{
+		"node_count":                  uint32(len(nodes)),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               databaseType,
+		"languages":                   []any{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(0),
+		"description":                 map[string]any{},
+	})
+	return out.Bytes()
+}
+
+func encode(out *bytes.Buffer, value any) {
+	switch value := value.(type) {
+	case string:
+		control(out, 2, len(value))
+		out.WriteString(value)
+	case float64:
+		control(out, 3, 8)
+		_ = binary.Write(out, binary.BigEndian, math.Float64bits(value))
+	case uint16:
+		control(out, 5, 2)
+		_ = binary.Write(out, binary.BigEndian, value)
+	case uint32:
+		control(out, 6, 4)
+		_ = binary.Write(out, binary.BigEndian, value)
+	case uint64:
+		control(out, 9, 8)
+		_ = binary.Write(out, binary.BigEndian, value)
+	case []any:
+		control(out, 11, len(value))
+		for _, item := range value {
+			encode(out, item)
+		}
+	case map[string]any:
+		control(out, 7, len(value))
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encode(out, key)
+			encode(out, value[key])
+		}
+	default:
+		panic("unsupported type")
+	}
+}
+
+// control writes a control byte, sizes must be lower than 285
+func control(out *bytes.Buffer, kind int, size int) {
+	extra := []byte{}
+	if size >= 29 {
+		extra = append(extra, byte(size-29))
+		size = 29
+	}
+	if kind <= 7 {
+		out.WriteByte(byte(kind<<5 | size))
+	} else {
+		out.Write([]byte{byte(size), byte(kind - 7)})
+	}
+	out.Write(extra)
+}
@@ -0,0 +1,79 @@
+package geoip
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var LocationType = types.NewObjectType("geoip.Location")
+
+// Location is the information found for an address, fields are empty when the database doesn't provide them
+type Location struct {
+	Found             bool    `cel:"found"`
+	Network           string  `cel:"network"`
+	Country           string  `cel:"country"`
+	CountryName       string  `cel:"countryName"`
+	RegisteredCountry string  `cel:"registeredCountry"`
+	Continent         string  `cel:"continent"`
+	Region            string  `cel:"region"`
+	RegionName        string  `cel:"regionName"`
+	City              string  `cel:"city"`
+	Latitude          float64 `cel:"latitude"`
+	Longitude         float64 `cel:"longitude"`
+	TimeZone          string  `cel:"timeZone"`
+	ASN               int64   `cel:"asn"`
+	Organization      string  `cel:"organization"`
+}
+
+// record holds the fields of GeoIP2 and GeoLite2 City, Country and ASN databases
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+		TimeZone  string  `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// merge copies the fields found in the record, fields already set are kept
+func (l *Location) merge(r *record) {
+	set := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	set(&l.Country, r.Country.ISOCode)
+	set(&l.CountryName, r.Country.Names["en"])
+	set(&l.RegisteredCountry, r.RegisteredCountry.ISOCode)
+	set(&l.Continent, r.Continent.Code)
+	if len(r.Subdivisions) > 0 {
+		set(&l.Region, r.Subdivisions[0].ISOCode)
+		set(&l.RegionName, r.Subdivisions[0].Names["en"])
+	}
+	set(&l.City, r.City.Names["en"])
+	set(&l.TimeZone, r.Location.TimeZone)
+	set(&l.Organization, r.AutonomousSystemOrganization)
+	if l.Latitude == 0 && l.Longitude == 0 {
+		l.Latitude, l.Longitude = r.Location.Latitude, r.Location.Longitude
+	}
+	if l.ASN == 0 {
+		l.ASN = int64(r.AutonomousSystemNumber)
+	}
+}
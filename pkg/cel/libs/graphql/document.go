@@ -0,0 +1,535 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxNesting bounds the nesting of selection sets and values, it protects the parser against stack exhaustion
+const maxNesting = 256
+
+// document is a GraphQL executable document, see https://spec.graphql.org/October2021/#sec-Executable-Definitions
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string
+	name       string
+	selections []selection
+}
+
+type fragment struct {
+	name       string
+	selections []selection
+}
+
+// selection is either a field, a fragment spread or an inline fragment
+type selection struct {
+	name       string
+	alias      string
+	arguments  []string
+	spread     string
+	inline     bool
+	selections []selection
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenNumber
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	start := l.pos
+	if start == len(l.src) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+	c := l.src[start]
+	switch {
+	case c == '.':
+		if !strings.HasPrefix(l.src[start:], "...") {
+			return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+		}
+		l.pos += 3
+		return token{kind: tokenPunctuator, value: "...", pos: start}, nil
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && strings.IndexByte("0123456789.eE+-", l.src[l.pos]) >= 0 {
+			l.pos++
+		}
+		if !strings.ContainsAny(l.src[start:l.pos], "0123456789") {
+			return token{}, fmt.Errorf("invalid number at offset %d", start)
+		}
+		return token{kind: tokenNumber, value: l.src[start:l.pos], pos: start}, nil
+	case strings.HasPrefix(l.src[start:], `"""`):
+		l.pos += 3
+		for {
+			switch {
+			case l.pos >= len(l.src):
+				return token{}, fmt.Errorf("unterminated block string at offset %d", start)
+			case strings.HasPrefix(l.src[l.pos:], `\"""`):
+				l.pos += 4
+			case strings.HasPrefix(l.src[l.pos:], `"""`):
+				l.pos += 3
+				return token{kind: tokenString, value: l.src[start:l.pos], pos: start}, nil
+			default:
+				l.pos++
+			}
+		}
+	case c == '"':
+		l.pos++
+		for {
+			switch {
+			case l.pos >= len(l.src) || l.src[l.pos] == '\n' || l.src[l.pos] == '\r':
+				return token{}, fmt.Errorf("unterminated string at offset %d", start)
+			case l.src[l.pos] == '\\':
+				l.pos += 2
+			case l.src[l.pos] == '"':
+				l.pos++
+				return token{kind: tokenString, value: l.src[start:l.pos], pos: start}, nil
+			default:
+				l.pos++
+			}
+		}
+	default:
+		return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	lexer
+	tok     token
+	nesting int
+}
+
+func parseDocument(query string) (*document, error) {
+	p := &parser{lexer: lexer{src: query}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &document{
+		fragments: map[string]*fragment{},
+	}
+	for p.tok.kind != tokenEOF {
+		// a lone selection set is an anonymous query
+		if p.peek("{") {
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: OperationQuery, selections: selections})
+			continue
+		}
+		if p.tok.kind != tokenName {
+			return nil, p.unexpected()
+		}
+		switch p.tok.value {
+		case OperationQuery, OperationMutation, OperationSubscription:
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, fmt.Errorf("fragment %q is defined more than once", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, fmt.Errorf("unsupported definition %q, only operations and fragments are allowed", p.tok.value)
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, errors.New("graphql document contains no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == punctuator
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return errors.New("unexpected end of graphql document")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", p.tok.value, p.tok.pos)
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(keyword string) error {
+	if p.tok.kind != tokenName || p.tok.value != keyword {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) nest() error {
+	p.nesting++
+	if p.nesting > maxNesting {
+		return fmt.Errorf("graphql document exceeds the maximum nesting of %d", maxNesting)
+	}
+	return nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		op.name = name
+	}
+	if p.peek("(") {
+		if err := p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	if err := p.keyword("fragment"); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value == "on" {
+		return nil, p.unexpected()
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.keyword("on"); err != nil {
+		return nil, err
+	}
+	if _, err := p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, selections: selections}, nil
+}
+
+func (p *parser) variableDefinitions() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if p.peek("=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if err := p.value(); err != nil {
+				return err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return err
+		}
+		if p.peek(")") {
+			return p.advance()
+		}
+	}
+}
+
+func (p *parser) typeRef() error {
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.nest(); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		p.nesting--
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peek("!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) directives() error {
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.peek("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) arguments() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var arguments []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if err := p.value(); err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, name)
+		if p.peek(")") {
+			return arguments, p.advance()
+		}
+	}
+}
+
+// value skips over a value, policies only look at argument names
+func (p *parser) value() error {
+	switch {
+	case p.peek("$"):
+		if err := p.advance(); err != nil {
+			return err
+		}
+		_, err := p.name()
+		return err
+	case p.peek("["):
+		if err := p.nest(); err != nil {
+			return err
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		for !p.peek("]") {
+			if err := p.value(); err != nil {
+				return err
+			}
+		}
+		p.nesting--
+		return p.advance()
+	case p.peek("{"):
+		if err := p.nest(); err != nil {
+			return err
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		for !p.peek("}") {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+			if err := p.value(); err != nil {
+				return err
+			}
+		}
+		p.nesting--
+		return p.advance()
+	case p.tok.kind == tokenName || p.tok.kind == tokenNumber || p.tok.kind == tokenString:
+		return p.advance()
+	default:
+		return p.unexpected()
+	}
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+		if p.peek("}") {
+			p.nesting--
+			return selections, p.advance()
+		}
+	}
+}
+
+func (p *parser) selection() (selection, error) {
+	var sel selection
+	if p.peek("...") {
+		if err := p.advance(); err != nil {
+			return sel, err
+		}
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			sel.spread = p.tok.value
+			if err := p.advance(); err != nil {
+				return sel, err
+			}
+			return sel, p.directives()
+		}
+		sel.inline = true
+		if p.tok.kind == tokenName {
+			if err := p.advance(); err != nil {
+				return sel, err
+			}
+			if _, err := p.name(); err != nil {
+				return sel, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return sel, err
+		}
+		selections, err := p.selectionSet()
+		sel.selections = selections
+		return sel, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return sel, err
+	}
+	sel.name = name
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return sel, err
+		}
+		if sel.name, err = p.name(); err != nil {
+			return sel, err
+		}
+		sel.alias = name
+	}
+	if p.peek("(") {
+		if sel.arguments, err = p.arguments(); err != nil {
+			return sel, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return sel, err
+	}
+	if p.peek("{") {
+		if sel.selections, err = p.selectionSet(); err != nil {
+			return sel, err
+		}
+	}
+	return sel, nil
+}
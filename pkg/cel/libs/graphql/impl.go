@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse_string(content ref.Val) ref.Val {
+	if content, err := utils.ConvertToNative[string](content); err != nil {
+		return types.WrapErr(err)
+	} else if request, err := Parse([]byte(content)); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(request)
+	}
+}
+
+func (c *impl) parse_query_string(query ref.Val) ref.Val {
+	return c.parse_query_string_string(query, types.String(""))
+}
+
+func (c *impl) parse_query_string_string(query ref.Val, operationName ref.Val) ref.Val {
+	if query, err := utils.ConvertToNative[string](query); err != nil {
+		return types.WrapErr(err)
+	} else if operationName, err := utils.ConvertToNative[string](operationName); err != nil {
+		return types.WrapErr(err)
+	} else if request, err := ParseQuery(query, operationName); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(request)
+	}
+}
+
+func (c *impl) request_has_field_string(request ref.Val, name ref.Val) ref.Val {
+	if request, err := utils.ConvertToNative[*Request](request); err != nil {
+		return types.WrapErr(err)
+	} else if name, err := utils.ConvertToNative[string](name); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(request.HasField(name))
+	}
+}
@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.graphql"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Request](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// operation types are exposed as constants
+	constants := map[string]string{
+		"QueryOperation":        OperationQuery,
+		"MutationOperation":     OperationMutation,
+		"SubscriptionOperation": OperationSubscription,
+	}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"graphql.Parse": {
+			cel.Overload("graphql_parse_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parse_string)),
+		},
+		"graphql.ParseQuery": {
+			cel.Overload("graphql_parse_query_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parse_query_string)),
+			cel.Overload("graphql_parse_query_string_string", []*cel.Type{types.StringType, types.StringType}, RequestType, cel.BinaryBinding(impl.parse_query_string_string)),
+		},
+		"hasField": {
+			cel.MemberOverload("graphql_request_has_field_string", []*cel.Type{RequestType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.request_has_field_string)),
+		},
+	}
+	// create env options corresponding to our constants and function overloads
+	options := []cel.EnvOption{}
+	for name, value := range constants {
+		options = append(options, cel.Constant("graphql."+name, types.StringType, types.String(value)))
+	}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLib(t *testing.T) {
+	const (
+		query = `{
+			"query": "query GetUser($id: ID!, $withPosts: Boolean = false) { me: user(id: $id) { name email ...Posts @include(if: $withPosts) } viewer { id } } fragment Posts on User { posts(first: 10) { title comments { body } } }",
+			"operationName": "GetUser",
+			"variables": { "id": "42" }
+		}`
+		mutation = `{
+			"query": "query Read { user { name } } mutation Write { deleteUser(id: 1) { id } }",
+			"operationName": "Write"
+		}`
+	)
+	tests := []struct {
+		name    string
+		source  string
+		body    string
+		want    any
+		wantErr bool
+	}{{
+		name:   "operation",
+		source: `graphql.Parse(body).operationType + " " + graphql.Parse(body).operationName`,
+		body:   query,
+		want:   "query GetUser",
+	}, {
+		name:   "root fields",
+		source: `graphql.Parse(body).fields.map(f, f.name)`,
+		body:   query,
+		want:   []string{"user", "viewer"},
+	}, {
+		name:   "root field alias and arguments",
+		source: `graphql.Parse(body).fields[0].alias + " " + graphql.Parse(body).fields[0].arguments[0]`,
+		body:   query,
+		want:   "me id",
+	}, {
+		name:   "has field",
+		source: `graphql.Parse(body).hasField("user") && !graphql.Parse(body).hasField("me")`,
+		body:   query,
+		want:   true,
+	}, {
+		name:   "field names",
+		source: `graphql.Parse(body).fieldNames`,
+		body:   query,
+		want:   []string{"body", "comments", "email", "id", "name", "posts", "title", "user", "viewer"},
+	}, {
+		name:   "depth",
+		source: `graphql.Parse(body).depth`,
+		body:   query,
+		want:   int64(4),
+	}, {
+		name:   "complexity",
+		source: `graphql.Parse(body).complexity`,
+		body:   query,
+		want:   int64(9),
+	}, {
+		name:   "aliases",
+		source: `graphql.Parse(body).aliases`,
+		body:   query,
+		want:   int64(1),
+	}, {
+		name:   "variables",
+		source: `graphql.Parse(body).variables`,
+		body:   query,
+		want:   `{ "id": "42" }`,
+	}, {
+		name:   "selected operation",
+		source: `graphql.Parse(body).operationType == graphql.MutationOperation && graphql.Parse(body).hasField("deleteUser")`,
+		body:   mutation,
+		want:   true,
+	}, {
+		name:   "default variables",
+		source: `graphql.Parse(body).variables`,
+		body:   mutation,
+		want:   "{}",
+	}, {
+		name:   "anonymous query",
+		source: `graphql.ParseQuery(body).operationType == graphql.QueryOperation && graphql.ParseQuery(body).fields.size() == 2`,
+		body:   `{ a, b { c } }`,
+		want:   true,
+	}, {
+		name:   "query with operation name",
+		source: `graphql.ParseQuery(body, "Read").fields[0].name`,
+		body:   `query Read { user { name } } mutation Write { deleteUser(id: 1) { id } }`,
+		want:   "user",
+	}, {
+		name:   "root fragments",
+		source: `graphql.ParseQuery(body).fields.map(f, f.name)`,
+		body:   `query { ...A ...A ... on Query { c } } fragment A on Query { a ...B } fragment B on Query { b }`,
+		want:   []string{"a", "b", "c"},
+	}, {
+		name:   "repeated fragments",
+		source: `graphql.ParseQuery(body).complexity`,
+		body:   `{ ...A ...A } fragment A on Query { a { ...B ...B } } fragment B on A { b c }`,
+		want:   int64(10),
+	}, {
+		name:   "strings and comments",
+		source: `graphql.ParseQuery(body).fields.map(f, f.name)`,
+		body: `# comment
+			{ a(s: "x } y", b: """ { "quoted" \""" """, o: { l: [1, -2.5e3, ENUM, null] }) b }`,
+		want: []string{"a", "b"},
+	}, {
+		name:    "missing operation name",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `query A { a } query B { b }`,
+		wantErr: true,
+	}, {
+		name:    "unknown operation",
+		source:  `graphql.ParseQuery(body, "C")`,
+		body:    `query A { a }`,
+		wantErr: true,
+	}, {
+		name:    "unknown fragment",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `{ ...A }`,
+		wantErr: true,
+	}, {
+		name:    "fragment cycle",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `{ ...A } fragment A on Query { a { ...B } } fragment B on Query { ...A }`,
+		wantErr: true,
+	}, {
+		name:    "type definitions",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `type Query { a: String }`,
+		wantErr: true,
+	}, {
+		name:    "syntax error",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `{ a(b: ) }`,
+		wantErr: true,
+	}, {
+		name:    "unterminated",
+		source:  `graphql.ParseQuery(body)`,
+		body:    `{ a { b }`,
+		wantErr: true,
+	}, {
+		name:    "too deep",
+		source:  `graphql.ParseQuery(body)`,
+		body:    strings.Repeat("{ a ", 300) + strings.Repeat("}", 300),
+		wantErr: true,
+	}, {
+		name:    "no query",
+		source:  `graphql.Parse(body)`,
+		body:    `{ "variables": {} }`,
+		wantErr: true,
+	}, {
+		name:    "invalid body",
+		source:  `graphql.Parse(body)`,
+		body:    `{`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("body", cel.StringType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"body": tt.body})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if want, ok := tt.want.([]string); ok {
+					got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				} else {
+					assert.Equal(t, tt.want, out.Value())
+				}
+			}
+		})
+	}
+}
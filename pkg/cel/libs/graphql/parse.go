@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// Parse parses a GraphQL over HTTP json request body, see https://graphql.github.io/graphql-over-http/draft/#sec-JSON-Encoding
+func Parse(content []byte) (*Request, error) {
+	var body struct {
+		Query         string  `json:"query"`
+		OperationName string  `json:"operationName"`
+		Variables     rawJSON `json:"variables"`
+	}
+	if err := json.Unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	if body.Query == "" {
+		return nil, errors.New("graphql request has no query")
+	}
+	request, err := ParseQuery(body.Query, body.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	if body.Variables != "" && body.Variables != "null" {
+		request.Variables = body.Variables
+	}
+	return request, nil
+}
+
+// ParseQuery parses a GraphQL document and analyzes the operation with the given name,
+// the name can be empty when the document contains a single operation
+func ParseQuery(query string, operationName string) (*Request, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return nil, err
+	}
+	op, err := doc.operation(operationName)
+	if err != nil {
+		return nil, err
+	}
+	a := analyzer{
+		fragments: doc.fragments,
+		stats:     map[string]*stats{},
+		visiting:  map[string]bool{},
+	}
+	stats, err := a.selections(op.selections)
+	if err != nil {
+		return nil, err
+	}
+	request := &Request{
+		Query:         query,
+		OperationType: op.kind,
+		OperationName: op.name,
+		FieldNames:    slices.Sorted(maps.Keys(stats.names)),
+		Aliases:       stats.aliases,
+		Depth:         stats.depth,
+		Complexity:    stats.complexity,
+		Variables:     "{}",
+	}
+	request.Fields = rootFields(doc.fragments, op.selections, map[string]bool{}, nil)
+	return request, nil
+}
+
+// operation returns the operation to execute, see https://spec.graphql.org/October2021/#GetOperation()
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) != 1 {
+			return nil, errors.New("operation name is required when the document contains multiple operations")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation %q not found", name)
+}
+
+type stats struct {
+	depth      int
+	complexity int
+	aliases    int
+	names      map[string]struct{}
+}
+
+func (s *stats) merge(other *stats) {
+	s.depth = max(s.depth, other.depth)
+	s.complexity = add(s.complexity, other.complexity)
+	s.aliases = add(s.aliases, other.aliases)
+	maps.Copy(s.names, other.names)
+}
+
+// add saturates instead of overflowing, nested fragment spreads grow exponentially
+func add(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// analyzer computes selection stats, fragment stats are computed once and reused for every spread
+type analyzer struct {
+	fragments map[string]*fragment
+	stats     map[string]*stats
+	visiting  map[string]bool
+}
+
+func (a *analyzer) selections(selections []selection) (*stats, error) {
+	out := &stats{names: map[string]struct{}{}}
+	for _, sel := range selections {
+		switch {
+		case sel.spread != "":
+			frag, err := a.fragment(sel.spread)
+			if err != nil {
+				return nil, err
+			}
+			out.merge(frag)
+		case sel.inline:
+			inline, err := a.selections(sel.selections)
+			if err != nil {
+				return nil, err
+			}
+			out.merge(inline)
+		default:
+			field, err := a.selections(sel.selections)
+			if err != nil {
+				return nil, err
+			}
+			field.depth++
+			field.complexity = add(field.complexity, 1)
+			if sel.alias != "" {
+				field.aliases = add(field.aliases, 1)
+			}
+			field.names[sel.name] = struct{}{}
+			out.merge(field)
+		}
+	}
+	return out, nil
+}
+
+func (a *analyzer) fragment(name string) (*stats, error) {
+	if stats, ok := a.stats[name]; ok {
+		return stats, nil
+	}
+	if a.visiting[name] {
+		return nil, fmt.Errorf("fragment %q spreads itself", name)
+	}
+	frag, ok := a.fragments[name]
+	if !ok {
+		return nil, fmt.Errorf("fragment %q is not defined", name)
+	}
+	a.visiting[name] = true
+	stats, err := a.selections(frag.selections)
+	if err != nil {
+		return nil, err
+	}
+	a.visiting[name] = false
+	a.stats[name] = stats
+	return stats, nil
+}
+
+// rootFields expands fragments at the root of the operation, fragments are known to exist and not to cycle at this point
+func rootFields(fragments map[string]*fragment, selections []selection, spread map[string]bool, fields []Field) []Field {
+	for _, sel := range selections {
+		switch {
+		case sel.spread != "":
+			// spreading the same fragment twice selects the same fields
+			if !spread[sel.spread] {
+				spread[sel.spread] = true
+				fields = rootFields(fragments, fragments[sel.spread].selections, spread, fields)
+			}
+		case sel.inline:
+			fields = rootFields(fragments, sel.selections, spread, fields)
+		default:
+			fields = append(fields, Field{
+				Name:      sel.name,
+				Alias:     sel.alias,
+				Arguments: sel.arguments,
+			})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	RequestType = types.NewObjectType("graphql.Request")
+	FieldType   = types.NewObjectType("graphql.Field")
+)
+
+// GraphQL operation types
+const (
+	OperationQuery        = "query"
+	OperationMutation     = "mutation"
+	OperationSubscription = "subscription"
+)
+
+// rawJSON holds a json value as is, policies decode it with json.Unmarshal when needed
+type rawJSON string
+
+func (r *rawJSON) UnmarshalJSON(data []byte) error {
+	*r = rawJSON(data)
+	return nil
+}
+
+// Request is the operation selected for execution in a GraphQL document
+type Request struct {
+	Query         string `cel:"query"`
+	OperationType string `cel:"operationType"`
+	OperationName string `cel:"operationName"`
+	// Fields are the root fields selected by the operation, with fragments expanded
+	Fields []Field `cel:"fields"`
+	// FieldNames are the distinct names of all the fields selected at any depth
+	FieldNames []string `cel:"fieldNames"`
+	// Aliases is the number of aliased fields selected at any depth
+	Aliases int `cel:"aliases"`
+	// Depth is the maximum nesting of fields, root fields are at depth 1
+	Depth int `cel:"depth"`
+	// Complexity is the total number of fields that will be resolved, counting every fragment spread
+	Complexity int     `cel:"complexity"`
+	Variables  rawJSON `cel:"variables"`
+}
+
+// Field is a root field of an operation
+type Field struct {
+	Name      string   `cel:"name"`
+	Alias     string   `cel:"alias"`
+	Arguments []string `cel:"arguments"`
+}
+
+// HasField returns true if the operation selects a root field with the given name
+func (r *Request) HasField(name string) bool {
+	for _, field := range r.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+	descriptors *Descriptors
+}
+
+func (c *impl) parse_path_string(path ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else if method, err := ParsePath(path); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(method)
+	}
+}
+
+func (c *impl) decode_bytes_string(body ref.Val, name ref.Val) ref.Val {
+	if body, err := utils.ConvertToNative[[]byte](body); err != nil {
+		return types.WrapErr(err)
+	} else if name, err := utils.ConvertToNative[string](name); err != nil {
+		return types.WrapErr(err)
+	} else if desc, err := c.descriptors.FindMessage(name); err != nil {
+		return types.WrapErr(err)
+	} else if message, err := Decode(body, desc); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(message)
+	}
+}
+
+func (c *impl) decode_request_string_bytes(path ref.Val, body ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else if body, err := utils.ConvertToNative[[]byte](body); err != nil {
+		return types.WrapErr(err)
+	} else if method, err := ParsePath(path); err != nil {
+		return types.WrapErr(err)
+	} else if desc, err := c.descriptors.FindMethod(method); err != nil {
+		return types.WrapErr(err)
+	} else if message, err := Decode(body, desc.Input()); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(message)
+	}
+}
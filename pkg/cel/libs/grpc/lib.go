@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct {
+	descriptors *Descriptors
+}
+
+func Lib(descriptors *Descriptors) cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{
+		descriptors: descriptors,
+	})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.grpc"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register message types from the configured descriptors,
+		// this must happen before native types replace the type provider
+		cel.TypeDescs(c.descriptors.Files()),
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Method](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter and descriptors
+	impl := impl{adapter, c.descriptors}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"grpc.ParsePath": {
+			cel.Overload("grpc_parse_path_string", []*cel.Type{types.StringType}, MethodType, cel.UnaryBinding(impl.parse_path_string)),
+		},
+		"grpc.Decode": {
+			cel.Overload("grpc_decode_bytes_string", []*cel.Type{types.BytesType, types.StringType}, types.DynType, cel.BinaryBinding(impl.decode_bytes_string)),
+		},
+		"grpc.DecodeRequest": {
+			cel.Overload("grpc_decode_request_string_bytes", []*cel.Type{types.StringType, types.BytesType}, types.DynType, cel.BinaryBinding(impl.decode_request_string_bytes)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func frame(t *testing.T, message proto.Message) []byte {
+	t.Helper()
+	payload, err := proto.Marshal(message)
+	assert.NoError(t, err)
+	out := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(out[1:5], uint32(len(payload)))
+	return append(out, payload...)
+}
+
+func TestLib(t *testing.T) {
+	descriptors, err := NewDescriptors(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+		},
+	})
+	assert.NoError(t, err)
+	body := frame(t, &healthpb.HealthCheckRequest{Service: "tenant-a"})
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "parse path",
+		source: `grpc.ParsePath("/grpc.health.v1.Health/Check")`,
+		want:   Method{Package: "grpc.health.v1", Service: "grpc.health.v1.Health", Name: "Check"},
+	}, {
+		name:   "parse path service",
+		source: `grpc.ParsePath("/grpc.health.v1.Health/Check").service`,
+		want:   "grpc.health.v1.Health",
+	}, {
+		name:    "parse invalid path",
+		source:  `grpc.ParsePath("/grpc.health.v1.Health")`,
+		wantErr: true,
+	}, {
+		name:   "decode",
+		source: `grpc.Decode(body, "grpc.health.v1.HealthCheckRequest").service`,
+		want:   "tenant-a",
+	}, {
+		name:   "decode request",
+		source: `grpc.DecodeRequest("/grpc.health.v1.Health/Check", body).service`,
+		want:   "tenant-a",
+	}, {
+		name:   "typed message",
+		source: `type(grpc.DecodeRequest("/grpc.health.v1.Health/Check", body)) == grpc.health.v1.HealthCheckRequest`,
+		want:   true,
+	}, {
+		name:    "unknown method",
+		source:  `grpc.DecodeRequest("/grpc.health.v1.Health/Unknown", body)`,
+		wantErr: true,
+	}, {
+		name:    "unknown message",
+		source:  `grpc.Decode(body, "grpc.health.v1.Unknown")`,
+		wantErr: true,
+	}, {
+		name:    "missing frame",
+		source:  `grpc.Decode(b"", "grpc.health.v1.HealthCheckRequest")`,
+		wantErr: true,
+	}, {
+		name:    "compressed frame",
+		source:  `grpc.Decode(b"\x01\x00\x00\x00\x00", "grpc.health.v1.HealthCheckRequest")`,
+		wantErr: true,
+	}, {
+		name:    "truncated frame",
+		source:  `grpc.Decode(b"\x00\x00\x00\x00\x0a\x0a", "grpc.health.v1.HealthCheckRequest")`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(
+				Lib(descriptors),
+				cel.Variable("body", cel.BytesType),
+			)
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{
+				"body": body,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.Value())
+		})
+	}
+}
+
+func TestLibWithoutDescriptors(t *testing.T) {
+	env, err := cel.NewEnv(Lib(nil))
+	assert.NoError(t, err)
+	ast, issues := env.Compile(`grpc.ParsePath("/grpc.health.v1.Health/Check").name`)
+	assert.NoError(t, issues.Err())
+	prog, err := env.Program(ast)
+	assert.NoError(t, err)
+	out, _, err := prog.Eval(map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Check", out.Value())
+}
+
+func TestLoadDescriptors(t *testing.T) {
+	content, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+		},
+	})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "health.binpb"), content, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("invalid"), 0o600))
+	for _, path := range []string{dir, filepath.Join(dir, "health.binpb")} {
+		descriptors, err := LoadDescriptors(path)
+		assert.NoError(t, err)
+		_, err = descriptors.FindMethod(Method{Service: "grpc.health.v1.Health", Name: "Watch"})
+		assert.NoError(t, err)
+	}
+	_, err = LoadDescriptors(filepath.Join(dir, ".hidden"))
+	assert.Error(t, err)
+}
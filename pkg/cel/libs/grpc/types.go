@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var MethodType = types.NewObjectType("grpc.Method")
+
+// Method is a gRPC method parsed from a request path of the form /<package>.<Service>/<Method>
+type Method struct {
+	Package string `cel:"package"`
+	Service string `cel:"service"`
+	Name    string `cel:"name"`
+}
+
+func ParsePath(path string) (Method, error) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok || !strings.HasPrefix(path, "/") || service == "" || name == "" || strings.Contains(name, "/") {
+		return Method{}, fmt.Errorf("invalid grpc path %q: expected /<service>/<method>", path)
+	}
+	pkg := ""
+	if i := strings.LastIndexByte(service, '.'); i >= 0 {
+		pkg = service[:i]
+	}
+	return Method{
+		Package: pkg,
+		Service: service,
+		Name:    name,
+	}, nil
+}
+
+// Descriptors holds the protobuf file descriptors used to resolve gRPC methods and messages
+type Descriptors struct {
+	files *protoregistry.Files
+}
+
+func NewDescriptors(sets ...*descriptorpb.FileDescriptorSet) (*Descriptors, error) {
+	// merge all sets, the same file can be included in more than one set
+	merged := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]struct{}{}
+	for _, set := range sets {
+		for _, file := range set.GetFile() {
+			if _, ok := seen[file.GetName()]; ok {
+				continue
+			}
+			seen[file.GetName()] = struct{}{}
+			merged.File = append(merged.File, file)
+		}
+	}
+	files, err := protodesc.NewFiles(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build file descriptors: %w", err)
+	}
+	return &Descriptors{files: files}, nil
+}
+
+// LoadDescriptors reads binary encoded FileDescriptorSet files, as produced by
+// `protoc --include_imports --descriptor_set_out` or `buf build -o`.
+// When a path is a directory, all the files it contains are loaded (hidden files are ignored).
+func LoadDescriptors(paths ...string) (*Descriptors, error) {
+	var sets []*descriptorpb.FileDescriptorSet
+	for _, path := range paths {
+		files, err := expand(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			var set descriptorpb.FileDescriptorSet
+			if err := proto.Unmarshal(content, &set); err != nil {
+				return nil, fmt.Errorf("failed to parse file descriptor set %s: %w", file, err)
+			}
+			sets = append(sets, &set)
+		}
+	}
+	return NewDescriptors(sets...)
+}
+
+func expand(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		// skip hidden entries, this includes the ..data links created when mounting config maps
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(path, entry.Name())
+		// stat follows symlinks
+		if info, err := os.Stat(file); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (d *Descriptors) Files() *protoregistry.Files {
+	if d == nil || d.files == nil {
+		return new(protoregistry.Files)
+	}
+	return d.files
+}
+
+func (d *Descriptors) FindMessage(name string) (protoreflect.MessageDescriptor, error) {
+	desc, err := d.Files().FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("message type %q not found: %w", name, err)
+	}
+	message, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", name)
+	}
+	return message, nil
+}
+
+func (d *Descriptors) FindMethod(method Method) (protoreflect.MethodDescriptor, error) {
+	desc, err := d.Files().FindDescriptorByName(protoreflect.FullName(method.Service))
+	if err != nil {
+		return nil, fmt.Errorf("service %q not found: %w", method.Service, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", method.Service)
+	}
+	m := service.Methods().ByName(protoreflect.Name(method.Name))
+	if m == nil {
+		return nil, fmt.Errorf("method %q not found in service %q", method.Name, method.Service)
+	}
+	return m, nil
+}
+
+// Decode unmarshals the first gRPC length-prefixed message found in body
+func Decode(body []byte, desc protoreflect.MessageDescriptor) (proto.Message, error) {
+	payload, err := unframe(body)
+	if err != nil {
+		return nil, err
+	}
+	message := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("failed to decode %s message: %w", desc.FullName(), err)
+	}
+	return message, nil
+}
+
+func unframe(body []byte) ([]byte, error) {
+	// a grpc frame is made of a compressed flag (1 byte), the message length (4 bytes) and the message
+	if len(body) < 5 {
+		return nil, errors.New("invalid grpc message: frame header is missing")
+	}
+	if body[0] != 0 {
+		return nil, errors.New("invalid grpc message: compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint64(len(body)-5) < uint64(length) {
+		return nil, errors.New("invalid grpc message: message is truncated")
+	}
+	return body[5 : 5+length], nil
+}
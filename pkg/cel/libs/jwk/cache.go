@@ -0,0 +1,178 @@
+package jwk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/crypto"
+	"github.com/kyverno/kyverno-authz/pkg/utils/filecache"
+	"github.com/lestrrat-go/httprc/v3"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+// CacheOptions configures how key sets are cached and refreshed
+type CacheOptions struct {
+	// MinRefreshInterval and MaxRefreshInterval bound background refreshes,
+	// Cache-Control and Expires response headers are honored within these bounds
+	MinRefreshInterval time.Duration
+	MaxRefreshInterval time.Duration
+	// Timeout bounds the fetches made while a policy is evaluated
+	Timeout time.Duration
+	// KidMissBackoff is the initial delay between two refreshes triggered by an unknown key id,
+	// it doubles up to MinRefreshInterval as long as no new key shows up
+	KidMissBackoff time.Duration
+}
+
+var DefaultCacheOptions = CacheOptions{
+	MinRefreshInterval: 15 * time.Minute,
+	MaxRefreshInterval: 24 * time.Hour,
+	Timeout:            10 * time.Second,
+	KidMissBackoff:     10 * time.Second,
+}
+
+// Cache keeps key sets in memory, remote sets are refreshed in the background and files are reloaded when they change
+type Cache struct {
+	options CacheOptions
+	remote  *jwk.Cache
+	lock    sync.Mutex
+	sources map[string]*source
+	files   *filecache.Cache[jwk.Set]
+}
+
+type source struct {
+	lock       sync.Mutex
+	registered bool
+	next       time.Time
+	backoff    time.Duration
+}
+
+var defaultCache = sync.OnceValues(func() (*Cache, error) {
+	return NewCache(context.Background(), DefaultCacheOptions)
+})
+
+// Fetch returns the key set at the given url from the process wide cache
+func Fetch(ctx context.Context, url string) (jwk.Set, error) {
+	cache, err := defaultCache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.Fetch(ctx, url)
+}
+
+// NewCache creates a cache, background refreshes stop when the context is done
+func NewCache(ctx context.Context, options CacheOptions) (*Cache, error) {
+	remote, err := jwk.NewCache(ctx, httprc.NewClient())
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		options: options,
+		remote:  remote,
+		sources: map[string]*source{},
+		files:   filecache.New(ParseSet, 0),
+	}, nil
+}
+
+// Fetch returns the key set at the given url, file:// urls are read from the local file system
+// and must be under the secrets root, like the files read by crypto.ReadSecret
+func (c *Cache) Fetch(ctx context.Context, url string) (jwk.Set, error) {
+	if path, ok := strings.CutPrefix(url, "file://"); ok {
+		path, err := crypto.ResolveSecret(path)
+		if err != nil {
+			return nil, err
+		}
+		return c.files.Load(path)
+	}
+	source := c.source(url)
+	set, err := c.lookup(ctx, url, source)
+	if err != nil {
+		return nil, err
+	}
+	return &refreshingSet{keySet: set, cache: c, url: url, source: source}, nil
+}
+
+func (c *Cache) source(url string) *source {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	s, ok := c.sources[url]
+	if !ok {
+		s = &source{}
+		c.sources[url] = s
+	}
+	return s
+}
+
+func (c *Cache) lookup(ctx context.Context, url string, source *source) (jwk.Set, error) {
+	source.lock.Lock()
+	if !source.registered {
+		ctx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+		// callers of the same url wait for the first fetch instead of fetching concurrently
+		if err := c.remote.Register(ctx, url, jwk.WithMinInterval(c.options.MinRefreshInterval), jwk.WithMaxInterval(c.options.MaxRefreshInterval)); err != nil {
+			// the url stays registered when the first fetch fails or times out
+			source.registered = c.remote.IsRegistered(context.Background(), url)
+			source.lock.Unlock()
+			return nil, err
+		}
+		source.registered = true
+	}
+	source.lock.Unlock()
+	set, err := c.remote.Lookup(ctx, url)
+	if err != nil {
+		// the first fetch failed, retry instead of waiting for the next background refresh
+		return c.refresh(ctx, url, source)
+	}
+	return set, nil
+}
+
+// refresh fetches the key set now, unless it was already forced to recently
+func (c *Cache) refresh(ctx context.Context, url string, source *source) (jwk.Set, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+	now := time.Now()
+	if now.Before(source.next) {
+		return nil, fmt.Errorf("key set %q was refreshed less than %s ago", url, source.backoff)
+	}
+	if source.backoff == 0 {
+		source.backoff = c.options.KidMissBackoff
+	} else {
+		source.backoff = min(2*source.backoff, c.options.MinRefreshInterval)
+	}
+	source.next = now.Add(source.backoff)
+	ctx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+	return c.remote.Refresh(ctx, url)
+}
+
+// keySet lets refreshingSet embed jwk.Set, whose Set method conflicts with the embedded field name
+type keySet = jwk.Set
+
+// refreshingSet refreshes the key set when a token is signed with an unknown key id,
+// identity providers may rotate keys before the cached set expires
+type refreshingSet struct {
+	keySet
+	cache  *Cache
+	url    string
+	source *source
+}
+
+func (s *refreshingSet) LookupKeyID(kid string) (jwk.Key, bool) {
+	if key, ok := s.keySet.LookupKeyID(kid); ok {
+		return key, true
+	}
+	set, err := s.cache.refresh(context.Background(), s.url, s.source)
+	if err != nil {
+		return nil, false
+	}
+	key, ok := set.LookupKeyID(kid)
+	if ok {
+		// a new key showed up, the next unknown key id will be looked up after the initial backoff
+		s.source.lock.Lock()
+		s.source.backoff = 0
+		s.source.lock.Unlock()
+	}
+	return key, ok
+}
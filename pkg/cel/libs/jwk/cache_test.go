@@ -0,0 +1,164 @@
+package jwk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/crypto"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeySet(t *testing.T, kids ...string) []byte {
+	t.Helper()
+	set := jwk.NewSet()
+	for _, kid := range kids {
+		raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		key, err := jwk.PublicKeyOf(raw)
+		require.NoError(t, err)
+		require.NoError(t, key.Set(jwk.KeyIDKey, kid))
+		require.NoError(t, set.AddKey(key))
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+type keyServer struct {
+	lock   sync.Mutex
+	hits   int
+	status int
+	keys   []byte
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.hits++
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	_, _ = w.Write(s.keys)
+}
+
+func (s *keyServer) update(status int, keys []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.status = status
+	s.keys = keys
+}
+
+func (s *keyServer) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.hits
+}
+
+func newTestCache(t *testing.T) *Cache {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cache, err := NewCache(ctx, CacheOptions{
+		MinRefreshInterval: time.Hour,
+		MaxRefreshInterval: time.Hour,
+		Timeout:            time.Second,
+		KidMissBackoff:     time.Hour,
+	})
+	require.NoError(t, err)
+	return cache
+}
+
+func TestCache_Fetch(t *testing.T) {
+	server := &keyServer{keys: newKeySet(t, "a")}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	cache := newTestCache(t)
+	for range 3 {
+		set, err := cache.Fetch(context.Background(), srv.URL)
+		require.NoError(t, err)
+		_, ok := set.LookupKeyID("a")
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 1, server.count())
+}
+
+func TestCache_KidMiss(t *testing.T) {
+	server := &keyServer{keys: newKeySet(t, "a")}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	cache := newTestCache(t)
+	set, err := cache.Fetch(context.Background(), srv.URL)
+	require.NoError(t, err)
+	// keys are rotated, the unknown kid triggers a refresh
+	server.update(0, newKeySet(t, "b"))
+	_, ok := set.LookupKeyID("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, server.count())
+	// the next unknown kid waits for the backoff
+	_, ok = set.LookupKeyID("c")
+	assert.False(t, ok)
+	assert.Equal(t, 2, server.count())
+	set, err = cache.Fetch(context.Background(), srv.URL)
+	require.NoError(t, err)
+	_, ok = set.LookupKeyID("b")
+	assert.True(t, ok)
+}
+
+func TestCache_FirstFetchFailure(t *testing.T) {
+	server := &keyServer{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	cache := newTestCache(t)
+	cache.options.Timeout = 100 * time.Millisecond
+	_, err := cache.Fetch(context.Background(), srv.URL)
+	assert.Error(t, err)
+	// the next evaluation retries instead of waiting for the background refresh
+	server.update(0, newKeySet(t, "a"))
+	set, err := cache.Fetch(context.Background(), srv.URL)
+	require.NoError(t, err)
+	_, ok := set.LookupKeyID("a")
+	assert.True(t, ok)
+}
+
+func TestCache_File(t *testing.T) {
+	root := t.TempDir()
+	crypto.SetSecretsRoot(root)
+	t.Cleanup(func() { crypto.SetSecretsRoot(crypto.DefaultSecretsRoot) })
+	path := filepath.Join(root, "jwks.json")
+	require.NoError(t, os.WriteFile(path, newKeySet(t, "a"), 0o600))
+	cache := newTestCache(t)
+	set, err := cache.Fetch(context.Background(), "file://"+path)
+	require.NoError(t, err)
+	_, ok := set.LookupKeyID("a")
+	assert.True(t, ok)
+	// files are reloaded when they change
+	require.NoError(t, os.WriteFile(path, newKeySet(t, "b", "c"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	set, err = cache.Fetch(context.Background(), "file://"+path)
+	require.NoError(t, err)
+	_, ok = set.LookupKeyID("b")
+	assert.True(t, ok)
+	_, err = cache.Fetch(context.Background(), "file://"+filepath.Join(root, "missing.json"))
+	assert.Error(t, err)
+	// files outside of the secrets root are rejected
+	outside := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(outside, newKeySet(t, "a"), 0o600))
+	_, err = cache.Fetch(context.Background(), "file://"+outside)
+	assert.ErrorContains(t, err, "is outside of the secrets root")
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link.json")))
+	_, err = cache.Fetch(context.Background(), "file://"+filepath.Join(root, "link.json"))
+	assert.ErrorContains(t, err, "is outside of the secrets root")
+}
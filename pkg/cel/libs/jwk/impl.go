@@ -6,7 +6,6 @@ import (
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
-	"github.com/lestrrat-go/jwx/v3/jwk"
 )
 
 type impl struct {
@@ -17,10 +16,34 @@ func (c *impl) fetch(from ref.Val) ref.Val {
 	if from, err := utils.ConvertToNative[string](from); err != nil {
 		return types.WrapErr(err)
 	} else {
-		set, err := jwk.Fetch(context.Background(), from)
+		set, err := Fetch(context.Background(), from)
 		if err != nil {
 			return types.WrapErr(err)
 		}
 		return c.NativeToValue(Set{set})
 	}
 }
+
+func (c *impl) parse_string(content ref.Val) ref.Val {
+	if content, err := utils.ConvertToNative[string](content); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.parse([]byte(content))
+	}
+}
+
+func (c *impl) parse_bytes(content ref.Val) ref.Val {
+	if content, err := utils.ConvertToNative[[]byte](content); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.parse(content)
+	}
+}
+
+func (c *impl) parse(content []byte) ref.Val {
+	set, err := ParseSet(content)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+	return c.NativeToValue(Set{set})
+}
@@ -42,6 +42,10 @@ func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		"jwks.Fetch": {
 			cel.Overload("fetch_string", []*cel.Type{types.StringType}, SetType, cel.UnaryBinding(impl.fetch)),
 		},
+		"jwks.Parse": {
+			cel.Overload("jwks_parse_string", []*cel.Type{types.StringType}, SetType, cel.UnaryBinding(impl.parse_string)),
+			cel.Overload("jwks_parse_bytes", []*cel.Type{types.BytesType}, SetType, cel.UnaryBinding(impl.parse_bytes)),
+		},
 	}
 	// create env options corresponding to our function overloads
 	options := []cel.EnvOption{}
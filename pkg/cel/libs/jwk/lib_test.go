@@ -42,3 +42,30 @@ func Test_fetch(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, jwks.Value())
 }
+
+func Test_parse(t *testing.T) {
+	jwks := `{"keys":[{"alg":"ES256","crv":"P-256","kid":"my-key-id","kty":"EC","use":"sig","x":"iTV4PECbWuDaNBMTLmwH0jwBTD3xUXR0S-VWsCYv8Gc","y":"-Cnw8d0XyQztrPZpynrFn8t10lyEb6oWqWcLJWPUB5A"}]}`
+	env, err := cel.NewEnv(
+		Lib(),
+		cel.Variable("jwks", cel.StringType),
+	)
+	assert.NoError(t, err)
+	for _, source := range []string{"jwks.Parse(jwks)", "jwks.Parse(bytes(jwks))"} {
+		ast, issues := env.Compile(source)
+		assert.NoError(t, issues.Err())
+		prog, err := env.Program(ast)
+		assert.NoError(t, err)
+		out, _, err := prog.Eval(map[string]any{"jwks": jwks})
+		assert.NoError(t, err)
+		set, ok := out.Value().(Set)
+		assert.True(t, ok)
+		_, ok = set.LookupKeyID("my-key-id")
+		assert.True(t, ok)
+	}
+	ast, issues := env.Compile("jwks.Parse('{')")
+	assert.NoError(t, issues.Err())
+	prog, err := env.Program(ast)
+	assert.NoError(t, err)
+	_, _, err = prog.Eval(map[string]any{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package jwk
+
+import (
+	"bytes"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+)
+
+// ParseSet parses a JWK, a JWK set or PEM encoded keys and certificates
+func ParseSet(data []byte) (jwk.Set, error) {
+	if IsPEM(data) {
+		return jwk.Parse(data, jwk.WithPEM(true))
+	}
+	return jwk.Parse(data)
+}
+
+// IsPEM returns true if data looks PEM encoded
+func IsPEM(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN "))
+}
@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/cel-go/common/types"
@@ -18,9 +22,19 @@ type impl struct {
 }
 
 func (c *impl) decode_string_string(token ref.Val, key ref.Val) ref.Val {
-	if token, err := utils.ConvertToNative[string](token); err != nil {
+	return c.decode_string_string_options(token, key, c.NativeToValue(&Options{}))
+}
+
+func (c *impl) decode_string_set(token ref.Val, set ref.Val) ref.Val {
+	return c.decode_string_set_options(token, set, c.NativeToValue(&Options{}))
+}
+
+func (c *impl) decode_string_string_options(args ...ref.Val) ref.Val {
+	if token, err := utils.ConvertToNative[string](args[0]); err != nil {
 		return types.WrapErr(err)
-	} else if key, err := utils.ConvertToNative[string](key); err != nil {
+	} else if key, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if options, err := utils.ConvertToNative[*Options](args[2]); err != nil {
 		return types.WrapErr(err)
 	} else {
 		set := jwk.NewSet()
@@ -31,100 +45,139 @@ func (c *impl) decode_string_string(token ref.Val, key ref.Val) ref.Val {
 		if err := set.AddKey(key); err != nil {
 			return types.WrapErr(err)
 		}
-		tok, err := jwt.Parse(
-			[]byte(token),
-			jwt.WithValidate(false),
-			jwt.WithKeySet(
-				set,
-				jws.WithUseDefault(true),
-				jws.WithInferAlgorithmFromKey(true),
-			),
-		)
-		if err != nil {
-			return types.WrapErr(err)
-		}
-		var claims *structpb.Struct
-		if keys := tok.Keys(); len(keys) > 0 {
-			fields := make(map[string]any, len(keys))
-			for _, key := range keys {
-				var value any
-				err := tok.Get(key, &value)
-				if err != nil {
-					return types.WrapErr(err)
-				}
-				switch value := value.(type) {
-				case time.Time:
-					fields[key] = value.Unix()
-				default:
-					fields[key] = value
-				}
-			}
-			encoded, err := structpb.NewStruct(fields)
-			if err != nil {
-				return types.WrapErr(err)
-			}
-			claims = encoded
-		}
-		return c.NativeToValue(
-			Token{
-				Claims: claims,
-				Valid:  jwt.Validate(tok) == nil,
-			},
-		)
+		return c.decode(token, set, options)
 	}
 }
 
-func (c *impl) decode_string_set(token ref.Val, set ref.Val) ref.Val {
-	if token, err := utils.ConvertToNative[string](token); err != nil {
+func (c *impl) decode_string_set_options(args ...ref.Val) ref.Val {
+	if token, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if set, err := utils.ConvertToNative[jwklib.Set](args[1]); err != nil {
 		return types.WrapErr(err)
-	} else if set, err := utils.ConvertToNative[jwklib.Set](set); err != nil {
+	} else if options, err := utils.ConvertToNative[*Options](args[2]); err != nil {
 		return types.WrapErr(err)
 	} else {
-		tok, err := jwt.Parse(
-			[]byte(token),
-			jwt.WithValidate(false),
-			jwt.WithKeySet(
-				set.Set,
-				jws.WithUseDefault(true),
-				jws.WithInferAlgorithmFromKey(true),
-			),
-		)
-		if err != nil {
-			return types.WrapErr(err)
-		}
-		var claims *structpb.Struct
-		if keys := tok.Keys(); len(keys) > 0 {
-			fields := make(map[string]any, len(keys))
-			for _, key := range keys {
-				var value any
-				err := tok.Get(key, &value)
-				if err != nil {
-					return types.WrapErr(err)
-				}
-				switch value := value.(type) {
-				case time.Time:
-					fields[key] = value.Unix()
-				case []string:
-					var untyped []any
-					for _, v := range value {
-						untyped = append(untyped, v)
-					}
-					fields[key] = untyped
-				default:
-					fields[key] = value
-				}
-			}
-			encoded, err := structpb.NewStruct(fields)
+		return c.decode(token, set.Set, options)
+	}
+}
+
+func (c *impl) sign_map_string_options(args ...ref.Val) ref.Val {
+	if key, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if options, err := utils.ConvertToNative[*SignOptions](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if set, err := keySet([]byte(key), options.Algorithm); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.sign(args[0], set, options)
+	}
+}
+
+func (c *impl) sign_map_bytes_options(args ...ref.Val) ref.Val {
+	if key, err := utils.ConvertToNative[[]byte](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if options, err := utils.ConvertToNative[*SignOptions](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if set, err := keySet(key, options.Algorithm); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.sign(args[0], set, options)
+	}
+}
+
+func (c *impl) sign_map_set_options(args ...ref.Val) ref.Val {
+	if set, err := utils.ConvertToNative[jwklib.Set](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if options, err := utils.ConvertToNative[*SignOptions](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.sign(args[0], set.Set, options)
+	}
+}
+
+func (c *impl) sign(claims ref.Val, set jwk.Set, options *SignOptions) ref.Val {
+	if claims, err := utils.ConvertToNative[*structpb.Struct](claims); err != nil {
+		return types.WrapErr(err)
+	} else if token, err := Sign(claims.AsMap(), set, options, time.Now()); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(token)
+	}
+}
+
+func (c *impl) decode(token string, set jwk.Set, options *Options) ref.Val {
+	tok, err := jwt.Parse(
+		[]byte(token),
+		jwt.WithValidate(false),
+		jwt.WithKeySet(
+			set,
+			jws.WithUseDefault(true),
+			jws.WithInferAlgorithmFromKey(true),
+		),
+	)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+	header, err := decodeHeader(token)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+	var claims *structpb.Struct
+	if keys := tok.Keys(); len(keys) > 0 {
+		fields := make(map[string]any, len(keys))
+		for _, key := range keys {
+			var value any
+			err := tok.Get(key, &value)
 			if err != nil {
 				return types.WrapErr(err)
 			}
-			claims = encoded
+			switch value := value.(type) {
+			case time.Time:
+				fields[key] = value.Unix()
+			case []string:
+				var untyped []any
+				for _, v := range value {
+					untyped = append(untyped, v)
+				}
+				fields[key] = untyped
+			default:
+				fields[key] = value
+			}
 		}
-		return c.NativeToValue(
-			Token{
-				Claims: claims,
-				Valid:  jwt.Validate(tok) == nil,
-			},
-		)
+		encoded, err := structpb.NewStruct(fields)
+		if err != nil {
+			return types.WrapErr(err)
+		}
+		claims = encoded
+	}
+	encodedHeader, err := structpb.NewStruct(header)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+	errs := validate(tok, header, options, time.Now())
+	return c.NativeToValue(
+		Token{
+			Header: encodedHeader,
+			Claims: claims,
+			Valid:  len(errs) == 0,
+			Errors: errs,
+		},
+	)
+}
+
+// decodeHeader returns the protected header of a compact token, the signature must have been verified before
+func decodeHeader(token string) (map[string]any, error) {
+	encoded, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errors.New("invalid compact token")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var header map[string]any
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
 	}
+	return header, nil
 }
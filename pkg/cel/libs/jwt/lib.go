@@ -24,8 +24,8 @@ func (c *lib) CompileOptions() []cel.EnvOption {
 	return []cel.EnvOption{
 		// register jwk lib
 		jwk.Lib(),
-		// register token type
-		ext.NativeTypes(reflect.TypeFor[Token]()),
+		// register token and options types
+		ext.NativeTypes(reflect.TypeFor[Token](), reflect.TypeFor[Options](), reflect.TypeFor[SignOptions]()),
 		// extend environment with function overloads
 		c.extendEnv,
 	}
@@ -45,6 +45,13 @@ func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		"jwt.Decode": {
 			cel.Overload("decode_string_string", []*cel.Type{types.StringType, types.StringType}, TokenType, cel.BinaryBinding(impl.decode_string_string)),
 			cel.Overload("decode_string_set", []*cel.Type{types.StringType, jwk.SetType}, TokenType, cel.BinaryBinding(impl.decode_string_set)),
+			cel.Overload("decode_string_string_options", []*cel.Type{types.StringType, types.StringType, OptionsType}, TokenType, cel.FunctionBinding(impl.decode_string_string_options)),
+			cel.Overload("decode_string_set_options", []*cel.Type{types.StringType, jwk.SetType, OptionsType}, TokenType, cel.FunctionBinding(impl.decode_string_set_options)),
+		},
+		"jwt.Sign": {
+			cel.Overload("sign_map_string_options", []*cel.Type{types.NewMapType(types.StringType, types.DynType), types.StringType, SignOptionsType}, types.StringType, cel.FunctionBinding(impl.sign_map_string_options)),
+			cel.Overload("sign_map_bytes_options", []*cel.Type{types.NewMapType(types.StringType, types.DynType), types.BytesType, SignOptionsType}, types.StringType, cel.FunctionBinding(impl.sign_map_bytes_options)),
+			cel.Overload("sign_map_set_options", []*cel.Type{types.NewMapType(types.StringType, types.DynType), jwk.SetType, SignOptionsType}, types.StringType, cel.FunctionBinding(impl.sign_map_set_options)),
 		},
 	}
 	// create env options corresponding to our function overloads
@@ -1,15 +1,26 @@
 package jwt
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	jwklib "github.com/kyverno/kyverno-authz/pkg/cel/libs/jwk"
 	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+	"github.com/lestrrat-go/jwx/v3/jwa"
 	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_decode_string_string(t *testing.T) {
@@ -135,3 +146,167 @@ func Test_decode_string_set(t *testing.T) {
 		})
 	}
 }
+
+func Test_decode_options(t *testing.T) {
+	now := time.Now()
+	sign := func(t *testing.T, typ string, build func(*jwt.Builder) *jwt.Builder) string {
+		t.Helper()
+		tok, err := build(jwt.NewBuilder()).Build()
+		require.NoError(t, err)
+		headers := jws.NewHeaders()
+		if typ != "" {
+			require.NoError(t, headers.Set(jws.TypeKey, typ))
+		}
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256(), []byte("secret"), jws.WithProtectedHeaders(headers)))
+		require.NoError(t, err)
+		return string(signed)
+	}
+	access := sign(t, "at+jwt", func(b *jwt.Builder) *jwt.Builder {
+		return b.Issuer("https://idp.example.com").Audience([]string{"api", "web"}).Subject("alice").IssuedAt(now.Add(-time.Minute)).Expiration(now.Add(time.Hour))
+	})
+	old := sign(t, "", func(b *jwt.Builder) *jwt.Builder {
+		return b.Issuer("https://other.example.com").Audience([]string{"web"}).IssuedAt(now.Add(-2 * time.Hour)).Expiration(now.Add(-10 * time.Second))
+	})
+	tests := []struct {
+		name   string
+		token  string
+		source string
+		want   any
+	}{{
+		name:   "header",
+		token:  access,
+		source: `jwt.Decode(token, "secret").Header.alg + " " + jwt.Decode(token, "secret").Header.typ`,
+		want:   "HS256 at+jwt",
+	}, {
+		name:   "no options",
+		token:  access,
+		source: `jwt.Decode(token, "secret").Valid && jwt.Decode(token, "secret").Errors.size() == 0`,
+		want:   true,
+	}, {
+		name:   "all options pass",
+		token:  access,
+		source: `jwt.Decode(token, "secret", jwt.Options{Issuers: ["https://idp.example.com"], Audiences: ["api"], Algorithms: ["HS256"], RequiredClaims: ["sub"], MaxAge: duration("5m"), Type: "application/AT+JWT"}).Valid`,
+		want:   true,
+	}, {
+		name:   "all options fail",
+		token:  old,
+		source: `jwt.Decode(token, "secret", jwt.Options{Issuers: ["https://idp.example.com"], Audiences: ["api"], Algorithms: ["RS256"], RequiredClaims: ["sub"], MaxAge: duration("1h"), Type: "at+jwt"}).Errors.map(e, e.Code)`,
+		want:   []string{"expired", "too_old", "issuer", "audience", "algorithm", "type", "missing_claim"},
+	}, {
+		name:   "leeway",
+		token:  old,
+		source: `jwt.Decode(token, "secret", jwt.Options{Leeway: duration("30s")}).Valid`,
+		want:   true,
+	}, {
+		name:   "error message",
+		token:  old,
+		source: `jwt.Decode(token, "secret", jwt.Options{Issuers: ["https://idp.example.com"], Leeway: duration("30s")}).Errors[0].Message`,
+		want:   `issuer "https://other.example.com" is not accepted`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("token", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"token": tt.token})
+			require.NoError(t, err)
+			if want, ok := tt.want.([]string); ok {
+				got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			} else {
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
+
+func Test_sign(t *testing.T) {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	private, err := x509.MarshalPKCS8PrivateKey(raw)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&raw.PublicKey)
+	require.NoError(t, err)
+	vars := map[string]any{
+		"private": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private})),
+		"public":  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})),
+		"keys":    `{"keys":[{"kty":"oct","kid":"a","k":"c2VjcmV0LWE"},{"kty":"oct","kid":"b","k":"c2VjcmV0LWI"}]}`,
+	}
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "hmac",
+		source: `jwt.Decode(jwt.Sign({"sub": "alice", "groups": ["admin"]}, "secret", jwt.SignOptions{Algorithm: "HS256", TTL: duration("5m")}), "secret").Claims.groups`,
+		want:   []string{"admin"},
+	}, {
+		name:   "hmac bytes",
+		source: `jwt.Decode(jwt.Sign({"sub": "alice"}, b"secret", jwt.SignOptions{Algorithm: "HS384"}), "secret").Header.alg`,
+		want:   "HS384",
+	}, {
+		name:   "ttl",
+		source: `[jwt.Decode(jwt.Sign({"sub": "alice"}, "secret", jwt.SignOptions{Algorithm: "HS256", TTL: duration("5m")}), "secret")].map(t, t.Claims.exp - t.Claims.iat)[0]`,
+		want:   float64(300),
+	}, {
+		name:   "pem",
+		source: `jwt.Decode(jwt.Sign({"iss": "https://gateway.internal", "sub": "alice"}, private, jwt.SignOptions{Type: "JWT", TTL: duration("1m")}), jwks.Parse(public), jwt.Options{Issuers: ["https://gateway.internal"], Algorithms: ["ES256"], Type: "JWT"}).Valid`,
+		want:   true,
+	}, {
+		name:   "key set",
+		source: `jwt.Decode(jwt.Sign({"sub": "alice"}, jwks.Parse(private), jwt.SignOptions{}), jwks.Parse(public)).Header.alg`,
+		want:   "ES256",
+	}, {
+		name:   "key id",
+		source: `jwt.Decode(jwt.Sign({"sub": "alice"}, jwks.Parse(keys), jwt.SignOptions{KeyID: "b"}), jwks.Parse(keys)).Header.kid`,
+		want:   "b",
+	}, {
+		name:    "key id required",
+		source:  `jwt.Sign({"sub": "alice"}, jwks.Parse(keys), jwt.SignOptions{})`,
+		wantErr: true,
+	}, {
+		name:    "raw key without hmac algorithm",
+		source:  `jwt.Sign({"sub": "alice"}, "secret", jwt.SignOptions{})`,
+		wantErr: true,
+	}, {
+		name:    "raw key with asymmetric algorithm",
+		source:  `jwt.Sign({"sub": "alice"}, b"not a key", jwt.SignOptions{Algorithm: "ES256"})`,
+		wantErr: true,
+	}, {
+		name:    "unknown algorithm",
+		source:  `jwt.Sign({"sub": "alice"}, "secret", jwt.SignOptions{Algorithm: "none"})`,
+		wantErr: true,
+	}, {
+		name:    "algorithm mismatch",
+		source:  `jwt.Sign({"sub": "alice"}, private, jwt.SignOptions{Algorithm: "HS256"})`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("private", cel.StringType), cel.Variable("public", cel.StringType), cel.Variable("keys", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if want, ok := tt.want.([]string); ok {
+				got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			} else {
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	jwklib "github.com/kyverno/kyverno-authz/pkg/cel/libs/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+// SignOptions configures minted tokens
+type SignOptions struct {
+	// Algorithm is the signature algorithm, it defaults to the key alg or to the usual algorithm for the key type
+	Algorithm string
+	// KeyID selects the signing key in a key set, it is also set as kid header
+	KeyID string
+	// Type is the typ header
+	Type string
+	// TTL sets the exp claim relative to the iat claim
+	TTL time.Duration
+}
+
+// Sign mints a token with the given claims, iat is always set to now
+func Sign(claims map[string]any, set jwk.Set, options *SignOptions, now time.Time) (string, error) {
+	key, err := signingKey(set, options.KeyID)
+	if err != nil {
+		return "", err
+	}
+	alg, err := signingAlgorithm(key, options.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	tok := jwt.New()
+	for name, value := range claims {
+		if err := tok.Set(name, value); err != nil {
+			return "", fmt.Errorf("invalid claim %q: %w", name, err)
+		}
+	}
+	now = now.Truncate(time.Second)
+	if err := tok.Set(jwt.IssuedAtKey, now); err != nil {
+		return "", err
+	}
+	if options.TTL > 0 {
+		if err := tok.Set(jwt.ExpirationKey, now.Add(options.TTL)); err != nil {
+			return "", err
+		}
+	}
+	headers := jws.NewHeaders()
+	if options.KeyID != "" {
+		if err := headers.Set(jws.KeyIDKey, options.KeyID); err != nil {
+			return "", err
+		}
+	}
+	if options.Type != "" {
+		if err := headers.Set(jws.TypeKey, options.Type); err != nil {
+			return "", err
+		}
+	}
+	signed, err := jwt.Sign(tok, jwt.WithKey(alg, key, jws.WithProtectedHeaders(headers)))
+	if err != nil {
+		return "", err
+	}
+	return string(signed), nil
+}
+
+// keySet parses signing key material, PEM and JSON are parsed as keys, anything else is an HMAC secret.
+// Raw secrets are only accepted with an explicit HMAC algorithm, so that a malformed key is not silently used as a secret.
+func keySet(data []byte, algorithm string) (jwk.Set, error) {
+	if jwklib.IsPEM(data) || (len(data) > 0 && data[0] == '{') {
+		return jwklib.ParseSet(data)
+	}
+	if !strings.HasPrefix(algorithm, "HS") {
+		return nil, errors.New("the key is neither a PEM encoded key nor a JWK, raw keys are HMAC secrets and require an HS256, HS384 or HS512 algorithm")
+	}
+	key, err := jwk.Import(data)
+	if err != nil {
+		return nil, err
+	}
+	set := jwk.NewSet()
+	if err := set.AddKey(key); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func signingKey(set jwk.Set, kid string) (jwk.Key, error) {
+	if kid != "" {
+		if key, ok := set.LookupKeyID(kid); ok {
+			return key, nil
+		}
+		// a single key without kid is used as is, the kid header is still set
+		if key, ok := set.Key(0); ok && set.Len() == 1 {
+			if _, hasKid := key.KeyID(); !hasKid {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("key %q not found", kid)
+	}
+	if set.Len() != 1 {
+		return nil, errors.New("a key id is required to select the signing key in a key set")
+	}
+	key, _ := set.Key(0)
+	return key, nil
+}
+
+func signingAlgorithm(key jwk.Key, name string) (jwa.SignatureAlgorithm, error) {
+	if name == "" {
+		if alg, ok := key.Algorithm(); ok {
+			name = alg.String()
+		} else if name, ok = defaultAlgorithm(key); !ok {
+			return jwa.EmptySignatureAlgorithm(), fmt.Errorf("no default algorithm for %s keys", key.KeyType())
+		}
+	}
+	alg, ok := jwa.LookupSignatureAlgorithm(name)
+	if !ok || alg == jwa.NoSignature() {
+		return jwa.EmptySignatureAlgorithm(), fmt.Errorf("unsupported signature algorithm %q", name)
+	}
+	return alg, nil
+}
+
+func defaultAlgorithm(key jwk.Key) (string, bool) {
+	switch key := key.(type) {
+	case jwk.RSAPrivateKey:
+		return "RS256", true
+	case jwk.ECDSAPrivateKey:
+		crv, _ := key.Crv()
+		switch crv.String() {
+		case elliptic.P256().Params().Name:
+			return "ES256", true
+		case elliptic.P384().Params().Name:
+			return "ES384", true
+		case elliptic.P521().Params().Name:
+			return "ES512", true
+		}
+	case jwk.OKPPrivateKey:
+		return "EdDSA", true
+	case jwk.SymmetricKey:
+		return "HS256", true
+	}
+	return "", false
+}
@@ -1,13 +1,59 @@
 package jwt
 
 import (
+	"time"
+
 	"github.com/google/cel-go/common/types"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-var TokenType = types.NewObjectType("jwt.Token")
+var (
+	TokenType           = types.NewObjectType("jwt.Token")
+	OptionsType         = types.NewObjectType("jwt.Options")
+	SignOptionsType     = types.NewObjectType("jwt.SignOptions")
+	ValidationErrorType = types.NewObjectType("jwt.ValidationError")
+)
+
+// Validation error codes
+const (
+	ErrorExpired        = "expired"
+	ErrorNotYetValid    = "not_yet_valid"
+	ErrorIssuedInFuture = "issued_in_future"
+	ErrorTooOld         = "too_old"
+	ErrorIssuer         = "issuer"
+	ErrorAudience       = "audience"
+	ErrorAlgorithm      = "algorithm"
+	ErrorType           = "type"
+	ErrorMissingClaim   = "missing_claim"
+)
 
 type Token struct {
+	Header *structpb.Struct
 	Claims *structpb.Struct
 	Valid  bool
+	Errors []ValidationError
+}
+
+// ValidationError describes why a token with a valid signature is not valid
+type ValidationError struct {
+	Code    string
+	Message string
+}
+
+// Options configures token validation, zero values disable the corresponding check
+type Options struct {
+	// Issuers lists the accepted iss claims
+	Issuers []string
+	// Audiences lists the accepted aud claims, the token must contain at least one of them
+	Audiences []string
+	// Algorithms lists the accepted alg headers
+	Algorithms []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat claims
+	Leeway time.Duration
+	// RequiredClaims lists the claims the token must contain
+	RequiredClaims []string
+	// MaxAge is the maximum time elapsed since the token was issued, it requires the iat claim
+	MaxAge time.Duration
+	// Type is the expected typ header, compared case insensitively and ignoring the "application/" prefix
+	Type string
 }
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwt"
+)
+
+// validate checks the token claims and header against the options, time based claims are always checked
+func validate(tok jwt.Token, header map[string]any, options *Options, now time.Time) []ValidationError {
+	var errs []ValidationError
+	fail := func(code string, format string, args ...any) {
+		errs = append(errs, ValidationError{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+	// claims have a second precision
+	now = now.Truncate(time.Second)
+	if exp, ok := tok.Expiration(); ok && !now.Before(exp.Add(options.Leeway)) {
+		fail(ErrorExpired, "token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := tok.NotBefore(); ok && now.Add(options.Leeway).Before(nbf) {
+		fail(ErrorNotYetValid, "token is not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+	iat, hasIat := tok.IssuedAt()
+	if hasIat && now.Add(options.Leeway).Before(iat) {
+		fail(ErrorIssuedInFuture, "token is issued at %s, in the future", iat.UTC().Format(time.RFC3339))
+	}
+	if options.MaxAge > 0 {
+		if !hasIat {
+			fail(ErrorTooOld, "token has no iat claim to check its age")
+		} else if now.After(iat.Add(options.MaxAge + options.Leeway)) {
+			fail(ErrorTooOld, "token was issued more than %s ago", options.MaxAge)
+		}
+	}
+	if len(options.Issuers) > 0 {
+		if iss, _ := tok.Issuer(); !slices.Contains(options.Issuers, iss) {
+			fail(ErrorIssuer, "issuer %q is not accepted", iss)
+		}
+	}
+	if len(options.Audiences) > 0 {
+		aud, _ := tok.Audience()
+		if !slices.ContainsFunc(aud, func(aud string) bool { return slices.Contains(options.Audiences, aud) }) {
+			fail(ErrorAudience, "audience %q is not accepted", aud)
+		}
+	}
+	if len(options.Algorithms) > 0 {
+		if alg, _ := header["alg"].(string); !slices.Contains(options.Algorithms, alg) {
+			fail(ErrorAlgorithm, "algorithm %q is not accepted", alg)
+		}
+	}
+	if options.Type != "" {
+		if typ, _ := header["typ"].(string); normalizeType(typ) != normalizeType(options.Type) {
+			fail(ErrorType, "type %q is not accepted", typ)
+		}
+	}
+	for _, claim := range options.RequiredClaims {
+		if !tok.Has(claim) {
+			fail(ErrorMissingClaim, "claim %q is missing", claim)
+		}
+	}
+	return errs
+}
+
+// normalizeType compares media types as recommended in https://www.rfc-editor.org/rfc/rfc7515#section-4.1.9
+func normalizeType(typ string) string {
+	return strings.TrimPrefix(strings.ToLower(typ), "application/")
+}
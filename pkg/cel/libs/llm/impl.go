@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse_string_string(path ref.Val, content ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else if content, err := utils.ConvertToNative[string](content); err != nil {
+		return types.WrapErr(err)
+	} else if request, err := Parse(path, []byte(content)); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(request)
+	}
+}
+
+// parser returns a function binding parsing the request body for a given api
+func (c *impl) parser(parse func([]byte) (*Request, error)) func(ref.Val) ref.Val {
+	return func(content ref.Val) ref.Val {
+		if content, err := utils.ConvertToNative[string](content); err != nil {
+			return types.WrapErr(err)
+		} else if request, err := parse([]byte(content)); err != nil {
+			return types.WrapErr(err)
+		} else {
+			return c.NativeToValue(request)
+		}
+	}
+}
+
+func (c *impl) request_uses_tools(request ref.Val) ref.Val {
+	if request, err := utils.ConvertToNative[*Request](request); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(request.UsesTools())
+	}
+}
+
+func (c *impl) request_text(request ref.Val) ref.Val {
+	if request, err := utils.ConvertToNative[*Request](request); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(request.Text())
+	}
+}
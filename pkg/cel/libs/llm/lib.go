@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.llm"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Request](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"llm.Parse": {
+			cel.Overload("llm_parse_string_string", []*cel.Type{types.StringType, types.StringType}, RequestType, cel.BinaryBinding(impl.parse_string_string)),
+		},
+		"llm.ParseChatCompletions": {
+			cel.Overload("llm_parse_chat_completions_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parser(ParseChatCompletions))),
+		},
+		"llm.ParseCompletions": {
+			cel.Overload("llm_parse_completions_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parser(ParseCompletions))),
+		},
+		"llm.ParseEmbeddings": {
+			cel.Overload("llm_parse_embeddings_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parser(ParseEmbeddings))),
+		},
+		"llm.ParseResponses": {
+			cel.Overload("llm_parse_responses_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parser(ParseResponses))),
+		},
+		"llm.ParseMessages": {
+			cel.Overload("llm_parse_messages_string", []*cel.Type{types.StringType}, RequestType, cel.UnaryBinding(impl.parser(ParseMessages))),
+		},
+		"usesTools": {
+			cel.MemberOverload("llm_request_uses_tools", []*cel.Type{RequestType}, types.BoolType, cel.UnaryBinding(impl.request_uses_tools)),
+		},
+		"text": {
+			cel.MemberOverload("llm_request_text", []*cel.Type{RequestType}, types.StringType, cel.UnaryBinding(impl.request_text)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
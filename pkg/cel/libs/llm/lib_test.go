@@ -0,0 +1,252 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLib(t *testing.T) {
+	const (
+		chat = `{
+			"model": "gpt-4o-mini",
+			"max_tokens": 256,
+			"temperature": 0.2,
+			"stream": true,
+			"user": "alice",
+			"messages": [
+				{ "role": "system", "content": "you are a helpful assistant" },
+				{ "role": "user", "content": [
+					{ "type": "text", "text": "what is in this image?" },
+					{ "type": "image_url", "image_url": { "url": "https://example.com/cat.png" } }
+				] }
+			],
+			"tools": [
+				{ "type": "function", "function": { "name": "get_weather", "parameters": {} } }
+			],
+			"tool_choice": { "type": "function", "function": { "name": "get_weather" } }
+		}`
+		messages = `{
+			"model": "claude-sonnet-4-5",
+			"max_tokens": 1024,
+			"system": [{ "type": "text", "text": "be concise" }],
+			"messages": [
+				{ "role": "user", "content": "hello" },
+				{ "role": "assistant", "content": [{ "type": "tool_use", "id": "1", "name": "search", "input": {} }] }
+			],
+			"tools": [
+				{ "name": "search", "input_schema": {} },
+				{ "type": "web_search_20250305", "name": "web_search" }
+			],
+			"tool_choice": { "type": "none" },
+			"metadata": { "user_id": "bob" }
+		}`
+		responses = `{
+			"model": "gpt-4.1",
+			"instructions": "answer in french",
+			"input": "bonjour",
+			"max_output_tokens": 100,
+			"tools": [{ "type": "web_search_preview" }]
+		}`
+		embeddings  = `{ "model": "text-embedding-3-small", "input": ["a", "b"] }`
+		completions = `{ "model": "gpt-3.5-turbo-instruct", "prompt": "once upon a time", "max_tokens": 16 }`
+	)
+	tests := []struct {
+		name    string
+		source  string
+		path    string
+		body    string
+		want    any
+		wantErr bool
+	}{{
+		name:   "chat model and limits",
+		source: `llm.ParseChatCompletions(body).model == "gpt-4o-mini" && llm.ParseChatCompletions(body).maxTokens == 256 && llm.ParseChatCompletions(body).stream`,
+		body:   chat,
+		want:   true,
+	}, {
+		name:   "chat max completion tokens",
+		source: `llm.ParseChatCompletions(body).maxTokens`,
+		body:   `{ "model": "o3", "max_tokens": 10, "max_completion_tokens": 20 }`,
+		want:   int64(20),
+	}, {
+		name:   "chat roles",
+		source: `llm.ParseChatCompletions(body).messages.map(m, m.role)`,
+		body:   chat,
+		want:   []string{"system", "user"},
+	}, {
+		name:   "chat content types",
+		source: `llm.ParseChatCompletions(body).messages[1].contentTypes`,
+		body:   chat,
+		want:   []string{"text", "image_url"},
+	}, {
+		name:   "chat tools",
+		source: `llm.ParseChatCompletions(body).tools[0].name + " " + llm.ParseChatCompletions(body).toolChoice.name`,
+		body:   chat,
+		want:   "get_weather get_weather",
+	}, {
+		name:   "chat uses tools",
+		source: `llm.ParseChatCompletions(body).usesTools()`,
+		body:   chat,
+		want:   true,
+	}, {
+		name:   "chat text",
+		source: `llm.ParseChatCompletions(body).text()`,
+		body:   chat,
+		want:   "you are a helpful assistant\nwhat is in this image?",
+	}, {
+		name:   "chat no tools",
+		source: `llm.ParseChatCompletions(body).usesTools() || has(llm.ParseChatCompletions(body).toolChoice)`,
+		body:   `{ "model": "gpt-4o", "messages": [] }`,
+		want:   false,
+	}, {
+		name:   "messages",
+		source: `llm.ParseMessages(body).system + " " + llm.ParseMessages(body).user + " " + string(llm.ParseMessages(body).maxTokens)`,
+		body:   messages,
+		want:   "be concise bob 1024",
+	}, {
+		name:   "messages tools",
+		source: `llm.ParseMessages(body).tools.map(t, t.type + "/" + t.name)`,
+		body:   messages,
+		want:   []string{"function/search", "web_search_20250305/web_search"},
+	}, {
+		name:   "messages tool choice none",
+		source: `llm.ParseMessages(body).usesTools()`,
+		body:   messages,
+		want:   false,
+	}, {
+		name:   "messages tool use",
+		source: `llm.ParseMessages(body).messages.exists(m, "tool_use" in m.contentTypes)`,
+		body:   messages,
+		want:   true,
+	}, {
+		name:   "responses",
+		source: `llm.ParseResponses(body).system + " " + llm.ParseResponses(body).messages[0].text + " " + string(llm.ParseResponses(body).maxTokens)`,
+		body:   responses,
+		want:   "answer in french bonjour 100",
+	}, {
+		name:   "responses built in tool",
+		source: `llm.ParseResponses(body).tools[0].type`,
+		body:   responses,
+		want:   "web_search_preview",
+	}, {
+		name:   "responses items",
+		source: `llm.ParseResponses(body).messages.map(m, m.role + ":" + m.contentTypes[0])`,
+		body:   `{ "model": "gpt-4.1", "input": [{ "role": "user", "content": [{ "type": "input_text", "text": "hi" }] }, { "type": "function_call_output", "call_id": "1", "output": "42" }] }`,
+		want:   []string{"user:input_text", ":function_call_output"},
+	}, {
+		name:   "embeddings",
+		source: `llm.ParseEmbeddings(body).input`,
+		body:   embeddings,
+		want:   []string{"a", "b"},
+	}, {
+		name:   "completions",
+		source: `llm.ParseCompletions(body).input[0]`,
+		body:   completions,
+		want:   "once upon a time",
+	}, {
+		name:   "parse by path",
+		source: `llm.Parse(path, body).api`,
+		path:   "/v1/chat/completions",
+		body:   chat,
+		want:   "chat/completions",
+	}, {
+		name:   "parse by path with query",
+		source: `llm.Parse(path, body).api`,
+		path:   "/openai/deployments/gpt/completions?api-version=2024-10-21",
+		body:   completions,
+		want:   "completions",
+	}, {
+		name:   "parse anthropic by path",
+		source: `llm.Parse(path, body).model`,
+		path:   "/v1/messages",
+		body:   messages,
+		want:   "claude-sonnet-4-5",
+	}, {
+		name:    "parse unknown path",
+		source:  `llm.Parse(path, body)`,
+		path:    "/v1/models",
+		body:    `{}`,
+		wantErr: true,
+	}, {
+		name:    "invalid body",
+		source:  `llm.ParseChatCompletions(body)`,
+		body:    `{`,
+		wantErr: true,
+	}, {
+		name:    "invalid content",
+		source:  `llm.ParseMessages(body)`,
+		body:    `{ "messages": [{ "role": "user", "content": 42 }] }`,
+		wantErr: true,
+	}, {
+		name:   "chat deprecated functions",
+		source: `llm.ParseChatCompletions(body).usesTools() && llm.ParseChatCompletions(body).tools[0].name == "get_weather"`,
+		body:   `{ "model": "gpt-4o", "functions": [{ "name": "get_weather", "parameters": {} }] }`,
+		want:   true,
+	}, {
+		name:   "chat deprecated function call",
+		source: `llm.ParseChatCompletions(body).toolChoice.type + "/" + llm.ParseChatCompletions(body).toolChoice.name`,
+		body:   `{ "model": "gpt-4o", "functions": [{ "name": "get_weather" }], "function_call": { "name": "get_weather" } }`,
+		want:   "function/get_weather",
+	}, {
+		name:   "chat deprecated function call disabled",
+		source: `llm.ParseChatCompletions(body).usesTools()`,
+		body:   `{ "model": "gpt-4o", "functions": [{ "name": "get_weather" }], "function_call": "none" }`,
+		want:   false,
+	}, {
+		name:   "chat tool choice takes precedence over function call",
+		source: `llm.ParseChatCompletions(body).toolChoice.type`,
+		body:   `{ "model": "gpt-4o", "tools": [{ "type": "function", "function": { "name": "a" } }], "tool_choice": "required", "function_call": "none" }`,
+		want:   "required",
+	}, {
+		name:    "keys differing in case",
+		source:  `llm.ParseChatCompletions(body)`,
+		body:    `{ "model": "expensive", "MODEL": "cheap" }`,
+		wantErr: true,
+	}, {
+		name:    "key in another case",
+		source:  `llm.Parse(path, body)`,
+		path:    "/v1/messages",
+		body:    `{ "Model": "cheap" }`,
+		wantErr: true,
+	}, {
+		name:    "duplicate keys",
+		source:  `llm.ParseResponses(body)`,
+		body:    `{ "model": "expensive", "model": "cheap" }`,
+		wantErr: true,
+	}, {
+		name:    "nested keys differing in case",
+		source:  `llm.ParseChatCompletions(body)`,
+		body:    `{ "model": "gpt-4o", "tools": [{ "type": "function", "function": { "name": "a", "NAME": "b" } }] }`,
+		wantErr: true,
+	}, {
+		name:   "unknown keys are not checked",
+		source: `llm.ParseChatCompletions(body).model`,
+		body:   `{ "model": "gpt-4o", "metadata": { "Tag": "a", "tag": "b" } }`,
+		want:   "gpt-4o",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("path", cel.StringType), cel.Variable("body", cel.StringType))
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"path": tt.path, "body": tt.body})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if want, ok := tt.want.([]string); ok {
+					got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				} else {
+					assert.Equal(t, tt.want, out.Value())
+				}
+			}
+		})
+	}
+}
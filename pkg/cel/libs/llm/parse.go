@@ -0,0 +1,424 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// content part types carrying text, across apis
+var textPartTypes = map[string]bool{
+	"text":        true,
+	"input_text":  true,
+	"output_text": true,
+}
+
+type wireMessage struct {
+	Type    string          `json:"type"`
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type wireTool struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Function *struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+type wireFunction struct {
+	Name string `json:"name"`
+}
+
+// Parse parses a request body, the api is guessed from the request path
+func Parse(path string, content []byte) (*Request, error) {
+	path, _, _ = strings.Cut(path, "?")
+	path = strings.TrimSuffix(path, "/")
+	switch {
+	case strings.HasSuffix(path, "/"+APIChatCompletions):
+		return ParseChatCompletions(content)
+	case strings.HasSuffix(path, "/"+APICompletions):
+		return ParseCompletions(content)
+	case strings.HasSuffix(path, "/"+APIEmbeddings):
+		return ParseEmbeddings(content)
+	case strings.HasSuffix(path, "/"+APIResponses):
+		return ParseResponses(content)
+	case strings.HasSuffix(path, "/"+APIMessages):
+		return ParseMessages(content)
+	default:
+		return nil, fmt.Errorf("no llm api served at path %q", path)
+	}
+}
+
+// ParseChatCompletions parses an OpenAI compatible chat completions request body
+func ParseChatCompletions(content []byte) (*Request, error) {
+	var body struct {
+		Model               string          `json:"model"`
+		MaxTokens           int             `json:"max_tokens"`
+		MaxCompletionTokens int             `json:"max_completion_tokens"`
+		Temperature         float64         `json:"temperature"`
+		Stream              bool            `json:"stream"`
+		User                string          `json:"user"`
+		Messages            []wireMessage   `json:"messages"`
+		Tools               []wireTool      `json:"tools"`
+		ToolChoice          json.RawMessage `json:"tool_choice"`
+		// functions and function_call are deprecated in favor of tools and tool_choice but still accepted
+		Functions    []wireFunction  `json:"functions"`
+		FunctionCall json.RawMessage `json:"function_call"`
+	}
+	if err := unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	request := &Request{
+		API:         APIChatCompletions,
+		Model:       body.Model,
+		MaxTokens:   body.MaxTokens,
+		Temperature: body.Temperature,
+		Stream:      body.Stream,
+		User:        body.User,
+	}
+	// max_tokens is deprecated in favor of max_completion_tokens
+	if body.MaxCompletionTokens != 0 {
+		request.MaxTokens = body.MaxCompletionTokens
+	}
+	tools := body.Tools
+	for _, function := range body.Functions {
+		tools = append(tools, wireTool{Type: "function", Name: function.Name})
+	}
+	if err := fill(request, body.Messages, tools, body.ToolChoice); err != nil {
+		return request, err
+	}
+	if request.ToolChoice == nil {
+		choice, err := parseToolChoice(body.FunctionCall)
+		if err != nil {
+			return request, fmt.Errorf("invalid function call: %w", err)
+		}
+		// a function call object only holds the function name
+		if choice != nil && choice.Type == "" {
+			choice.Type = "function"
+		}
+		request.ToolChoice = choice
+	}
+	return request, nil
+}
+
+// ParseCompletions parses an OpenAI compatible legacy completions request body
+func ParseCompletions(content []byte) (*Request, error) {
+	var body struct {
+		Model       string          `json:"model"`
+		Prompt      json.RawMessage `json:"prompt"`
+		MaxTokens   int             `json:"max_tokens"`
+		Temperature float64         `json:"temperature"`
+		Stream      bool            `json:"stream"`
+		User        string          `json:"user"`
+	}
+	if err := unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	input, err := stringList(body.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt: %w", err)
+	}
+	return &Request{
+		API:         APICompletions,
+		Model:       body.Model,
+		MaxTokens:   body.MaxTokens,
+		Temperature: body.Temperature,
+		Stream:      body.Stream,
+		User:        body.User,
+		Input:       input,
+	}, nil
+}
+
+// ParseEmbeddings parses an OpenAI compatible embeddings request body
+func ParseEmbeddings(content []byte) (*Request, error) {
+	var body struct {
+		Model string          `json:"model"`
+		Input json.RawMessage `json:"input"`
+		User  string          `json:"user"`
+	}
+	if err := unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	input, err := stringList(body.Input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	return &Request{
+		API:   APIEmbeddings,
+		Model: body.Model,
+		User:  body.User,
+		Input: input,
+	}, nil
+}
+
+// ParseResponses parses an OpenAI responses request body
+func ParseResponses(content []byte) (*Request, error) {
+	var body struct {
+		Model           string          `json:"model"`
+		Instructions    string          `json:"instructions"`
+		Input           json.RawMessage `json:"input"`
+		MaxOutputTokens int             `json:"max_output_tokens"`
+		Temperature     float64         `json:"temperature"`
+		Stream          bool            `json:"stream"`
+		User            string          `json:"user"`
+		Tools           []wireTool      `json:"tools"`
+		ToolChoice      json.RawMessage `json:"tool_choice"`
+	}
+	if err := unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	request := &Request{
+		API:         APIResponses,
+		Model:       body.Model,
+		MaxTokens:   body.MaxOutputTokens,
+		Temperature: body.Temperature,
+		Stream:      body.Stream,
+		User:        body.User,
+		System:      body.Instructions,
+	}
+	// input is either a plain text user message or a list of items
+	var messages []wireMessage
+	var text string
+	if len(body.Input) != 0 && unmarshal(body.Input, &text) == nil {
+		messages = []wireMessage{{Role: "user", Content: body.Input}}
+	} else if len(body.Input) != 0 {
+		if err := unmarshal(body.Input, &messages); err != nil {
+			return nil, fmt.Errorf("invalid input: %w", err)
+		}
+	}
+	return request, fill(request, messages, body.Tools, body.ToolChoice)
+}
+
+// ParseMessages parses an Anthropic messages request body
+func ParseMessages(content []byte) (*Request, error) {
+	var body struct {
+		Model       string          `json:"model"`
+		System      json.RawMessage `json:"system"`
+		MaxTokens   int             `json:"max_tokens"`
+		Temperature float64         `json:"temperature"`
+		Stream      bool            `json:"stream"`
+		Messages    []wireMessage   `json:"messages"`
+		Tools       []wireTool      `json:"tools"`
+		ToolChoice  json.RawMessage `json:"tool_choice"`
+		Metadata    struct {
+			UserID string `json:"user_id"`
+		} `json:"metadata"`
+	}
+	if err := unmarshal(content, &body); err != nil {
+		return nil, err
+	}
+	system, _, err := parseContent(body.System)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system: %w", err)
+	}
+	request := &Request{
+		API:         APIMessages,
+		Model:       body.Model,
+		MaxTokens:   body.MaxTokens,
+		Temperature: body.Temperature,
+		Stream:      body.Stream,
+		User:        body.Metadata.UserID,
+		System:      system,
+	}
+	return request, fill(request, body.Messages, body.Tools, body.ToolChoice)
+}
+
+// UsesTools returns true if the model may call tools
+func (r *Request) UsesTools() bool {
+	return len(r.Tools) != 0 && (r.ToolChoice == nil || r.ToolChoice.Type != "none")
+}
+
+// Text returns the text sent to the model: system prompt, messages and input
+func (r *Request) Text() string {
+	var texts []string
+	if r.System != "" {
+		texts = append(texts, r.System)
+	}
+	for _, message := range r.Messages {
+		if message.Text != "" {
+			texts = append(texts, message.Text)
+		}
+	}
+	texts = append(texts, r.Input...)
+	return strings.Join(texts, "\n")
+}
+
+func fill(request *Request, messages []wireMessage, tools []wireTool, toolChoice json.RawMessage) error {
+	for _, m := range messages {
+		text, contentTypes, err := parseContent(m.Content)
+		if err != nil {
+			return fmt.Errorf("invalid %s message content: %w", m.Role, err)
+		}
+		// items that are not messages (function calls and outputs of the responses api) are kept with their type
+		if m.Role == "" && m.Type != "" {
+			contentTypes = []string{m.Type}
+		}
+		request.Messages = append(request.Messages, Message{
+			Role:         m.Role,
+			Text:         text,
+			ContentTypes: contentTypes,
+		})
+	}
+	for _, t := range tools {
+		tool := Tool{
+			Type: t.Type,
+			Name: t.Name,
+		}
+		// anthropic client tools have no type
+		if tool.Type == "" {
+			tool.Type = "function"
+		}
+		if t.Function != nil && tool.Name == "" {
+			tool.Name = t.Function.Name
+		}
+		request.Tools = append(request.Tools, tool)
+	}
+	choice, err := parseToolChoice(toolChoice)
+	if err != nil {
+		return fmt.Errorf("invalid tool choice: %w", err)
+	}
+	request.ToolChoice = choice
+	return nil
+}
+
+// parseContent returns the text and part types of a content, which is either a string or a list of parts
+func parseContent(raw json.RawMessage) (string, []string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := unmarshal(raw, &text); err == nil {
+		return text, []string{"text"}, nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := unmarshal(raw, &parts); err != nil {
+		return "", nil, err
+	}
+	var texts, contentTypes []string
+	for _, part := range parts {
+		contentTypes = append(contentTypes, part.Type)
+		if textPartTypes[part.Type] {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n"), contentTypes, nil
+}
+
+func parseToolChoice(raw json.RawMessage) (*ToolChoice, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var mode string
+	if err := unmarshal(raw, &mode); err == nil {
+		return &ToolChoice{Type: mode}, nil
+	}
+	var choice wireTool
+	if err := unmarshal(raw, &choice); err != nil {
+		return nil, err
+	}
+	name := choice.Name
+	if choice.Function != nil && name == "" {
+		name = choice.Function.Name
+	}
+	return &ToolChoice{Type: choice.Type, Name: name}, nil
+}
+
+// stringList returns the strings of a string or list input, token arrays are ignored
+func stringList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var text string
+	if err := unmarshal(raw, &text); err == nil {
+		return []string{text}, nil
+	}
+	var items []any
+	if err := unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	var texts []string
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			texts = append(texts, text)
+		}
+	}
+	return texts, nil
+}
+
+// unmarshal decodes like json.Unmarshal but keys must match exactly and can't be repeated. encoding/json matches keys
+// case insensitively and keeps the last duplicate, a server matching keys otherwise would see a different request
+// than the policy, for example {"model":"expensive","MODEL":"cheap"}.
+func unmarshal(data []byte, v any) error {
+	if err := checkKeys(data, reflect.TypeOf(v)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// checkKeys checks the keys of the objects decoded into the structs of t, invalid json is left to json.Unmarshal
+func checkKeys(data []byte, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct:
+		return checkObjectKeys(data, t)
+	case t.Kind() == reflect.Slice && t != rawMessageType:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return nil
+		}
+		for _, item := range items {
+			if err := checkKeys(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkObjectKeys(data []byte, t reflect.Type) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	seen := map[string]bool{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil
+		}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || !strings.EqualFold(name, key) {
+				continue
+			}
+			if name != key {
+				return fmt.Errorf("invalid key %q, expected %q", key, name)
+			}
+			if seen[name] {
+				return fmt.Errorf("duplicate key %q", key)
+			}
+			seen[name] = true
+			if err := checkKeys(value, field.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
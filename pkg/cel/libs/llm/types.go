@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	RequestType    = types.NewObjectType("llm.Request")
+	MessageType    = types.NewObjectType("llm.Message")
+	ToolType       = types.NewObjectType("llm.Tool")
+	ToolChoiceType = types.NewObjectType("llm.ToolChoice")
+)
+
+// APIs a request can be parsed for
+const (
+	APIChatCompletions = "chat/completions"
+	APICompletions     = "completions"
+	APIEmbeddings      = "embeddings"
+	APIResponses       = "responses"
+	APIMessages        = "messages"
+)
+
+// Request is the provider agnostic description of a model request body
+type Request struct {
+	API         string      `json:"api"                  cel:"api"`
+	Model       string      `json:"model"                cel:"model"`
+	MaxTokens   int         `json:"maxTokens"            cel:"maxTokens"`
+	Temperature float64     `json:"temperature"          cel:"temperature"`
+	Stream      bool        `json:"stream"               cel:"stream"`
+	User        string      `json:"user"                 cel:"user"`
+	System      string      `json:"system"               cel:"system"`
+	Messages    []Message   `json:"messages"             cel:"messages"`
+	Input       []string    `json:"input"                cel:"input"`
+	Tools       []Tool      `json:"tools"                cel:"tools"`
+	ToolChoice  *ToolChoice `json:"toolChoice,omitempty" cel:"toolChoice"`
+}
+
+type Message struct {
+	Role string `json:"role" cel:"role"`
+	// Text is the concatenation of the text content of the message
+	Text string `json:"text" cel:"text"`
+	// ContentTypes lists the types of the content parts (text, image_url, tool_use, ...), a plain string content is a text part
+	ContentTypes []string `json:"contentTypes" cel:"contentTypes"`
+}
+
+// Tool is a tool declared in the request, client side tools have the function type
+type Tool struct {
+	Type string `json:"type" cel:"type"`
+	Name string `json:"name" cel:"name"`
+}
+
+// ToolChoice tells how the model should use tools, Name is set when a specific tool is forced
+type ToolChoice struct {
+	Type string `json:"type" cel:"type"`
+	Name string `json:"name" cel:"name"`
+}
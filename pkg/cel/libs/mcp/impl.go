@@ -29,6 +29,34 @@ func (c *impl) mcp_parse(mcp, value ref.Val) ref.Val {
 	return c.NativeToValue(mcpRequest)
 }
 
+func (c *impl) mcp_parse_batch(mcp, value ref.Val) ref.Val {
+	mcpImpl, err := utils.ConvertToNative[MCP](mcp)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+
+	stringBody, err := utils.ConvertToNative[string](value)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+
+	mcpRequests, err := mcpImpl.ParseBatch([]byte(stringBody))
+	if err != nil {
+		return types.WrapErr(err)
+	}
+
+	return c.NativeToValue(mcpRequests)
+}
+
+func (c *impl) mcp_is_batch(_, value ref.Val) ref.Val {
+	stringBody, err := utils.ConvertToNative[string](value)
+	if err != nil {
+		return types.WrapErr(err)
+	}
+
+	return types.Bool(IsBatch([]byte(stringBody)))
+}
+
 func (c *impl) mcp_get_string(args ...ref.Val) ref.Val {
 	mcpRequest, err := utils.ConvertToNative[*MCPRequest](args[0])
 	if err != nil {
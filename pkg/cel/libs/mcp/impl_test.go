@@ -16,13 +16,18 @@ import (
 
 // mockMCPImpl is a mock implementation of MCPImpl for tests
 type mockMCPImpl struct {
-	parseFn func([]byte) (*MCPRequest, error)
+	parseFn      func([]byte) (*MCPRequest, error)
+	parseBatchFn func([]byte) ([]*MCPRequest, error)
 }
 
 func (m *mockMCPImpl) Parse(b []byte) (*MCPRequest, error) {
 	return m.parseFn(b)
 }
 
+func (m *mockMCPImpl) ParseBatch(b []byte) ([]*MCPRequest, error) {
+	return m.parseBatchFn(b)
+}
+
 // setupTestEnv creates a CEL environment with MCPRequest type registered and returns an impl instance
 func setupTestEnv(t *testing.T) *impl {
 	env, err := cel.NewEnv(
@@ -200,6 +205,118 @@ func TestImpl_mcp_parse(t *testing.T) {
 	}
 }
 
+func TestImpl_mcp_parse_batch(t *testing.T) {
+	tests := []struct {
+		name        string
+		mcpImpl     MCPImpl
+		value       any
+		want        any
+		expectError bool
+	}{
+		{
+			name: "successful parse",
+			mcpImpl: &mockMCPImpl{
+				parseBatchFn: func(b []byte) ([]*MCPRequest, error) {
+					if string(b) != `[{"foo":"bar"},{"bar":"baz"}]` {
+						return nil, fmt.Errorf("unexpected body: %s", string(b))
+					}
+					return []*MCPRequest{{Method: "hello"}, {Method: "world"}}, nil
+				},
+			},
+			value:       `[{"foo":"bar"},{"bar":"baz"}]`,
+			want:        []*MCPRequest{{Method: "hello"}, {Method: "world"}},
+			expectError: false,
+		},
+		{
+			name: "ParseBatch returns error",
+			mcpImpl: &mockMCPImpl{
+				parseBatchFn: func(b []byte) ([]*MCPRequest, error) {
+					return nil, errors.New("parse err")
+				},
+			},
+			value:       `anything`,
+			want:        "parse err",
+			expectError: true,
+		},
+		{
+			name:        "cannot convert mcp native type",
+			mcpImpl:     nil, // purposely pass wrong type
+			value:       `[]`,
+			want:        "type conversion error",
+			expectError: true,
+		},
+		{
+			name: "cannot convert value to string",
+			mcpImpl: &mockMCPImpl{
+				parseBatchFn: func(b []byte) ([]*MCPRequest, error) { return nil, nil },
+			},
+			value:       1234,
+			want:        "unsupported type conversion",
+			expectError: true,
+		},
+	}
+
+	impl := setupTestEnvWithMCP(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mcpVal any
+			if tt.mcpImpl != nil {
+				mcpVal = MCP{tt.mcpImpl}
+			} else {
+				mcpVal = struct{}{} // trigger conversion error
+			}
+			mcpRefVal := impl.NativeToValue(mcpVal)
+			valueRefVal := impl.NativeToValue(tt.value)
+
+			got := impl.mcp_parse_batch(mcpRefVal, valueRefVal)
+
+			if tt.expectError {
+				if got.Type() != types.ErrType {
+					t.Errorf("Expected error, got %v (type %v)", got, got.Type())
+				}
+				if s, ok := got.Value().(error); ok {
+					if !strings.Contains(s.Error(), tt.want.(string)) {
+						t.Errorf("Expected error containing %q, got %v", tt.want, s)
+					}
+				}
+			} else {
+				native, err := got.ConvertToNative(reflect.TypeFor[[]*MCPRequest]())
+				if err != nil {
+					t.Fatalf("ConvertToNative err: %v", err)
+				}
+				if !reflect.DeepEqual(native, tt.want) {
+					t.Errorf("got %v, want %v", native, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestImpl_mcp_is_batch(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "batch", value: `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, want: true},
+		{name: "batch with leading spaces", value: "\n  [{}]", want: true},
+		{name: "single message", value: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, want: false},
+		{name: "empty", value: ``, want: false},
+	}
+
+	impl := setupTestEnvWithMCP(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := impl.mcp_is_batch(impl.NativeToValue(MCP{}), impl.NativeToValue(tt.value))
+			if got != types.Bool(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestImpl_mcp_get_string(t *testing.T) {
 	tests := []struct {
 		name         string
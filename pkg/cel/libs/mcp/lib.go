@@ -65,6 +65,15 @@ func (l *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 		"ToolsCallMethod":              string(mcp.MethodToolsCall),
 		"SetLogLevelMethod":            string(mcp.MethodSetLogLevel),
 		"ElicitationCreateMethod":      string(mcp.MethodElicitationCreate),
+		"ResourcesSubscribeMethod":     MethodResourcesSubscribe,
+		"ResourcesUnsubscribeMethod":   MethodResourcesUnsubscribe,
+		"SamplingCreateMessageMethod":  string(mcp.MethodSamplingCreateMessage),
+		"CompletionCompleteMethod":     string(mcp.MethodCompletionComplete),
+		"RootsListMethod":              string(mcp.MethodListRoots),
+		"TasksGetMethod":               string(mcp.MethodTasksGet),
+		"TasksListMethod":              string(mcp.MethodTasksList),
+		"TasksResultMethod":            string(mcp.MethodTasksResult),
+		"TasksCancelMethod":            string(mcp.MethodTasksCancel),
 	}
 
 	libraryDecls := map[string][]cel.FunctionOpt{
@@ -75,6 +84,20 @@ func (l *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
 				cel.BinaryBinding(impl.mcp_parse),
 			),
 		},
+		"ParseBatch": {
+			cel.MemberOverload("mcp_parse_batch_bytes_dyn",
+				[]*cel.Type{MCPType, types.DynType},
+				cel.ListType(MCPRequestType),
+				cel.BinaryBinding(impl.mcp_parse_batch),
+			),
+		},
+		"IsBatch": {
+			cel.MemberOverload("mcp_is_batch_dyn",
+				[]*cel.Type{MCPType, types.DynType},
+				types.BoolType,
+				cel.BinaryBinding(impl.mcp_is_batch),
+			),
+		},
 		"GetStringArgument": {
 			cel.MemberOverload("mcp_get_string",
 				[]*cel.Type{MCPRequestType, types.StringType, types.StringType},
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"bytes"
 	"strconv"
 
 	"github.com/google/cel-go/common/types"
@@ -12,8 +13,21 @@ var (
 	MCPRequestType = types.NewObjectType("mcp.MCPRequest")
 )
 
+// Methods not declared by the mcp-go library
+const (
+	MethodResourcesSubscribe      = "resources/subscribe"
+	MethodResourcesUnsubscribe    = "resources/unsubscribe"
+	MethodNotificationInitialized = "notifications/initialized"
+	MethodNotificationCancelled   = "notifications/cancelled"
+	MethodNotificationProgress    = "notifications/progress"
+	MethodNotificationMessage     = "notifications/message"
+)
+
 type MCPImpl interface {
+	// Parse parses a single JSON-RPC message, batches are rejected
 	Parse([]byte) (*MCPRequest, error)
+	// ParseBatch parses a JSON-RPC batch, a single message is returned as a batch of one message
+	ParseBatch([]byte) ([]*MCPRequest, error)
 }
 
 type MCP struct {
@@ -24,6 +38,14 @@ type MCPRequest struct {
 	Method string
 	ID     mcp.RequestId
 
+	// Notification is set for messages without id, no response is expected
+	Notification bool
+	// Response is set for responses to a previous request, Method is empty and either Result or Error is set,
+	// Result holds the raw JSON result
+	Response bool
+	Result   string
+	Error    *mcp.JSONRPCErrorDetails
+
 	Paginated *mcp.PaginatedParams // For all list methods
 
 	// Tools
@@ -33,6 +55,7 @@ type MCPRequest struct {
 	ResourceRead        *mcp.ReadResourceParams
 	ResourceSubscribe   *mcp.SubscribeParams
 	ResourceUnsubscribe *mcp.UnsubscribeParams
+	ResourceUpdated     *mcp.ResourceUpdatedNotificationParams
 
 	// Prompts
 	PromptGet *mcp.GetPromptParams
@@ -46,9 +69,25 @@ type MCPRequest struct {
 	// Elicitation
 	Elicitation *mcp.ElicitationParams
 
+	// Tasks
+	TaskGet    *mcp.GetTaskParams
+	TaskResult *mcp.TaskResultParams
+	TaskCancel *mcp.CancelTaskParams
+
 	// Utilities
 	Complete    *mcp.CompleteParams
 	SetLogLevel *mcp.SetLevelParams
+
+	// Notifications
+	Cancelled  *mcp.CancelledNotificationParams
+	Progress   *mcp.ProgressNotificationParams
+	LogMessage *mcp.LoggingMessageNotificationParams
+}
+
+// IsBatch returns true if the content is a JSON-RPC batch
+func IsBatch(content []byte) bool {
+	content = bytes.TrimSpace(content)
+	return len(content) != 0 && content[0] == '['
 }
 
 // GetArguments returns the Arguments from ToolCall, ResourceRead, or PromptGet as map[string]any
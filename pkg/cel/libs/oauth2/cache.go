@@ -0,0 +1,80 @@
+package oauth2
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxTTL bounds how long responses are cached, revoked tokens are detected after at most this delay
+	maxTTL = 5 * time.Minute
+	// maxEntries bounds the number of cached responses
+	maxEntries = 10000
+)
+
+type entry[T any] struct {
+	key     [sha256.Size]byte
+	value   T
+	expires time.Time
+}
+
+// cache keeps responses in memory, keyed by a hash of the request so that tokens are not kept in memory,
+// the least recently used responses are evicted when maxEntries is reached
+type cache[T any] struct {
+	maxEntries int
+	lock       sync.Mutex
+	lru        *list.List
+	entries    map[[sha256.Size]byte]*list.Element
+}
+
+func newCache[T any]() *cache[T] {
+	return &cache[T]{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    map[[sha256.Size]byte]*list.Element{},
+	}
+}
+
+func cacheKey(parts ...string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+}
+
+func (c *cache[T]) get(key [sha256.Size]byte, now time.Time) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	entry := element.Value.(*entry[T])
+	if !now.Before(entry.expires) {
+		delete(c.entries, key)
+		c.lru.Remove(element)
+		var zero T
+		return zero, false
+	}
+	c.lru.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *cache[T]) set(key [sha256.Size]byte, value T, now time.Time, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, ok := c.entries[key]; ok {
+		delete(c.entries, key)
+		c.lru.Remove(element)
+	}
+	for c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*entry[T]).key)
+		c.lru.Remove(oldest)
+	}
+	c.entries[key] = c.lru.PushFront(&entry[T]{key: key, value: value, expires: now.Add(ttl)})
+}
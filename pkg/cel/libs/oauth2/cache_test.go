@@ -0,0 +1,41 @@
+package oauth2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCache[string]()
+	c.maxEntries = 2
+	a, b, d := cacheKey("a"), cacheKey("b"), cacheKey("d")
+	c.set(a, "a", now, time.Minute)
+	c.set(b, "b", now, time.Minute)
+	// a is used after b was set, b is now the least recently used
+	got, ok := c.get(a, now)
+	assert.True(t, ok)
+	assert.Equal(t, "a", got)
+	c.set(d, "d", now, time.Minute)
+	_, ok = c.get(b, now)
+	assert.False(t, ok)
+	_, ok = c.get(a, now)
+	assert.True(t, ok)
+	_, ok = c.get(d, now)
+	assert.True(t, ok)
+	// setting a known key replaces it without evicting
+	c.set(d, "d2", now, time.Minute)
+	assert.Len(t, c.entries, 2)
+	got, _ = c.get(d, now)
+	assert.Equal(t, "d2", got)
+	// expired entries are removed when they are read
+	_, ok = c.get(a, now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Len(t, c.entries, 1)
+	// responses without ttl are not cached
+	c.set(b, "b", now, 0)
+	_, ok = c.get(b, now)
+	assert.False(t, ok)
+}
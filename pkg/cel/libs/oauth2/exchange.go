@@ -0,0 +1,88 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+var defaultExchanger = newExchanger(&http.Client{Timeout: 10 * time.Second})
+
+// Exchange exchanges the subject token at the token endpoint, issued tokens are cached by the process
+func Exchange(ctx context.Context, endpoint string, subjectToken string, credentials Credentials, options ExchangeOptions) (*Token, error) {
+	return defaultExchanger.exchange(ctx, endpoint, subjectToken, credentials, options)
+}
+
+type exchanger struct {
+	client *http.Client
+	cache  *cache[*Token]
+}
+
+func newExchanger(client *http.Client) *exchanger {
+	return &exchanger{
+		client: client,
+		cache:  newCache[*Token](),
+	}
+}
+
+func (e *exchanger) exchange(ctx context.Context, endpoint string, subjectToken string, credentials Credentials, options ExchangeOptions) (*Token, error) {
+	form := options.form(subjectToken)
+	key := cacheKey(endpoint, credentials.ClientID, form.Encode())
+	now := time.Now()
+	if token, ok := e.cache.get(key, now); ok {
+		return token, nil
+	}
+	body, err := post(ctx, e.client, endpoint, form, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token exchange failed: response has no access_token")
+	}
+	// issued tokens are reused for half their lifetime so that backends never receive a token about to expire,
+	// tokens without expires_in are not cached
+	e.cache.set(key, &token, now, min(time.Duration(token.ExpiresIn)*time.Second/2, maxTTL))
+	return &token, nil
+}
+
+func (o ExchangeOptions) form(subjectToken string) url.Values {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {o.SubjectTokenType},
+	}
+	if form.Get("subject_token_type") == "" {
+		form.Set("subject_token_type", AccessTokenType)
+	}
+	if o.ActorToken != "" {
+		form.Set("actor_token", o.ActorToken)
+		form.Set("actor_token_type", o.ActorTokenType)
+		if o.ActorTokenType == "" {
+			form.Set("actor_token_type", AccessTokenType)
+		}
+	}
+	if o.RequestedTokenType != "" {
+		form.Set("requested_token_type", o.RequestedTokenType)
+	}
+	if len(o.Audience) > 0 {
+		form["audience"] = o.Audience
+	}
+	if len(o.Resource) > 0 {
+		form["resource"] = o.Resource
+	}
+	if scope := strings.TrimSpace(o.Scope); scope != "" {
+		form.Set("scope", scope)
+	}
+	return form
+}
@@ -0,0 +1,57 @@
+package oauth2
+
+import (
+	"context"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) introspect_string_string_credentials(args ...ref.Val) ref.Val {
+	if endpoint, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if token, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if credentials, err := utils.ConvertToNative[*Credentials](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if introspection, err := Introspect(context.Background(), endpoint, token, *credentials); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(introspection)
+	}
+}
+
+func (c *impl) exchange_string_string_credentials(args ...ref.Val) ref.Val {
+	return c.exchange_string_string_credentials_options(append(args, c.NativeToValue(&ExchangeOptions{}))...)
+}
+
+func (c *impl) exchange_string_string_credentials_options(args ...ref.Val) ref.Val {
+	if endpoint, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if token, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if credentials, err := utils.ConvertToNative[*Credentials](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if options, err := utils.ConvertToNative[*ExchangeOptions](args[3]); err != nil {
+		return types.WrapErr(err)
+	} else if token, err := Exchange(context.Background(), endpoint, token, *credentials, *options); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(token)
+	}
+}
+
+func (c *impl) introspection_has_scope_string(introspection ref.Val, scope ref.Val) ref.Val {
+	if introspection, err := utils.ConvertToNative[*Introspection](introspection); err != nil {
+		return types.WrapErr(err)
+	} else if scope, err := utils.ConvertToNative[string](scope); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(introspection.HasScope(scope))
+	}
+}
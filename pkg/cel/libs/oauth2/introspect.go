@@ -0,0 +1,130 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// inactiveTTL is how long inactive tokens, and active tokens without exp claim, are cached
+	inactiveTTL = time.Minute
+	// maxResponseSize bounds the size of responses
+	maxResponseSize = 1 << 20
+)
+
+var defaultIntrospector = newIntrospector(&http.Client{Timeout: 10 * time.Second})
+
+// Introspect returns the introspection response for the token, responses are cached by the process
+func Introspect(ctx context.Context, endpoint string, token string, credentials Credentials) (*Introspection, error) {
+	return defaultIntrospector.introspect(ctx, endpoint, token, credentials)
+}
+
+type introspector struct {
+	client *http.Client
+	cache  *cache[*Introspection]
+}
+
+func newIntrospector(client *http.Client) *introspector {
+	return &introspector{
+		client: client,
+		cache:  newCache[*Introspection](),
+	}
+}
+
+func (i *introspector) introspect(ctx context.Context, endpoint string, token string, credentials Credentials) (*Introspection, error) {
+	key := cacheKey(endpoint, credentials.ClientID, token)
+	now := time.Now()
+	if introspection, ok := i.cache.get(key, now); ok {
+		return introspection, nil
+	}
+	introspection, err := i.request(ctx, endpoint, token, credentials)
+	if err != nil {
+		return nil, err
+	}
+	ttl := inactiveTTL
+	if introspection.Active && introspection.Exp > 0 {
+		ttl = min(time.Unix(introspection.Exp, 0).Sub(now), maxTTL)
+	}
+	i.cache.set(key, introspection, now, ttl)
+	return introspection, nil
+}
+
+func (i *introspector) request(ctx context.Context, endpoint string, token string, credentials Credentials) (*Introspection, error) {
+	body, err := post(ctx, i.client, endpoint, url.Values{"token": {token}}, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("token introspection failed: %w", err)
+	}
+	return parse(body)
+}
+
+// post sends a form to an authorization server endpoint and returns the response body
+func post(ctx context.Context, client *http.Client, endpoint string, form url.Values, credentials Credentials) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client credentials are form encoded before basic auth, see https://www.rfc-editor.org/rfc/rfc6749#section-2.3.1
+	req.SetBasicAuth(url.QueryEscape(credentials.ClientID), url.QueryEscape(credentials.ClientSecret))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// error responses carry an error code, see https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			if oauthErr.Description != "" {
+				return nil, fmt.Errorf("status code %d: %s: %s", resp.StatusCode, oauthErr.Error, oauthErr.Description)
+			}
+			return nil, fmt.Errorf("status code %d: %s", resp.StatusCode, oauthErr.Error)
+		}
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+func parse(body []byte) (*Introspection, error) {
+	var introspection Introspection
+	if err := json.Unmarshal(body, &introspection); err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, err
+	}
+	// aud is either a string or a list of strings
+	switch aud := claims["aud"].(type) {
+	case string:
+		introspection.Aud = []string{aud}
+	case []any:
+		for _, aud := range aud {
+			if aud, ok := aud.(string); ok {
+				introspection.Aud = append(introspection.Aud, aud)
+			}
+		}
+	}
+	encoded, err := structpb.NewStruct(claims)
+	if err != nil {
+		return nil, err
+	}
+	introspection.Claims = encoded
+	return &introspection, nil
+}
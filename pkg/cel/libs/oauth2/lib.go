@@ -0,0 +1,76 @@
+package oauth2
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.oauth2"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Introspection](),
+			reflect.TypeFor[Credentials](),
+			reflect.TypeFor[ExchangeOptions](),
+			reflect.TypeFor[Token](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// token type identifiers are exposed as constants
+	constants := map[string]string{
+		"AccessTokenType":  AccessTokenType,
+		"RefreshTokenType": RefreshTokenType,
+		"IDTokenType":      IDTokenType,
+		"JWTTokenType":     JWTTokenType,
+	}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"oauth2.Introspect": {
+			cel.Overload("oauth2_introspect_string_string_credentials", []*cel.Type{types.StringType, types.StringType, CredentialsType}, IntrospectionType, cel.FunctionBinding(impl.introspect_string_string_credentials)),
+		},
+		"oauth2.Exchange": {
+			cel.Overload("oauth2_exchange_string_string_credentials", []*cel.Type{types.StringType, types.StringType, CredentialsType}, TokenType, cel.FunctionBinding(impl.exchange_string_string_credentials)),
+			cel.Overload("oauth2_exchange_string_string_credentials_options", []*cel.Type{types.StringType, types.StringType, CredentialsType, ExchangeOptionsType}, TokenType, cel.FunctionBinding(impl.exchange_string_string_credentials_options)),
+		},
+		"hasScope": {
+			cel.MemberOverload("oauth2_introspection_has_scope_string", []*cel.Type{IntrospectionType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.introspection_has_scope_string)),
+		},
+	}
+	// create env options corresponding to our constants and function overloads
+	options := []cel.EnvOption{}
+	for name, value := range constants {
+		options = append(options, cel.Constant("oauth2."+name, types.StringType, types.String(value)))
+	}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
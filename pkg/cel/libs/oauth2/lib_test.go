@@ -0,0 +1,191 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLib(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		id, secret, _ := r.BasicAuth()
+		if id, _ = url.QueryUnescape(id); id != "authz" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if secret, _ = url.QueryUnescape(secret); secret != "s3cr%t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response := map[string]any{"active": false}
+		if r.PostFormValue("token") == "opaque" {
+			response = map[string]any{
+				"active":    true,
+				"scope":     "read write",
+				"client_id": "app",
+				"sub":       "alice",
+				"aud":       "api",
+				"exp":       time.Now().Add(time.Hour).Unix(),
+				"tenant":    "acme",
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name    string
+		source  string
+		token   string
+		want    any
+		wantErr bool
+	}{{
+		name:   "active",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).active`,
+		token:  "opaque",
+		want:   true,
+	}, {
+		name:   "fields",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).sub + " " + oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).clientId`,
+		token:  "opaque",
+		want:   "alice app",
+	}, {
+		name:   "audience",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).aud`,
+		token:  "opaque",
+		want:   []string{"api"},
+	}, {
+		name:   "scopes",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).hasScope("write")`,
+		token:  "opaque",
+		want:   true,
+	}, {
+		name:   "extension claims",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).claims.tenant`,
+		token:  "opaque",
+		want:   "acme",
+	}, {
+		name:   "inactive",
+		source: `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "s3cr%t"}).active`,
+		token:  "revoked",
+		want:   false,
+	}, {
+		name:    "unauthorized",
+		source:  `oauth2.Introspect(endpoint, token, oauth2.Credentials{clientId: "authz", clientSecret: "wrong"})`,
+		token:   "other",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("endpoint", cel.StringType), cel.Variable("token", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"endpoint": srv.URL, "token": tt.token})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				if want, ok := tt.want.([]string); ok {
+					got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				} else {
+					assert.Equal(t, tt.want, out.Value())
+				}
+			}
+		})
+	}
+	// responses are cached by token
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestExchange(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if id, _, _ := r.BasicAuth(); id != "gateway" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_client"})
+			return
+		}
+		if r.PostFormValue("grant_type") != "urn:ietf:params:oauth:grant-type:token-exchange" || r.PostFormValue("subject_token") != "external" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid_grant", "error_description": "unknown subject token"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "internal:" + r.PostFormValue("subject_token_type") + ":" + strings.Join(r.PostForm["audience"], ","),
+			"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"token_type":        "Bearer",
+			"expires_in":        600,
+			"scope":             r.PostFormValue("scope"),
+		})
+	}))
+	defer srv.Close()
+	tests := []struct {
+		name    string
+		source  string
+		token   string
+		want    any
+		wantErr bool
+	}{{
+		name:   "defaults",
+		source: `oauth2.Exchange(endpoint, token, oauth2.Credentials{clientId: "gateway", clientSecret: "secret"}).accessToken`,
+		token:  "external",
+		want:   "internal:urn:ietf:params:oauth:token-type:access_token:",
+	}, {
+		name:   "options",
+		source: `oauth2.Exchange(endpoint, token, oauth2.Credentials{clientId: "gateway", clientSecret: "secret"}, oauth2.ExchangeOptions{subjectTokenType: oauth2.JWTTokenType, audience: ["orders", "billing"], scope: "read"}).accessToken`,
+		token:  "external",
+		want:   "internal:urn:ietf:params:oauth:token-type:jwt:orders,billing",
+	}, {
+		name:   "cached",
+		source: `oauth2.Exchange(endpoint, token, oauth2.Credentials{clientId: "gateway", clientSecret: "secret"}, oauth2.ExchangeOptions{subjectTokenType: oauth2.JWTTokenType, audience: ["orders", "billing"], scope: "read"}).expiresIn`,
+		token:  "external",
+		want:   int64(600),
+	}, {
+		name:    "invalid grant",
+		source:  `oauth2.Exchange(endpoint, token, oauth2.Credentials{clientId: "gateway", clientSecret: "secret"})`,
+		token:   "unknown",
+		wantErr: true,
+	}, {
+		name:    "invalid client",
+		source:  `oauth2.Exchange(endpoint, token, oauth2.Credentials{clientId: "other", clientSecret: "secret"})`,
+		token:   "external",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("endpoint", cel.StringType), cel.Variable("token", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"endpoint": srv.URL, "token": tt.token})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+	// issued tokens are cached by request
+	assert.Equal(t, int32(4), hits.Load())
+}
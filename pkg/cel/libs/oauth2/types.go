@@ -0,0 +1,73 @@
+package oauth2
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	IntrospectionType   = types.NewObjectType("oauth2.Introspection")
+	CredentialsType     = types.NewObjectType("oauth2.Credentials")
+	ExchangeOptionsType = types.NewObjectType("oauth2.ExchangeOptions")
+	TokenType           = types.NewObjectType("oauth2.Token")
+)
+
+// Token type identifiers, see https://www.rfc-editor.org/rfc/rfc8693#section-3
+const (
+	AccessTokenType  = "urn:ietf:params:oauth:token-type:access_token"
+	RefreshTokenType = "urn:ietf:params:oauth:token-type:refresh_token"
+	IDTokenType      = "urn:ietf:params:oauth:token-type:id_token"
+	JWTTokenType     = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// Credentials authenticate the Authz Server with the authorization server
+type Credentials struct {
+	ClientID     string `cel:"clientId"`
+	ClientSecret string `cel:"clientSecret"`
+}
+
+// Introspection is a token introspection response, see https://www.rfc-editor.org/rfc/rfc7662#section-2.2
+type Introspection struct {
+	Active    bool     `json:"active"     cel:"active"`
+	Scope     string   `json:"scope"      cel:"scope"`
+	ClientID  string   `json:"client_id"  cel:"clientId"`
+	Username  string   `json:"username"   cel:"username"`
+	TokenType string   `json:"token_type" cel:"tokenType"`
+	Exp       int64    `json:"exp"        cel:"exp"`
+	Iat       int64    `json:"iat"        cel:"iat"`
+	Nbf       int64    `json:"nbf"        cel:"nbf"`
+	Sub       string   `json:"sub"        cel:"sub"`
+	Aud       []string `json:"-"          cel:"aud"`
+	Iss       string   `json:"iss"        cel:"iss"`
+	Jti       string   `json:"jti"        cel:"jti"`
+	// Claims holds the whole response, including extension claims
+	Claims *structpb.Struct `json:"-" cel:"claims"`
+}
+
+// HasScope returns true if the token was granted the given scope
+func (i *Introspection) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(i.Scope), scope)
+}
+
+// ExchangeOptions configures a token exchange request, see https://www.rfc-editor.org/rfc/rfc8693#section-2.1
+type ExchangeOptions struct {
+	SubjectTokenType   string   `cel:"subjectTokenType"`
+	ActorToken         string   `cel:"actorToken"`
+	ActorTokenType     string   `cel:"actorTokenType"`
+	RequestedTokenType string   `cel:"requestedTokenType"`
+	Audience           []string `cel:"audience"`
+	Resource           []string `cel:"resource"`
+	Scope              string   `cel:"scope"`
+}
+
+// Token is a token exchange response, see https://www.rfc-editor.org/rfc/rfc8693#section-2.2.1
+type Token struct {
+	AccessToken     string `json:"access_token"      cel:"accessToken"`
+	IssuedTokenType string `json:"issued_token_type" cel:"issuedTokenType"`
+	TokenType       string `json:"token_type"        cel:"tokenType"`
+	ExpiresIn       int64  `json:"expires_in"        cel:"expiresIn"`
+	Scope           string `json:"scope"             cel:"scope"`
+}
@@ -0,0 +1,143 @@
+package oidc
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// discoveryTTL is how long provider configurations are cached
+	discoveryTTL = time.Hour
+	// failureTTL is how long discovery failures are cached, so that a failing issuer is not fetched on every evaluation
+	failureTTL = 30 * time.Second
+	// maxIssuers bounds the number of cached issuers, the least recently used ones are evicted first
+	maxIssuers = 100
+	// maxConfigurationSize bounds the size of provider configuration documents
+	maxConfigurationSize = 1 << 20
+)
+
+var defaultDiscoverer = newDiscoverer(&http.Client{Timeout: 10 * time.Second}, discoveryTTL, maxIssuers)
+
+// Discover returns the configuration of an OpenID provider from the process wide cache.
+// The cache is bounded, the issuer is expected to be a constant of the policy and not a value taken from the request.
+func Discover(ctx context.Context, issuer string) (*Configuration, error) {
+	return defaultDiscoverer.discover(ctx, issuer)
+}
+
+type discovered struct {
+	issuer        string
+	configuration *Configuration
+	err           error
+	expires       time.Time
+}
+
+type discoverer struct {
+	client     *http.Client
+	ttl        time.Duration
+	maxIssuers int
+	group      singleflight.Group
+	lock       sync.Mutex
+	lru        *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+func newDiscoverer(client *http.Client, ttl time.Duration, maxIssuers int) *discoverer {
+	return &discoverer{
+		client:     client,
+		ttl:        ttl,
+		maxIssuers: maxIssuers,
+		lru:        list.New(),
+		entries:    map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+func (d *discoverer) discover(ctx context.Context, issuer string) (*Configuration, error) {
+	if entry, ok := d.get(issuer); ok {
+		return entry.configuration, entry.err
+	}
+	// concurrent evaluations share the same fetch, it must not be cancelled when the first caller goes away
+	out, _, _ := d.group.Do(issuer, func() (any, error) {
+		if entry, ok := d.get(issuer); ok {
+			return entry, nil
+		}
+		configuration, err := d.fetch(context.WithoutCancel(ctx), issuer)
+		ttl := d.ttl
+		if err != nil {
+			ttl = min(ttl, failureTTL)
+		}
+		entry := &discovered{issuer: issuer, configuration: configuration, err: err, expires: d.now().Add(ttl)}
+		d.put(entry)
+		return entry, nil
+	})
+	entry := out.(*discovered)
+	return entry.configuration, entry.err
+}
+
+func (d *discoverer) get(issuer string) (*discovered, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	element, ok := d.entries[issuer]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*discovered)
+	if !d.now().Before(entry.expires) {
+		delete(d.entries, issuer)
+		d.lru.Remove(element)
+		return nil, false
+	}
+	d.lru.MoveToFront(element)
+	return entry, true
+}
+
+func (d *discoverer) put(entry *discovered) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if element, ok := d.entries[entry.issuer]; ok {
+		delete(d.entries, entry.issuer)
+		d.lru.Remove(element)
+	}
+	for d.maxIssuers > 0 && len(d.entries) >= d.maxIssuers {
+		oldest := d.lru.Back()
+		delete(d.entries, oldest.Value.(*discovered).issuer)
+		d.lru.Remove(oldest)
+	}
+	d.entries[entry.issuer] = d.lru.PushFront(entry)
+}
+
+func (d *discoverer) fetch(ctx context.Context, issuer string) (*Configuration, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to discover %q, unexpected status code %d", url, resp.StatusCode)
+	}
+	var configuration Configuration
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxConfigurationSize)).Decode(&configuration); err != nil {
+		return nil, err
+	}
+	// the issuer must match to prevent impersonation, see https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if configuration.Issuer != issuer {
+		return nil, fmt.Errorf("discovered issuer %q doesn't match %q", configuration.Issuer, issuer)
+	}
+	return &configuration, nil
+}
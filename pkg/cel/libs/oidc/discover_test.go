@@ -0,0 +1,97 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverer(t *testing.T) {
+	var hits atomic.Int32
+	var failing atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		issuer := "http://" + r.Host + strings.TrimSuffix(r.URL.Path, "/.well-known/openid-configuration")
+		_ = json.NewEncoder(w).Encode(map[string]any{"issuer": issuer})
+	})
+	srv.Start()
+	defer srv.Close()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var lock sync.Mutex
+	d := newDiscoverer(srv.Client(), time.Hour, 2)
+	d.now = func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return now
+	}
+	advance := func(duration time.Duration) {
+		lock.Lock()
+		defer lock.Unlock()
+		now = now.Add(duration)
+	}
+	t.Run("concurrent discoveries share a fetch", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				configuration, err := d.discover(context.Background(), srv.URL+"/a")
+				assert.NoError(t, err)
+				assert.Equal(t, srv.URL+"/a", configuration.Issuer)
+			})
+		}
+		// let the callers join the fetch before answering
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(1), hits.Load())
+	})
+	t.Run("failures are cached briefly", func(t *testing.T) {
+		hits.Store(0)
+		failing.Store(true)
+		_, err := d.discover(context.Background(), srv.URL+"/b")
+		assert.Error(t, err)
+		_, err = d.discover(context.Background(), srv.URL+"/b")
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), hits.Load())
+		failing.Store(false)
+		advance(failureTTL)
+		_, err = d.discover(context.Background(), srv.URL+"/b")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), hits.Load())
+	})
+	t.Run("least recently used issuers are evicted", func(t *testing.T) {
+		hits.Store(0)
+		// a is used after b was discovered, b is now the least recently used
+		_, err := d.discover(context.Background(), srv.URL+"/a")
+		assert.NoError(t, err)
+		_, err = d.discover(context.Background(), srv.URL+"/c")
+		assert.NoError(t, err)
+		assert.Len(t, d.entries, 2)
+		_, ok := d.get(srv.URL + "/b")
+		assert.False(t, ok)
+		_, ok = d.get(srv.URL + "/a")
+		assert.True(t, ok)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+	t.Run("configurations expire", func(t *testing.T) {
+		hits.Store(0)
+		advance(time.Hour)
+		_, err := d.discover(context.Background(), srv.URL+"/a")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+}
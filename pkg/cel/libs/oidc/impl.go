@@ -0,0 +1,34 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/jwk"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) discover_string(issuer ref.Val) ref.Val {
+	if issuer, err := utils.ConvertToNative[string](issuer); err != nil {
+		return types.WrapErr(err)
+	} else if configuration, err := Discover(context.Background(), issuer); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(configuration)
+	}
+}
+
+func (c *impl) configuration_jwks(configuration ref.Val) ref.Val {
+	if configuration, err := utils.ConvertToNative[*Configuration](configuration); err != nil {
+		return types.WrapErr(err)
+	} else if set, err := jwk.Fetch(context.Background(), configuration.JwksURI); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(jwk.Set{Set: set})
+	}
+}
@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/jwk"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.oidc"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register jwk lib
+		jwk.Lib(),
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Configuration](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"oidc.Discover": {
+			cel.Overload("oidc_discover_string", []*cel.Type{types.StringType}, ConfigurationType, cel.UnaryBinding(impl.discover_string)),
+		},
+		"jwks": {
+			cel.MemberOverload("oidc_configuration_jwks", []*cel.Type{ConfigurationType}, jwk.SetType, cel.UnaryBinding(impl.configuration_jwks)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
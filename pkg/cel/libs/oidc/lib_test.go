@@ -0,0 +1,103 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLib(t *testing.T) {
+	const jwks = `{"keys":[{"alg":"ES256","crv":"P-256","kid":"my-key-id","kty":"EC","use":"sig","x":"iTV4PECbWuDaNBMTLmwH0jwBTD3xUXR0S-VWsCYv8Gc","y":"-Cnw8d0XyQztrPZpynrFn8t10lyEb6oWqWcLJWPUB5A"}]}`
+	hits := 0
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 srv.URL,
+			"jwks_uri":               srv.URL + "/keys",
+			"introspection_endpoint": srv.URL + "/introspect",
+			"scopes_supported":       []string{"openid", "email"},
+		})
+	})
+	mux.HandleFunc("/other/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"issuer": "https://attacker.example.com"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(jwks))
+	})
+	tests := []struct {
+		name    string
+		source  string
+		issuer  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "endpoints",
+		source: `oidc.Discover(issuer).introspectionEndpoint`,
+		issuer: srv.URL,
+		want:   srv.URL + "/introspect",
+	}, {
+		name:   "scopes",
+		source: `oidc.Discover(issuer).scopesSupported`,
+		issuer: srv.URL,
+		want:   []string{"openid", "email"},
+	}, {
+		name:    "issuer mismatch",
+		source:  `oidc.Discover(issuer)`,
+		issuer:  srv.URL + "/other",
+		wantErr: true,
+	}, {
+		name:    "not found",
+		source:  `oidc.Discover(issuer)`,
+		issuer:  srv.URL + "/missing",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("issuer", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"issuer": tt.issuer})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				if want, ok := tt.want.([]string); ok {
+					got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+					assert.NoError(t, err)
+					assert.Equal(t, want, got)
+				} else {
+					assert.Equal(t, tt.want, out.Value())
+				}
+			}
+		})
+	}
+	t.Run("jwks", func(t *testing.T) {
+		env, err := cel.NewEnv(Lib(), cel.Variable("issuer", cel.StringType))
+		require.NoError(t, err)
+		ast, issues := env.Compile(`oidc.Discover(issuer).jwks()`)
+		require.NoError(t, issues.Err())
+		prog, err := env.Program(ast)
+		require.NoError(t, err)
+		out, _, err := prog.Eval(map[string]any{"issuer": srv.URL})
+		require.NoError(t, err)
+		set, ok := out.Value().(jwk.Set)
+		require.True(t, ok)
+		_, ok = set.LookupKeyID("my-key-id")
+		assert.True(t, ok)
+	})
+	// configurations are cached
+	assert.Equal(t, 1, hits)
+}
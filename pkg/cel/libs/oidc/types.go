@@ -0,0 +1,26 @@
+package oidc
+
+import (
+	"github.com/google/cel-go/common/types"
+)
+
+var ConfigurationType = types.NewObjectType("oidc.Configuration")
+
+// Configuration is an OpenID provider configuration, see https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type Configuration struct {
+	Issuer                            string   `json:"issuer"                                cel:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"                cel:"authorizationEndpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"                        cel:"tokenEndpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"                     cel:"userinfoEndpoint"`
+	JwksURI                           string   `json:"jwks_uri"                              cel:"jwksUri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"                cel:"introspectionEndpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"                   cel:"revocationEndpoint"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint"                  cel:"endSessionEndpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"                      cel:"scopesSupported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"              cel:"responseTypesSupported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"                 cel:"grantTypesSupported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"               cel:"subjectTypesSupported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported" cel:"idTokenSigningAlgValuesSupported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported" cel:"tokenEndpointAuthMethodsSupported"`
+	ClaimsSupported                   []string `json:"claims_supported"                      cel:"claimsSupported"`
+}
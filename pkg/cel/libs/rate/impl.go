@@ -0,0 +1,74 @@
+package rate
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+	limiter Limiter
+}
+
+func (c *impl) take(algorithm Algorithm, args ...ref.Val) (Result, error) {
+	request := Request{Algorithm: algorithm, Cost: 1}
+	if key, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return Result{}, err
+	} else if limit, err := utils.ConvertToNative[int64](args[1]); err != nil {
+		return Result{}, err
+	} else if period, err := utils.ConvertToNative[time.Duration](args[2]); err != nil {
+		return Result{}, err
+	} else {
+		request.Key = key
+		request.Limit = limit
+		request.Period = period
+	}
+	if len(args) > 3 {
+		cost, err := utils.ConvertToNative[int64](args[3])
+		if err != nil {
+			return Result{}, err
+		}
+		request.Cost = cost
+	}
+	return c.limiter.Take(context.Background(), request, time.Now())
+}
+
+func (c *impl) allow(algorithm Algorithm) func(...ref.Val) ref.Val {
+	return func(args ...ref.Val) ref.Val {
+		if result, err := c.take(algorithm, args...); err != nil {
+			return types.WrapErr(err)
+		} else {
+			return types.Bool(result.Allowed)
+		}
+	}
+}
+
+func (c *impl) check(algorithm Algorithm) func(...ref.Val) ref.Val {
+	return func(args ...ref.Val) ref.Val {
+		if result, err := c.take(algorithm, args...); err != nil {
+			return types.WrapErr(err)
+		} else {
+			return c.NativeToValue(&result)
+		}
+	}
+}
+
+func (c *impl) result_retry_after_header(result ref.Val) ref.Val {
+	if result, err := utils.ConvertToNative[*Result](result); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(result.RetryAfterHeader())
+	}
+}
+
+func (c *impl) result_headers(result ref.Val) ref.Val {
+	if result, err := utils.ConvertToNative[*Result](result); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(result.Headers())
+	}
+}
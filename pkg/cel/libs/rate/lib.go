@@ -0,0 +1,75 @@
+package rate
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct {
+	limiter Limiter
+}
+
+func Lib(limiter Limiter) cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{limiter: limiter})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.rate"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Result](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c *lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter and limiter
+	impl := impl{adapter, c.limiter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"rate.Allow": {
+			cel.Overload("rate_allow_string_int_duration", []*cel.Type{types.StringType, types.IntType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.allow(TokenBucket))),
+		},
+		"rate.Check": {
+			cel.Overload("rate_check_string_int_duration", []*cel.Type{types.StringType, types.IntType, types.DurationType}, ResultType, cel.FunctionBinding(impl.check(TokenBucket))),
+			cel.Overload("rate_check_string_int_duration_int", []*cel.Type{types.StringType, types.IntType, types.DurationType, types.IntType}, ResultType, cel.FunctionBinding(impl.check(TokenBucket))),
+		},
+		"rate.AllowWindow": {
+			cel.Overload("rate_allow_window_string_int_duration", []*cel.Type{types.StringType, types.IntType, types.DurationType}, types.BoolType, cel.FunctionBinding(impl.allow(SlidingWindow))),
+		},
+		"rate.CheckWindow": {
+			cel.Overload("rate_check_window_string_int_duration", []*cel.Type{types.StringType, types.IntType, types.DurationType}, ResultType, cel.FunctionBinding(impl.check(SlidingWindow))),
+			cel.Overload("rate_check_window_string_int_duration_int", []*cel.Type{types.StringType, types.IntType, types.DurationType, types.IntType}, ResultType, cel.FunctionBinding(impl.check(SlidingWindow))),
+		},
+		"retryAfterHeader": {
+			cel.MemberOverload("rate_result_retry_after_header", []*cel.Type{ResultType}, types.StringType, cel.UnaryBinding(impl.result_retry_after_header)),
+		},
+		"headers": {
+			cel.MemberOverload("rate_result_headers", []*cel.Type{ResultType}, types.NewMapType(types.StringType, types.StringType), cel.UnaryBinding(impl.result_headers)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,79 @@
+package rate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLib(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "allow",
+		source: `[1, 2, 3].map(i, rate.Allow("tenant:" + tenant, 2, duration("1m")))`,
+		want:   []bool{true, true, false},
+	}, {
+		name:   "allow window",
+		source: `[1, 2, 3].map(i, rate.AllowWindow("tenant:" + tenant, 2, duration("1h")))`,
+		want:   []bool{true, true, false},
+	}, {
+		name:   "check",
+		source: `rate.Check("tenant:" + tenant, 100, duration("1m"), 40).remaining`,
+		want:   int64(60),
+	}, {
+		name:   "check denied",
+		source: `[rate.Check("tenant:" + tenant, 1, duration("1m")), rate.Check("tenant:" + tenant, 1, duration("1m"))].map(r, r.allowed)`,
+		want:   []bool{true, false},
+	}, {
+		name:   "retry after",
+		source: `[rate.Check("tenant:" + tenant, 1, duration("1m")), rate.Check("tenant:" + tenant, 1, duration("1m"))][1].retryAfterHeader()`,
+		want:   "60",
+	}, {
+		name:   "headers",
+		source: `[rate.CheckWindow("tenant:" + tenant, 1, duration("1h")), rate.CheckWindow("tenant:" + tenant, 1, duration("1h"))][1].headers()`,
+		want:   map[string]string{"ratelimit-limit": "1", "ratelimit-remaining": "0"},
+	}, {
+		name:    "invalid limit",
+		source:  `rate.Allow("tenant:" + tenant, 0, duration("1m"))`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(NewMemoryLimiter(DefaultMaxKeys)), cel.Variable("tenant", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"tenant": "acme"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			switch want := tt.want.(type) {
+			case []bool:
+				got, err := out.ConvertToNative(reflect.TypeFor[[]bool]())
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			case map[string]string:
+				native, err := out.ConvertToNative(reflect.TypeFor[map[string]string]())
+				assert.NoError(t, err)
+				// reset and retry after depend on the current time within the window
+				got := native.(map[string]string)
+				assert.Subset(t, got, want)
+				assert.Contains(t, got, "retry-after")
+				assert.Contains(t, got, "ratelimit-reset")
+			default:
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package rate
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultMaxKeys bounds the number of counters kept by the default limiter
+const DefaultMaxKeys = 100000
+
+var defaultLimiter = sync.OnceValue(func() Limiter {
+	return NewMemoryLimiter(DefaultMaxKeys)
+})
+
+// Default returns the process wide limiter
+func Default() Limiter {
+	return defaultLimiter()
+}
+
+// MemoryLimiter keeps counters in memory, the least recently used counters are evicted when maxKeys is reached
+type MemoryLimiter struct {
+	maxKeys int
+	lock    sync.Mutex
+	lru     *list.List
+	entries map[counterKey]*list.Element
+}
+
+type counterKey struct {
+	key       string
+	algorithm Algorithm
+	limit     int64
+	period    time.Duration
+}
+
+type counter struct {
+	key counterKey
+	// token bucket state
+	tokens float64
+	last   time.Time
+	// sliding window state
+	start    time.Time
+	current  int64
+	previous int64
+}
+
+func NewMemoryLimiter(maxKeys int) *MemoryLimiter {
+	return &MemoryLimiter{
+		maxKeys: maxKeys,
+		lru:     list.New(),
+		entries: map[counterKey]*list.Element{},
+	}
+}
+
+func (l *MemoryLimiter) Take(_ context.Context, request Request, now time.Time) (Result, error) {
+	if request.Limit <= 0 {
+		return Result{}, errors.New("rate limit must be positive")
+	}
+	if request.Period <= 0 {
+		return Result{}, errors.New("rate limit period must be positive")
+	}
+	if request.Cost < 0 {
+		return Result{}, errors.New("rate limit cost must not be negative")
+	}
+	if request.Algorithm != TokenBucket && request.Algorithm != SlidingWindow {
+		return Result{}, fmt.Errorf("unsupported rate limit algorithm %q", request.Algorithm)
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	counter := l.counter(counterKey{key: request.Key, algorithm: request.Algorithm, limit: request.Limit, period: request.Period}, now)
+	if request.Algorithm == SlidingWindow {
+		return counter.slidingWindow(request, now), nil
+	}
+	return counter.tokenBucket(request, now), nil
+}
+
+// counter returns the counter for key, the lock must be held
+func (l *MemoryLimiter) counter(key counterKey, now time.Time) *counter {
+	if element, ok := l.entries[key]; ok {
+		l.lru.MoveToFront(element)
+		return element.Value.(*counter)
+	}
+	// evicting a counter resets it, the least recently used ones are the most likely to have been reset already
+	for l.maxKeys > 0 && len(l.entries) >= l.maxKeys {
+		oldest := l.lru.Back()
+		delete(l.entries, oldest.Value.(*counter).key)
+		l.lru.Remove(oldest)
+	}
+	c := &counter{key: key, tokens: float64(key.limit), last: now, start: now.Truncate(key.period)}
+	l.entries[key] = l.lru.PushFront(c)
+	return c
+}
+
+func (c *counter) tokenBucket(request Request, now time.Time) Result {
+	rate := float64(request.Limit) / request.Period.Seconds()
+	if elapsed := now.Sub(c.last); elapsed > 0 {
+		c.tokens = min(float64(request.Limit), c.tokens+elapsed.Seconds()*rate)
+		c.last = now
+	}
+	result := Result{Limit: request.Limit}
+	cost := float64(request.Cost)
+	if cost <= c.tokens {
+		c.tokens -= cost
+		result.Allowed = true
+	} else if request.Cost <= request.Limit {
+		result.RetryAfter = duration((cost - c.tokens) / rate)
+	} else {
+		// the request can never be allowed
+		result.RetryAfter = request.Period
+	}
+	result.Remaining = int64(math.Floor(c.tokens))
+	result.ResetAfter = duration((float64(request.Limit) - c.tokens) / rate)
+	return result
+}
+
+func (c *counter) slidingWindow(request Request, now time.Time) Result {
+	window := request.Period
+	if start := now.Truncate(window); start.After(c.start) {
+		if start.Sub(c.start) == window {
+			c.previous = c.current
+		} else {
+			c.previous = 0
+		}
+		c.current = 0
+		c.start = start
+	}
+	elapsed := now.Sub(c.start)
+	// the previous window count is weighted by how much of it overlaps the sliding window
+	weight := 1 - float64(elapsed)/float64(window)
+	estimate := float64(c.previous)*weight + float64(c.current)
+	result := Result{Limit: request.Limit}
+	if estimate+float64(request.Cost) <= float64(request.Limit) {
+		c.current += request.Cost
+		estimate += float64(request.Cost)
+		result.Allowed = true
+	} else if request.Cost > request.Limit {
+		// the request can never be allowed
+		result.RetryAfter = window
+	} else if c.current+request.Cost <= request.Limit {
+		// wait for the previous window weight to decrease enough
+		target := float64(request.Limit-c.current-request.Cost) / float64(c.previous)
+		result.RetryAfter = time.Duration((1-target)*float64(window)) - elapsed
+	} else {
+		// wait for the current window to become the previous one and its weight to decrease enough
+		target := float64(request.Limit-request.Cost) / float64(c.current)
+		result.RetryAfter = window - elapsed + time.Duration((1-target)*float64(window))
+	}
+	result.RetryAfter = max(result.RetryAfter, 0)
+	result.Remaining = max(int64(math.Floor(float64(request.Limit)-estimate)), 0)
+	switch {
+	case c.current > 0:
+		result.ResetAfter = 2*window - elapsed
+	case c.previous > 0:
+		result.ResetAfter = window - elapsed
+	}
+	return result
+}
+
+func duration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
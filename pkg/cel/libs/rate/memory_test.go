@@ -0,0 +1,94 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func take(t *testing.T, limiter Limiter, request Request, now time.Time) Result {
+	t.Helper()
+	result, err := limiter.Take(context.Background(), request, now)
+	require.NoError(t, err)
+	return result
+}
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	limiter := NewMemoryLimiter(10)
+	now := time.Unix(1000, 0)
+	request := Request{Key: "tenant:a", Algorithm: TokenBucket, Limit: 3, Period: 3 * time.Second, Cost: 1}
+	for i := range 3 {
+		result := take(t, limiter, request, now)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(2-i), result.Remaining)
+	}
+	result := take(t, limiter, request, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+	// one token is refilled every second
+	result = take(t, limiter, request, now.Add(time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	// keys are independent
+	result = take(t, limiter, Request{Key: "tenant:b", Algorithm: TokenBucket, Limit: 3, Period: 3 * time.Second, Cost: 1}, now)
+	assert.True(t, result.Allowed)
+	// requests costing more than the limit are never allowed
+	result = take(t, limiter, Request{Key: "tenant:c", Algorithm: TokenBucket, Limit: 3, Period: 3 * time.Second, Cost: 4}, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 3*time.Second, result.RetryAfter)
+}
+
+func TestMemoryLimiter_SlidingWindow(t *testing.T) {
+	limiter := NewMemoryLimiter(10)
+	start := time.Unix(1000, 0).Truncate(10 * time.Second)
+	request := Request{Key: "tenant:a", Algorithm: SlidingWindow, Limit: 10, Period: 10 * time.Second, Cost: 5}
+	assert.True(t, take(t, limiter, request, start).Allowed)
+	assert.True(t, take(t, limiter, request, start.Add(time.Second)).Allowed)
+	result := take(t, limiter, request, start.Add(2*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	// the current window becomes the previous one, its weight must drop to one half
+	assert.Equal(t, 13*time.Second, result.RetryAfter)
+	// halfway through the next window the previous count weights 5
+	result = take(t, limiter, request, start.Add(15*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.False(t, take(t, limiter, request, start.Add(16*time.Second)).Allowed)
+	// windows without requests reset the counter
+	result = take(t, limiter, request, start.Add(40*time.Second))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(5), result.Remaining)
+}
+
+func TestMemoryLimiter_Eviction(t *testing.T) {
+	limiter := NewMemoryLimiter(2)
+	now := time.Unix(1000, 0)
+	request := func(key string) Request {
+		return Request{Key: key, Algorithm: TokenBucket, Limit: 1, Period: time.Minute, Cost: 1}
+	}
+	assert.True(t, take(t, limiter, request("a"), now).Allowed)
+	assert.True(t, take(t, limiter, request("b"), now).Allowed)
+	assert.False(t, take(t, limiter, request("a"), now).Allowed)
+	// b is the least recently used counter
+	assert.True(t, take(t, limiter, request("c"), now).Allowed)
+	assert.Len(t, limiter.entries, 2)
+	assert.True(t, take(t, limiter, request("b"), now).Allowed)
+	assert.False(t, take(t, limiter, request("c"), now).Allowed)
+}
+
+func TestMemoryLimiter_Errors(t *testing.T) {
+	limiter := NewMemoryLimiter(10)
+	for _, request := range []Request{
+		{Algorithm: TokenBucket, Limit: 0, Period: time.Second, Cost: 1},
+		{Algorithm: TokenBucket, Limit: 1, Period: 0, Cost: 1},
+		{Algorithm: TokenBucket, Limit: 1, Period: time.Second, Cost: -1},
+		{Algorithm: "fixed_window", Limit: 1, Period: time.Second, Cost: 1},
+	} {
+		_, err := limiter.Take(context.Background(), request, time.Now())
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,68 @@
+package rate
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+var ResultType = types.NewObjectType("rate.Result")
+
+// Algorithm identifies how requests are counted
+type Algorithm string
+
+const (
+	// TokenBucket lets bursts up to the limit through, tokens are refilled continuously over the period
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow counts requests over the last window, the count is approximated from the current and previous windows
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Request describes the counter to update, counters are identified by key, algorithm, limit and period
+type Request struct {
+	Key       string
+	Algorithm Algorithm
+	Limit     int64
+	Period    time.Duration
+	Cost      int64
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed    bool          `cel:"allowed"`
+	Limit      int64         `cel:"limit"`
+	Remaining  int64         `cel:"remaining"`
+	RetryAfter time.Duration `cel:"retryAfter"`
+	ResetAfter time.Duration `cel:"resetAfter"`
+}
+
+// Limiter keeps rate limit counters, the in memory limiter keeps them in the Authz Server process
+// while other implementations can share them between replicas
+type Limiter interface {
+	Take(ctx context.Context, request Request, now time.Time) (Result, error)
+}
+
+// RetryAfterHeader returns the Retry-After header value, in seconds rounded up
+func (r *Result) RetryAfterHeader() string {
+	return seconds(r.RetryAfter)
+}
+
+// Headers returns the RateLimit-* headers, and Retry-After when the request is not allowed
+func (r *Result) Headers() map[string]string {
+	headers := map[string]string{
+		"ratelimit-limit":     strconv.FormatInt(r.Limit, 10),
+		"ratelimit-remaining": strconv.FormatInt(r.Remaining, 10),
+		"ratelimit-reset":     seconds(r.ResetAfter),
+	}
+	if !r.Allowed {
+		headers["retry-after"] = r.RetryAfterHeader()
+	}
+	return headers
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(max(d, 0).Seconds())), 10)
+}
@@ -0,0 +1,64 @@
+package spiffe
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse_string(id ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[string](id); err != nil {
+		return types.WrapErr(err)
+	} else if parsed, err := ParseID(id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(parsed)
+	}
+}
+
+func (c *impl) is_valid_string(id ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[string](id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		_, err := ParseID(id)
+		return types.Bool(err == nil)
+	}
+}
+
+func (c *impl) id_trust_domain(id ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[ID](id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(id.TrustDomain)
+	}
+}
+
+func (c *impl) id_path(id ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[ID](id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.String(id.Path)
+	}
+}
+
+func (c *impl) id_segments(id ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[ID](id); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(id.Segments())
+	}
+}
+
+func (c *impl) id_member_of_string(id ref.Val, trustDomain ref.Val) ref.Val {
+	if id, err := utils.ConvertToNative[ID](id); err != nil {
+		return types.WrapErr(err)
+	} else if trustDomain, err := utils.ConvertToNative[string](trustDomain); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(id.MemberOf(trustDomain))
+	}
+}
@@ -0,0 +1,68 @@
+package spiffe
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.spiffe"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(reflect.TypeFor[ID]()),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"spiffe.Parse": {
+			cel.Overload("spiffe_parse_string", []*cel.Type{types.StringType}, IDType, cel.UnaryBinding(impl.parse_string)),
+		},
+		"spiffe.IsValid": {
+			cel.Overload("spiffe_is_valid_string", []*cel.Type{types.StringType}, types.BoolType, cel.UnaryBinding(impl.is_valid_string)),
+		},
+		"trustDomain": {
+			cel.MemberOverload("spiffe_id_trust_domain", []*cel.Type{IDType}, types.StringType, cel.UnaryBinding(impl.id_trust_domain)),
+		},
+		"path": {
+			cel.MemberOverload("spiffe_id_path", []*cel.Type{IDType}, types.StringType, cel.UnaryBinding(impl.id_path)),
+		},
+		"segments": {
+			cel.MemberOverload("spiffe_id_segments", []*cel.Type{IDType}, types.NewListType(types.StringType), cel.UnaryBinding(impl.id_segments)),
+		},
+		"memberOf": {
+			cel.MemberOverload("spiffe_id_member_of_string", []*cel.Type{IDType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.id_member_of_string)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,78 @@
+package spiffe
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLib(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "trust domain",
+		source: `spiffe.Parse("spiffe://cluster.local/ns/default/sa/bookinfo").trustDomain()`,
+		want:   "cluster.local",
+	}, {
+		name:   "path",
+		source: `spiffe.Parse("spiffe://cluster.local/ns/default/sa/bookinfo").path()`,
+		want:   "/ns/default/sa/bookinfo",
+	}, {
+		name:   "segments",
+		source: `spiffe.Parse("spiffe://cluster.local/ns/default/sa/bookinfo").segments()[3]`,
+		want:   "bookinfo",
+	}, {
+		name:   "no path",
+		source: `spiffe.Parse("spiffe://cluster.local").segments().size()`,
+		want:   int64(0),
+	}, {
+		name:   "member of",
+		source: `spiffe.Parse("spiffe://cluster.local/ns/default/sa/bookinfo").memberOf("spiffe://cluster.local")`,
+		want:   true,
+	}, {
+		name:   "not member of",
+		source: `spiffe.Parse("spiffe://cluster.local/ns/default/sa/bookinfo").memberOf("example.org")`,
+		want:   false,
+	}, {
+		name:   "is valid",
+		source: `spiffe.IsValid("spiffe://cluster.local/ns/default")`,
+		want:   true,
+	}, {
+		name:   "is not valid",
+		source: `spiffe.IsValid("spiffe://cluster.local/ns//default")`,
+		want:   false,
+	}, {
+		name:    "invalid scheme",
+		source:  `spiffe.Parse("https://cluster.local/ns/default")`,
+		wantErr: true,
+	}, {
+		name:    "invalid trust domain",
+		source:  `spiffe.Parse("spiffe://Cluster.Local/ns/default")`,
+		wantErr: true,
+	}, {
+		name:    "dot segment",
+		source:  `spiffe.Parse("spiffe://cluster.local/ns/../default")`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib())
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package spiffe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+)
+
+var IDType = types.NewObjectType("spiffe.ID")
+
+const scheme = "spiffe://"
+
+// ID is a parsed SPIFFE ID as described in https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE-ID.md
+type ID struct {
+	TrustDomain string
+	Path        string
+}
+
+func (id ID) String() string {
+	return scheme + id.TrustDomain + id.Path
+}
+
+func (id ID) Segments() []string {
+	if id.Path == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimPrefix(id.Path, "/"), "/")
+}
+
+func (id ID) MemberOf(trustDomain string) bool {
+	return id.TrustDomain == strings.TrimPrefix(trustDomain, scheme)
+}
+
+func ParseID(in string) (ID, error) {
+	if !strings.HasPrefix(in, scheme) {
+		return ID{}, fmt.Errorf("invalid spiffe id %q: scheme is missing or invalid", in)
+	}
+	rest := in[len(scheme):]
+	trustDomain, path := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		trustDomain, path = rest[:i], rest[i:]
+	}
+	if trustDomain == "" {
+		return ID{}, fmt.Errorf("invalid spiffe id %q: trust domain is missing", in)
+	}
+	for _, c := range trustDomain {
+		if !isTrustDomainChar(c) {
+			return ID{}, fmt.Errorf("invalid spiffe id %q: trust domain contains invalid character %q", in, c)
+		}
+	}
+	if path != "" {
+		for _, segment := range strings.Split(path[1:], "/") {
+			if err := validateSegment(segment); err != nil {
+				return ID{}, fmt.Errorf("invalid spiffe id %q: %w", in, err)
+			}
+		}
+	}
+	return ID{TrustDomain: trustDomain, Path: path}, nil
+}
+
+func validateSegment(segment string) error {
+	switch segment {
+	case "":
+		return errors.New("path cannot contain empty segments")
+	case ".", "..":
+		return errors.New("path cannot contain dot segments")
+	}
+	for _, c := range segment {
+		if !isPathChar(c) {
+			return fmt.Errorf("path contains invalid character %q", c)
+		}
+	}
+	return nil
+}
+
+func isTrustDomainChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_'
+}
+
+func isPathChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_'
+}
@@ -0,0 +1,137 @@
+package timecel
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kyverno/kyverno-authz/pkg/utils/filecache"
+	"sigs.k8s.io/yaml"
+)
+
+type entrySpec struct {
+	Name   string `json:"name"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Date   string `json:"date"`
+	Window string `json:"window"`
+}
+
+type calendarSpec struct {
+	TimeZone string      `json:"timeZone"`
+	Entries  []entrySpec `json:"entries"`
+}
+
+// localLayouts are accepted for times without offset, they are interpreted in the calendar time zone
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// ParseCalendar parses a YAML or JSON calendar, each entry has either `start` and `end`, a `date` spanning a whole day
+// or a recurring `window`, times without offset are in the calendar `timeZone` (UTC by default)
+func ParseCalendar(data []byte) (*Calendar, error) {
+	var spec calendarSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec.TimeZone == "" {
+		spec.TimeZone = "UTC"
+	}
+	location, err := loadLocation(spec.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	calendar := Calendar{TimeZone: spec.TimeZone}
+	for i, e := range spec.Entries {
+		entry, err := parseEntry(e, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar entry %d: %w", i, err)
+		}
+		calendar.Entries = append(calendar.Entries, entry)
+	}
+	return &calendar, nil
+}
+
+func parseEntry(spec entrySpec, location *time.Location) (Entry, error) {
+	entry := Entry{Name: spec.Name, Window: spec.Window}
+	switch {
+	case spec.Window != "":
+		if spec.Start != "" || spec.End != "" || spec.Date != "" {
+			return entry, errors.New("window can't be combined with start, end or date")
+		}
+		window, err := ParseWindow(spec.Window)
+		if err != nil {
+			return entry, err
+		}
+		entry.parsed = window
+	case spec.Date != "":
+		if spec.Start != "" || spec.End != "" {
+			return entry, errors.New("date can't be combined with start or end")
+		}
+		date, err := time.ParseInLocation(time.DateOnly, spec.Date, location)
+		if err != nil {
+			return entry, err
+		}
+		entry.Start, entry.End = date, date.AddDate(0, 0, 1)
+	default:
+		start, err := parseTime(spec.Start, location)
+		if err != nil {
+			return entry, fmt.Errorf("invalid start: %w", err)
+		}
+		end, err := parseTime(spec.End, location)
+		if err != nil {
+			return entry, fmt.Errorf("invalid end: %w", err)
+		}
+		if !end.After(start) {
+			return entry, errors.New("end must be after start")
+		}
+		entry.Start, entry.End = start, end
+	}
+	return entry, nil
+}
+
+func parseTime(value string, location *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("missing time")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format %q", value)
+}
+
+// Active returns the entries active at t, periods include their start and exclude their end
+func (c *Calendar) Active(t time.Time) []Entry {
+	var active []Entry
+	var local time.Time
+	for _, entry := range c.Entries {
+		if entry.parsed != nil {
+			if local.IsZero() {
+				// the location was validated when the calendar was parsed
+				location, _ := loadLocation(c.TimeZone)
+				local = t.In(location)
+			}
+			if entry.parsed.Contains(local) {
+				active = append(active, entry)
+			}
+		} else if !t.Before(entry.Start) && t.Before(entry.End) {
+			active = append(active, entry)
+		}
+	}
+	return active
+}
+
+// Contains returns true if an entry is active at t
+func (c *Calendar) Contains(t time.Time) bool {
+	return len(c.Active(t)) > 0
+}
+
+var calendars = filecache.New(ParseCalendar, 0)
+
+// LoadCalendar parses a calendar file, files are cached and reloaded when they change
+func LoadCalendar(path string) (*Calendar, error) {
+	return calendars.Load(path)
+}
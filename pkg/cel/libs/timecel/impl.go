@@ -0,0 +1,107 @@
+package timecel
+
+import (
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) now(...ref.Val) ref.Val {
+	return types.Timestamp{Time: time.Now()}
+}
+
+func (c *impl) in_window_string_string(window ref.Val, zone ref.Val) ref.Val {
+	return c.in_window_string_string_timestamp(window, zone, c.now())
+}
+
+func (c *impl) in_window_string_string_timestamp(args ...ref.Val) ref.Val {
+	if window, err := utils.ConvertToNative[string](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if zone, err := utils.ConvertToNative[string](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if at, err := utils.ConvertToNative[time.Time](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if in, err := InWindow(window, zone, at); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(in)
+	}
+}
+
+func (c *impl) start_of_day_timestamp_string(at ref.Val, zone ref.Val) ref.Val {
+	if at, err := utils.ConvertToNative[time.Time](at); err != nil {
+		return types.WrapErr(err)
+	} else if zone, err := utils.ConvertToNative[string](zone); err != nil {
+		return types.WrapErr(err)
+	} else if location, err := loadLocation(zone); err != nil {
+		return types.WrapErr(err)
+	} else {
+		local := at.In(location)
+		return types.Timestamp{Time: time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)}
+	}
+}
+
+func (c *impl) add_date_timestamp_int_int_int_string(args ...ref.Val) ref.Val {
+	if at, err := utils.ConvertToNative[time.Time](args[0]); err != nil {
+		return types.WrapErr(err)
+	} else if years, err := utils.ConvertToNative[int](args[1]); err != nil {
+		return types.WrapErr(err)
+	} else if months, err := utils.ConvertToNative[int](args[2]); err != nil {
+		return types.WrapErr(err)
+	} else if days, err := utils.ConvertToNative[int](args[3]); err != nil {
+		return types.WrapErr(err)
+	} else if zone, err := utils.ConvertToNative[string](args[4]); err != nil {
+		return types.WrapErr(err)
+	} else if location, err := loadLocation(zone); err != nil {
+		return types.WrapErr(err)
+	} else {
+		// dates are added to the wall clock, a day is not always 24 hours when daylight saving time changes
+		return types.Timestamp{Time: at.In(location).AddDate(years, months, days)}
+	}
+}
+
+func (c *impl) parse_calendar_string(data ref.Val) ref.Val {
+	if data, err := utils.ConvertToNative[string](data); err != nil {
+		return types.WrapErr(err)
+	} else if calendar, err := ParseCalendar([]byte(data)); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(calendar)
+	}
+}
+
+func (c *impl) load_calendar_string(path ref.Val) ref.Val {
+	if path, err := utils.ConvertToNative[string](path); err != nil {
+		return types.WrapErr(err)
+	} else if calendar, err := LoadCalendar(path); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(calendar)
+	}
+}
+
+func (c *impl) calendar_active_timestamp(calendar ref.Val, at ref.Val) ref.Val {
+	if calendar, err := utils.ConvertToNative[*Calendar](calendar); err != nil {
+		return types.WrapErr(err)
+	} else if at, err := utils.ConvertToNative[time.Time](at); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(calendar.Active(at))
+	}
+}
+
+func (c *impl) calendar_is_active_timestamp(calendar ref.Val, at ref.Val) ref.Val {
+	if calendar, err := utils.ConvertToNative[*Calendar](calendar); err != nil {
+		return types.WrapErr(err)
+	} else if at, err := utils.ConvertToNative[time.Time](at); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(calendar.Contains(at))
+	}
+}
@@ -0,0 +1,79 @@
+package timecel
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.time"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Calendar](),
+			reflect.TypeFor[Entry](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"time.Now": {
+			cel.Overload("time_now", []*cel.Type{}, types.TimestampType, cel.FunctionBinding(impl.now)),
+		},
+		"time.InWindow": {
+			cel.Overload("time_in_window_string_string", []*cel.Type{types.StringType, types.StringType}, types.BoolType, cel.BinaryBinding(impl.in_window_string_string)),
+			cel.Overload("time_in_window_string_string_timestamp", []*cel.Type{types.StringType, types.StringType, types.TimestampType}, types.BoolType, cel.FunctionBinding(impl.in_window_string_string_timestamp)),
+		},
+		"time.StartOfDay": {
+			cel.Overload("time_start_of_day_timestamp_string", []*cel.Type{types.TimestampType, types.StringType}, types.TimestampType, cel.BinaryBinding(impl.start_of_day_timestamp_string)),
+		},
+		"time.AddDate": {
+			cel.Overload("time_add_date_timestamp_int_int_int_string", []*cel.Type{types.TimestampType, types.IntType, types.IntType, types.IntType, types.StringType}, types.TimestampType, cel.FunctionBinding(impl.add_date_timestamp_int_int_int_string)),
+		},
+		"time.ParseCalendar": {
+			cel.Overload("time_parse_calendar_string", []*cel.Type{types.StringType}, CalendarType, cel.UnaryBinding(impl.parse_calendar_string)),
+		},
+		"time.LoadCalendar": {
+			cel.Overload("time_load_calendar_string", []*cel.Type{types.StringType}, CalendarType, cel.UnaryBinding(impl.load_calendar_string)),
+		},
+		"active": {
+			cel.MemberOverload("time_calendar_active_timestamp", []*cel.Type{CalendarType, types.TimestampType}, types.NewListType(EntryType), cel.BinaryBinding(impl.calendar_active_timestamp)),
+		},
+		"isActive": {
+			cel.MemberOverload("time_calendar_is_active_timestamp", []*cel.Type{CalendarType, types.TimestampType}, types.BoolType, cel.BinaryBinding(impl.calendar_is_active_timestamp)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
@@ -0,0 +1,151 @@
+package timecel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const calendar = `
+timeZone: Europe/Paris
+entries:
+- name: release freeze
+  start: 2026-12-20
+  end: 2027-01-04
+- name: christmas
+  date: 2026-12-25
+- name: weekly maintenance
+  window: Sun 02:00-04:00
+- name: migration
+  start: 2026-11-03T22:00:00Z
+  end: 2026-11-04T02:00:00Z
+`
+
+func TestLib(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(calendar), 0o600))
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "business hours",
+		source: `time.InWindow("Mon-Fri 09:00-18:00", "Europe/Paris", timestamp("2026-10-19T07:30:00Z"))`,
+		want:   true,
+	}, {
+		name:   "before business hours",
+		source: `time.InWindow("Mon-Fri 09:00-18:00", "Europe/Paris", timestamp("2026-10-19T06:30:00Z"))`,
+		want:   false,
+	}, {
+		name:   "other time zone",
+		source: `time.InWindow("Mon-Fri 09:00-18:00", "America/New_York", timestamp("2026-10-19T07:30:00Z"))`,
+		want:   false,
+	}, {
+		name:   "weekend",
+		source: `time.InWindow("Mon-Fri 09:00-18:00", "Europe/Paris", timestamp("2026-10-18T10:00:00Z"))`,
+		want:   false,
+	}, {
+		name:   "several rules",
+		source: `time.InWindow("Mon-Fri 09:00-12:00,14:00-18:00; Sat,Sun", "UTC", timestamp("2026-10-18T23:59:59Z"))`,
+		want:   true,
+	}, {
+		name:   "lunch break",
+		source: `time.InWindow("Mon-Fri 09:00-12:00,14:00-18:00", "UTC", timestamp("2026-10-19T12:30:00Z"))`,
+		want:   false,
+	}, {
+		name:   "crossing midnight",
+		source: `[timestamp("2026-10-23T23:00:00Z"), timestamp("2026-10-24T05:00:00Z"), timestamp("2026-10-19T05:00:00Z")].map(t, time.InWindow("Fri 22:00-06:00", "UTC", t))`,
+		want:   []bool{true, true, false},
+	}, {
+		name:   "wrapping days",
+		source: `time.InWindow("Fri-Mon", "UTC", timestamp("2026-10-19T12:00:00Z"))`,
+		want:   true,
+	}, {
+		name:   "now",
+		source: `time.InWindow("00:00-24:00", "UTC")`,
+		want:   true,
+	}, {
+		name:    "invalid window",
+		source:  `time.InWindow("Mon-Fri 9h-18h", "UTC")`,
+		wantErr: true,
+	}, {
+		name:    "invalid day",
+		source:  `time.InWindow("Monday 09:00-18:00", "UTC")`,
+		wantErr: true,
+	}, {
+		name:    "invalid time zone",
+		source:  `time.InWindow("Mon-Fri 09:00-18:00", "Europe/Atlantis")`,
+		wantErr: true,
+	}, {
+		name:   "start of day",
+		source: `time.StartOfDay(timestamp("2026-10-19T23:30:00Z"), "Europe/Paris")`,
+		want:   time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC).Add(-2 * time.Hour),
+	}, {
+		name:   "add date across daylight saving time",
+		source: `time.AddDate(timestamp("2026-10-24T10:00:00Z"), 0, 0, 1, "Europe/Paris") - timestamp("2026-10-24T10:00:00Z")`,
+		want:   25 * time.Hour,
+	}, {
+		name:   "calendar",
+		source: `time.LoadCalendar(path).active(timestamp("2026-12-25T12:00:00Z")).map(e, e.name)`,
+		want:   []string{"release freeze", "christmas"},
+	}, {
+		name:   "calendar window",
+		source: `time.ParseCalendar(calendar).active(timestamp("2026-10-25T01:30:00Z")).map(e, e.name)`,
+		want:   []string{"weekly maintenance"},
+	}, {
+		name:   "calendar offset",
+		source: `time.ParseCalendar(calendar).isActive(timestamp("2026-11-04T01:00:00Z"))`,
+		want:   true,
+	}, {
+		name:   "calendar end excluded",
+		source: `time.ParseCalendar(calendar).isActive(timestamp("2027-01-03T23:00:00Z"))`,
+		want:   false,
+	}, {
+		name:   "calendar fields",
+		source: `time.ParseCalendar(calendar).entries[0].end`,
+		want:   time.Date(2027, 1, 3, 23, 0, 0, 0, time.UTC),
+	}, {
+		name:    "invalid calendar",
+		source:  `time.ParseCalendar("entries: [{name: x, date: 2026-12-25, window: Sun}]")`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(Lib(), cel.Variable("path", cel.StringType), cel.Variable("calendar", cel.StringType))
+			require.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			require.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			require.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{"path": path, "calendar": calendar})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			switch want := tt.want.(type) {
+			case []bool:
+				got, err := out.ConvertToNative(reflect.TypeFor[[]bool]())
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			case []string:
+				got, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			case time.Time:
+				got, err := out.ConvertToNative(reflect.TypeFor[time.Time]())
+				assert.NoError(t, err)
+				assert.True(t, want.Equal(got.(time.Time)), "%s != %s", want, got)
+			default:
+				assert.Equal(t, tt.want, out.Value())
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package timecel
+
+import (
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+var (
+	CalendarType = types.NewObjectType("timecel.Calendar")
+	EntryType    = types.NewObjectType("timecel.Entry")
+)
+
+// Calendar is a list of dated periods and recurring windows, for example maintenance windows or release freezes
+type Calendar struct {
+	TimeZone string  `cel:"timeZone"`
+	Entries  []Entry `cel:"entries"`
+}
+
+// Entry is either a period between Start and End, or a recurring Window
+type Entry struct {
+	Name   string    `cel:"name"`
+	Start  time.Time `cel:"start"`
+	End    time.Time `cel:"end"`
+	Window string    `cel:"window"`
+	parsed *Window
+}
@@ -0,0 +1,210 @@
+package timecel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// Window is a set of weekly recurring time ranges, for example `Mon-Fri 09:00-12:00,14:00-18:00; Sat 10:00-12:00`
+type Window struct {
+	rules []rule
+}
+
+type rule struct {
+	days [7]bool
+	// start and end are seconds since midnight, ranges ending before they start cross midnight
+	start int
+	end   int
+}
+
+var days = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWindow parses rules separated by `;`, each rule has optional days (`Mon`, `Mon-Fri`, `Sat,Sun`)
+// followed by optional time ranges (`09:00-18:00`, `22:00-06:00`), a rule without time ranges spans whole days
+func ParseWindow(spec string) (*Window, error) {
+	var window Window
+	for _, part := range strings.Split(spec, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		var dayList, timeList string
+		switch {
+		case len(fields) == 1 && isDigit(fields[0][0]):
+			timeList = fields[0]
+		case len(fields) == 1:
+			dayList = fields[0]
+		case len(fields) == 2:
+			dayList, timeList = fields[0], fields[1]
+		default:
+			return nil, fmt.Errorf("invalid window rule %q", strings.TrimSpace(part))
+		}
+		ruleDays, err := parseDays(dayList)
+		if err != nil {
+			return nil, err
+		}
+		if timeList == "" {
+			window.rules = append(window.rules, rule{days: ruleDays, start: 0, end: secondsPerDay})
+			continue
+		}
+		for _, r := range strings.Split(timeList, ",") {
+			from, to, ok := strings.Cut(r, "-")
+			if !ok {
+				return nil, fmt.Errorf("invalid time range %q", r)
+			}
+			start, err := parseClock(from)
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseClock(to)
+			if err != nil {
+				return nil, err
+			}
+			if start == end || start == secondsPerDay {
+				return nil, fmt.Errorf("invalid time range %q", r)
+			}
+			window.rules = append(window.rules, rule{days: ruleDays, start: start, end: end})
+		}
+	}
+	if len(window.rules) == 0 {
+		return nil, fmt.Errorf("empty window %q", spec)
+	}
+	return &window, nil
+}
+
+// Contains returns true if the wall clock time of t falls in the window, t must be in the window time zone
+func (w *Window) Contains(t time.Time) bool {
+	clock := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	day := t.Weekday()
+	previous := (day + 6) % 7
+	for _, rule := range w.rules {
+		if rule.start < rule.end {
+			if rule.days[day] && clock >= rule.start && clock < rule.end {
+				return true
+			}
+		} else if (rule.days[day] && clock >= rule.start) || (rule.days[previous] && clock < rule.end) {
+			// the range crosses midnight, it belongs to the day it starts
+			return true
+		}
+	}
+	return false
+}
+
+func parseDays(spec string) ([7]bool, error) {
+	var out [7]bool
+	if spec == "" {
+		return [7]bool{true, true, true, true, true, true, true}, nil
+	}
+	for _, item := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(item, "-")
+		start, ok := days[strings.ToLower(from)]
+		if !ok {
+			return out, fmt.Errorf("invalid day %q", from)
+		}
+		end := start
+		if isRange {
+			if end, ok = days[strings.ToLower(to)]; !ok {
+				return out, fmt.Errorf("invalid day %q", to)
+			}
+		}
+		// ranges may wrap around the week, for example Fri-Mon
+		for day := start; ; day = (day + 1) % 7 {
+			out[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func parseClock(spec string) (int, error) {
+	hours, minutes, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 24 || len(minutes) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", spec)
+	}
+	return h*3600 + m*60, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// maxCachedWindows bounds the number of parsed windows kept in memory, windows are usually literals in policies
+const maxCachedWindows = 1000
+
+var windows = struct {
+	lock    sync.Mutex
+	entries map[string]*Window
+}{
+	entries: map[string]*Window{},
+}
+
+// window returns the parsed window, parsed windows are cached
+func window(spec string) (*Window, error) {
+	windows.lock.Lock()
+	cached, ok := windows.entries[spec]
+	windows.lock.Unlock()
+	if ok {
+		return cached, nil
+	}
+	parsed, err := ParseWindow(spec)
+	if err != nil {
+		return nil, err
+	}
+	windows.lock.Lock()
+	if len(windows.entries) < maxCachedWindows {
+		windows.entries[spec] = parsed
+	}
+	windows.lock.Unlock()
+	return parsed, nil
+}
+
+// InWindow returns true if t falls in the window, in the given time zone
+func InWindow(spec string, zone string, t time.Time) (bool, error) {
+	w, err := window(spec)
+	if err != nil {
+		return false, err
+	}
+	location, err := loadLocation(zone)
+	if err != nil {
+		return false, err
+	}
+	return w.Contains(t.In(location)), nil
+}
+
+var locations sync.Map
+
+// loadLocation returns the named time zone, time zones are read from the system database once
+func loadLocation(zone string) (*time.Location, error) {
+	if location, ok := locations.Load(zone); ok {
+		return location.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(zone, location)
+	return location, nil
+}
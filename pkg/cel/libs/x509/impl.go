@@ -0,0 +1,53 @@
+package x509
+
+import (
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/kyverno/kyverno-authz/pkg/cel/utils"
+)
+
+type impl struct {
+	types.Adapter
+}
+
+func (c *impl) parse_string(in ref.Val) ref.Val {
+	if in, err := utils.ConvertToNative[string](in); err != nil {
+		return types.WrapErr(err)
+	} else if certs, err := ParseCertificates(in); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(certs[0])
+	}
+}
+
+func (c *impl) parse_chain_string(in ref.Val) ref.Val {
+	if in, err := utils.ConvertToNative[string](in); err != nil {
+		return types.WrapErr(err)
+	} else if certs, err := ParseCertificates(in); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(certs)
+	}
+}
+
+func (c *impl) xfcc_parse_string(in ref.Val) ref.Val {
+	if in, err := utils.ConvertToNative[string](in); err != nil {
+		return types.WrapErr(err)
+	} else if elements, err := ParseXFCC(in); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return c.NativeToValue(elements)
+	}
+}
+
+func (c *impl) certificate_valid_at_timestamp(cert ref.Val, at ref.Val) ref.Val {
+	if cert, err := utils.ConvertToNative[Certificate](cert); err != nil {
+		return types.WrapErr(err)
+	} else if at, err := utils.ConvertToNative[time.Time](at); err != nil {
+		return types.WrapErr(err)
+	} else {
+		return types.Bool(!at.Before(cert.NotBefore) && !at.After(cert.NotAfter))
+	}
+}
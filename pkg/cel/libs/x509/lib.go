@@ -0,0 +1,69 @@
+package x509
+
+import (
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/spiffe"
+)
+
+type lib struct{}
+
+func Lib() cel.EnvOption {
+	// create the cel lib env option
+	return cel.Lib(&lib{})
+}
+
+func (*lib) LibraryName() string {
+	return "kyverno.x509"
+}
+
+func (c *lib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		// register spiffe lib
+		spiffe.Lib(),
+		// register native types
+		ext.NativeTypes(
+			reflect.TypeFor[Certificate](),
+			reflect.TypeFor[ClientCert](),
+			ext.ParseStructTags(true),
+		),
+		// extend environment with function overloads
+		c.extendEnv,
+	}
+}
+
+func (*lib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (*lib) extendEnv(env *cel.Env) (*cel.Env, error) {
+	// get env type adapter
+	adapter := env.CELTypeAdapter()
+	// create implementation with adapter
+	impl := impl{adapter}
+	// build our function overloads
+	libraryDecls := map[string][]cel.FunctionOpt{
+		"x509.Parse": {
+			cel.Overload("x509_parse_string", []*cel.Type{types.StringType}, CertificateType, cel.UnaryBinding(impl.parse_string)),
+		},
+		"x509.ParseChain": {
+			cel.Overload("x509_parse_chain_string", []*cel.Type{types.StringType}, types.NewListType(CertificateType), cel.UnaryBinding(impl.parse_chain_string)),
+		},
+		"xfcc.Parse": {
+			cel.Overload("xfcc_parse_string", []*cel.Type{types.StringType}, types.NewListType(ClientCertType), cel.UnaryBinding(impl.xfcc_parse_string)),
+		},
+		"validAt": {
+			cel.MemberOverload("x509_certificate_valid_at_timestamp", []*cel.Type{CertificateType, types.TimestampType}, types.BoolType, cel.BinaryBinding(impl.certificate_valid_at_timestamp)),
+		},
+	}
+	// create env options corresponding to our function overloads
+	options := []cel.EnvOption{}
+	for name, overloads := range libraryDecls {
+		options = append(options, cel.Function(name, overloads...))
+	}
+	// extend environment with our function overloads
+	return env.Extend(options...)
+}
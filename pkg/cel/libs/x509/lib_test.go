@@ -0,0 +1,172 @@
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCertificates(t *testing.T) (string, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root-ca", Organization: []string{"cluster.local"}},
+		NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDer)
+	assert.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	spiffeID, err := url.Parse("spiffe://cluster.local/ns/default/sa/bookinfo")
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "bookinfo"},
+		NotBefore:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		DNSNames:     []string{"bookinfo.default.svc"},
+		URIs:         []*url.URL{spiffeID},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+	leaf := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	root := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}))
+	return leaf, root
+}
+
+func TestLib(t *testing.T) {
+	leaf, root := newCertificates(t)
+	xfcc := `By=spiffe://cluster.local/ns/default/sa/productpage;Hash=abcd;Cert="` + url.PathEscape(leaf) + `";Chain="` + url.PathEscape(leaf+root) + `";Subject="CN=bookinfo";URI=spiffe://cluster.local/ns/default/sa/bookinfo;DNS=bookinfo.default.svc`
+	tests := []struct {
+		name    string
+		source  string
+		want    any
+		wantErr bool
+	}{{
+		name:   "parse pem",
+		source: `x509.Parse(leaf).subject.commonName`,
+		want:   "bookinfo",
+	}, {
+		name:   "parse url encoded pem",
+		source: `x509.Parse(escaped).subject.commonName`,
+		want:   "bookinfo",
+	}, {
+		name:   "parse partially url encoded pem",
+		source: `x509.Parse(partial).subject.commonName`,
+		want:   "bookinfo",
+	}, {
+		name:   "issuer",
+		source: `x509.Parse(leaf).issuer.organization[0]`,
+		want:   "cluster.local",
+	}, {
+		name:   "sans",
+		source: `x509.Parse(leaf).dnsNames == ["bookinfo.default.svc"] && x509.Parse(leaf).uris.size() == 1`,
+		want:   true,
+	}, {
+		name:   "serial number",
+		source: `x509.Parse(leaf).serialNumber`,
+		want:   "42",
+	}, {
+		name:   "expiry",
+		source: `x509.Parse(leaf).notAfter == timestamp("2026-01-01T00:00:00Z")`,
+		want:   true,
+	}, {
+		name:   "valid at",
+		source: `x509.Parse(leaf).validAt(timestamp("2025-06-01T00:00:00Z")) && !x509.Parse(leaf).validAt(timestamp("2027-06-01T00:00:00Z"))`,
+		want:   true,
+	}, {
+		name:   "chain",
+		source: `x509.ParseChain(leaf + root).map(c, c.isCA)`,
+		want:   []bool{false, true},
+	}, {
+		name:   "spiffe id",
+		source: `x509.Parse(escaped).spiffeId.trustDomain() + x509.Parse(escaped).spiffeId.path()`,
+		want:   "cluster.local/ns/default/sa/bookinfo",
+	}, {
+		name:   "spiffe id member of",
+		source: `x509.Parse(leaf).spiffeId.memberOf("cluster.local")`,
+		want:   true,
+	}, {
+		name:   "no spiffe id",
+		source: `has(x509.Parse(root).spiffeId)`,
+		want:   false,
+	}, {
+		name:   "xfcc",
+		source: `xfcc.Parse(xfcc)[0].by[0]`,
+		want:   "spiffe://cluster.local/ns/default/sa/productpage",
+	}, {
+		name:   "xfcc cert",
+		source: `has(xfcc.Parse(xfcc)[0].cert) && xfcc.Parse(xfcc)[0].cert.spiffeId == spiffe.Parse(xfcc.Parse(xfcc)[0].uris[0])`,
+		want:   true,
+	}, {
+		name:   "xfcc chain",
+		source: `xfcc.Parse(xfcc)[0].chain[1].subject.commonName`,
+		want:   "root-ca",
+	}, {
+		name:   "xfcc without cert",
+		source: `xfcc.Parse("By=spiffe://a/b;URI=spiffe://a/c,By=spiffe://a/d;Subject=\"CN=foo,OU=bar\"").map(e, has(e.cert) ? "cert" : e.subject)`,
+		want:   []string{"", "CN=foo,OU=bar"},
+	}, {
+		name:    "invalid pem",
+		source:  `x509.Parse("foo")`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := cel.NewEnv(
+				Lib(),
+				cel.Variable("leaf", cel.StringType),
+				cel.Variable("root", cel.StringType),
+				cel.Variable("escaped", cel.StringType),
+				cel.Variable("partial", cel.StringType),
+				cel.Variable("xfcc", cel.StringType),
+			)
+			assert.NoError(t, err)
+			ast, issues := env.Compile(tt.source)
+			assert.NoError(t, issues.Err())
+			prog, err := env.Program(ast)
+			assert.NoError(t, err)
+			out, _, err := prog.Eval(map[string]any{
+				"leaf":    leaf,
+				"root":    root,
+				"escaped": url.PathEscape(leaf),
+				// only line breaks are encoded, '+' characters are kept as is
+				"partial": strings.ReplaceAll(leaf, "\n", "%0A"),
+				"xfcc":    xfcc,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			switch want := tt.want.(type) {
+			case []bool, []string:
+				got, err := out.ConvertToNative(reflect.TypeOf(want))
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			default:
+				assert.Equal(t, want, out.Value())
+			}
+		})
+	}
+}
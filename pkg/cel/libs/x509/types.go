@@ -0,0 +1,221 @@
+package x509
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/spiffe"
+)
+
+var (
+	CertificateType = types.NewObjectType("x509.Certificate")
+	NameType        = types.NewObjectType("x509.Name")
+	ClientCertType  = types.NewObjectType("x509.ClientCert")
+)
+
+type Name struct {
+	String             string   `cel:"string"`
+	CommonName         string   `cel:"commonName"`
+	SerialNumber       string   `cel:"serialNumber"`
+	Country            []string `cel:"country"`
+	Organization       []string `cel:"organization"`
+	OrganizationalUnit []string `cel:"organizationalUnit"`
+	Locality           []string `cel:"locality"`
+	Province           []string `cel:"province"`
+}
+
+type Certificate struct {
+	Subject        Name       `cel:"subject"`
+	Issuer         Name       `cel:"issuer"`
+	SerialNumber   string     `cel:"serialNumber"`
+	NotBefore      time.Time  `cel:"notBefore"`
+	NotAfter       time.Time  `cel:"notAfter"`
+	DNSNames       []string   `cel:"dnsNames"`
+	EmailAddresses []string   `cel:"emailAddresses"`
+	IPAddresses    []string   `cel:"ipAddresses"`
+	URIs           []string   `cel:"uris"`
+	SpiffeID       *spiffe.ID `cel:"spiffeId"`
+	IsCA           bool       `cel:"isCA"`
+	Fingerprint    string     `cel:"fingerprint"`
+}
+
+// ClientCert is an element of the x-forwarded-client-cert header, see
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_conn_man/headers#x-forwarded-client-cert
+type ClientCert struct {
+	By      []string      `cel:"by"`
+	Hash    string        `cel:"hash"`
+	Subject string        `cel:"subject"`
+	URIs    []string      `cel:"uris"`
+	DNS     []string      `cel:"dns"`
+	Cert    *Certificate  `cel:"cert"`
+	Chain   []Certificate `cel:"chain"`
+}
+
+func newName(in pkix.Name) Name {
+	return Name{
+		String:             in.String(),
+		CommonName:         in.CommonName,
+		SerialNumber:       in.SerialNumber,
+		Country:            nonNil(in.Country),
+		Organization:       nonNil(in.Organization),
+		OrganizationalUnit: nonNil(in.OrganizationalUnit),
+		Locality:           nonNil(in.Locality),
+		Province:           nonNil(in.Province),
+	}
+}
+
+func NewCertificate(in *x509.Certificate) Certificate {
+	fingerprint := sha256.Sum256(in.Raw)
+	out := Certificate{
+		Subject:        newName(in.Subject),
+		Issuer:         newName(in.Issuer),
+		SerialNumber:   in.SerialNumber.String(),
+		NotBefore:      in.NotBefore,
+		NotAfter:       in.NotAfter,
+		DNSNames:       nonNil(in.DNSNames),
+		EmailAddresses: nonNil(in.EmailAddresses),
+		IPAddresses:    []string{},
+		URIs:           []string{},
+		IsCA:           in.IsCA,
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+	}
+	for _, ip := range in.IPAddresses {
+		out.IPAddresses = append(out.IPAddresses, ip.String())
+	}
+	for _, uri := range in.URIs {
+		out.URIs = append(out.URIs, uri.String())
+		// a SVID contains exactly one URI SAN with the spiffe scheme
+		if uri.Scheme == "spiffe" && out.SpiffeID == nil {
+			if id, err := spiffe.ParseID(uri.String()); err == nil {
+				out.SpiffeID = &id
+			}
+		}
+	}
+	return out
+}
+
+// ParseCertificates parses all certificates in a PEM encoded string, the string can be URL encoded
+// (this is how Envoy forwards peer certificates).
+func ParseCertificates(in string) ([]Certificate, error) {
+	if strings.Contains(in, "%") {
+		// PathUnescape keeps '+' as is, it is part of the base64 alphabet
+		unescaped, err := url.PathUnescape(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to url decode certificate: %w", err)
+		}
+		in = unescaped
+	}
+	out := []Certificate{}
+	rest := []byte(in)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, NewCertificate(cert))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return out, nil
+}
+
+// ParseXFCC parses the x-forwarded-client-cert header, it returns one element per proxy hop.
+func ParseXFCC(in string) ([]ClientCert, error) {
+	out := []ClientCert{}
+	for _, element := range splitUnquoted(in, ',') {
+		if strings.TrimSpace(element) == "" {
+			continue
+		}
+		cc := ClientCert{
+			By:    []string{},
+			URIs:  []string{},
+			DNS:   []string{},
+			Chain: []Certificate{},
+		}
+		for _, pair := range splitUnquoted(element, ';') {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid x-forwarded-client-cert pair %q", pair)
+			}
+			value = unquote(value)
+			switch strings.ToLower(key) {
+			case "by":
+				cc.By = append(cc.By, value)
+			case "hash":
+				cc.Hash = value
+			case "subject":
+				cc.Subject = value
+			case "uri":
+				cc.URIs = append(cc.URIs, value)
+			case "dns":
+				cc.DNS = append(cc.DNS, value)
+			case "cert":
+				certs, err := ParseCertificates(value)
+				if err != nil {
+					return nil, err
+				}
+				cc.Cert = &certs[0]
+			case "chain":
+				certs, err := ParseCertificates(value)
+				if err != nil {
+					return nil, err
+				}
+				cc.Chain = certs
+			}
+		}
+		out = append(out, cc)
+	}
+	return out, nil
+}
+
+// splitUnquoted splits the input on sep, ignoring separators in double quoted sections.
+func splitUnquoted(in string, sep byte) []string {
+	var out []string
+	quoted, escaped, start := false, false, 0
+	for i := 0; i < len(in); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case in[i] == '\\' && quoted:
+			escaped = true
+		case in[i] == '"':
+			quoted = !quoted
+		case in[i] == sep && !quoted:
+			out = append(out, in[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, in[start:])
+}
+
+func unquote(in string) string {
+	if len(in) >= 2 && in[0] == '"' && in[len(in)-1] == '"' {
+		return strings.ReplaceAll(in[1:len(in)-1], `\"`, `"`)
+	}
+	return in
+}
+
+func nonNil(in []string) []string {
+	if in == nil {
+		return []string{}
+	}
+	return in
+}
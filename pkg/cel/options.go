@@ -0,0 +1,27 @@
+package cel
+
+import (
+	grpccel "github.com/kyverno/kyverno-authz/pkg/cel/libs/grpc"
+)
+
+// Option configures optional dependencies of the cel env
+type Option func(*options)
+
+type options struct {
+	descriptors *grpccel.Descriptors
+}
+
+func newOptions(opts ...Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithGrpcDescriptors sets the protobuf descriptors used to decode grpc messages
+func WithGrpcDescriptors(descriptors *grpccel.Descriptors) Option {
+	return func(o *options) {
+		o.descriptors = descriptors
+	}
+}
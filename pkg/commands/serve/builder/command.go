@@ -0,0 +1,370 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-logr/logr"
+	vpol "github.com/kyverno/api/api/policies.kyverno.io/v1"
+	authzcel "github.com/kyverno/kyverno-authz/pkg/cel"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/crypto"
+	vpolcompiler "github.com/kyverno/kyverno-authz/pkg/engine/compiler"
+	"github.com/kyverno/kyverno-authz/pkg/engine/sources"
+	"github.com/kyverno/kyverno-authz/pkg/events"
+	"github.com/kyverno/kyverno-authz/pkg/probes"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/kyverno/kyverno-authz/pkg/signals"
+	"github.com/kyverno/kyverno-authz/pkg/utils"
+	"github.com/kyverno/kyverno-authz/pkg/utils/ocifs"
+	"github.com/kyverno/sdk/core"
+	sdksources "github.com/kyverno/sdk/core/sources"
+	"github.com/kyverno/sdk/extensions/policy"
+	openreportsclient "github.com/openreports/reports-api/pkg/client/clientset/versioned/typed/openreports.io/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// Options describes a server command, IN and OUT are the policy input and output types
+// and EV is the request type pushed to event subscribers
+type Options[IN, OUT, EV any] struct {
+	// Use and Short are the cobra command name and description
+	Use   string
+	Short string
+	// Name identifies the server in the kube policy source, logs and errors
+	Name string
+	// Mode selects the policies loaded from the cluster
+	Mode vpol.EvaluationMode
+	// ReportName is the name of the openreports report, suffixed with a hash of the pod name
+	ReportName string
+	// Flags registers the flags specific to the command
+	Flags func(*pflag.FlagSet)
+	// CompilerOptions returns the options used to compile policies, it is called once the flags are parsed
+	CompilerOptions func() ([]authzcel.Option, error)
+	// HealthCheckInterval is the default of the health-check-interval flag, the flag is only registered when it is set
+	HealthCheckInterval time.Duration
+	// NoTLS doesn't register the cert-file and key-file flags, for servers that don't serve tls
+	NoTLS bool
+	// NewServer creates the server once the policy sources are loaded
+	NewServer func(context.Context, Params[IN, OUT, EV]) (server.Server, error)
+}
+
+// Params holds what is available to create a server
+type Params[IN, OUT, EV any] struct {
+	Logger logr.Logger
+	// KubeConfig is nil when no kubernetes cluster configuration was found
+	KubeConfig *rest.Config
+	Source     core.Source[policy.Policy[dynamic.Interface, IN, OUT]]
+	Dyn        dynamic.Interface
+	Events     events.EventIface[EV]
+	Ready      func() bool
+	CertFile   string
+	KeyFile    string
+	// HealthCheckInterval is how often the server refreshes its health status
+	HealthCheckInterval time.Duration
+}
+
+type flags struct {
+	probesAddress         string
+	metricsAddress        string
+	kubeConfigOverrides   clientcmd.ConfigOverrides
+	externalPolicySources []string
+	kubePolicySource      bool
+	imagePullSecrets      []string
+	allowInsecureRegistry bool
+	certFile              string
+	keyFile               string
+	msgFormat             string
+	eventsEnabled         bool
+	openreportsEnabled    bool
+	reportFlushInterval   string
+	resultBufSize         int
+	readinessMinPolicies  int
+	secretsRoot           string
+	healthCheckInterval   time.Duration
+}
+
+// Command returns a command loading policies from the cluster and external sources, running the probes
+// server and the server created by the options
+func Command[IN, OUT, EV any](opts Options[IN, OUT, EV]) *cobra.Command {
+	var f flags
+	command := &cobra.Command{
+		Use:   opts.Use,
+		Short: opts.Short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// setup signals aware context
+			return signals.Do(context.Background(), func(ctx context.Context) error {
+				return run(ctx, opts, f)
+			})
+		},
+	}
+	command.Flags().StringVar(&f.probesAddress, "probes-address", "", "Address to listen on for health checks")
+	command.Flags().StringVar(&f.metricsAddress, "metrics-address", ":9082", "Address to listen on for metrics")
+	command.Flags().StringArrayVar(&f.externalPolicySources, "external-policy-source", nil, "External policy sources")
+	command.Flags().StringArrayVar(&f.imagePullSecrets, "image-pull-secret", nil, "Image pull secrets")
+	command.Flags().BoolVar(&f.allowInsecureRegistry, "allow-insecure-registry", false, "Allow insecure registry")
+	command.Flags().BoolVar(&f.kubePolicySource, "kube-policy-source", true, "Enable in-cluster kubernetes policy source")
+	if opts.Flags != nil {
+		opts.Flags(command.Flags())
+	}
+	if !opts.NoTLS {
+		command.Flags().StringVar(&f.certFile, "cert-file", "", "File containing tls certificate")
+		command.Flags().StringVar(&f.keyFile, "key-file", "", "File containing tls private key")
+	}
+	if opts.HealthCheckInterval != 0 {
+		command.Flags().DurationVar(&f.healthCheckInterval, "health-check-interval", opts.HealthCheckInterval, "How often the grpc health service status is refreshed")
+	}
+	command.Flags().IntVar(&f.readinessMinPolicies, "readiness-min-policies", 1, "Minimum number of compiled policies required for the server to report ready (0 reports ready without policies)")
+	command.Flags().StringVar(&f.secretsRoot, "secrets-root", crypto.DefaultSecretsRoot, "Directory policies can read secret files from, secret files are disabled when empty")
+	command.Flags().StringVar(&f.msgFormat, "log-msg-format", "[%s] "+opts.Name+": request %s, response: %s\n", "The format in which request logs would be shown in stdout")
+	command.Flags().BoolVar(&f.eventsEnabled, "events-enabled", false, "Enable k8s events on authz, if not running in k8s this flag won't take effect")
+	command.Flags().BoolVar(&f.openreportsEnabled, "openreports-enabled", false, "Enable reporting in the openreports format, if not running in k8s or the openreports CRD is not installed this flag won't take effect")
+	command.Flags().StringVar(&f.reportFlushInterval, "report-flush-interval", "", "how often do results get flushed into the openreports report (if active)")
+	command.Flags().IntVar(&f.resultBufSize, "result-buffer-size", 500, "Event buffer size for openreports, note that if the total exceeded the 1MB etcd limit, report flushing will error")
+	clientcmd.BindOverrideFlags(&f.kubeConfigOverrides, command.Flags(), clientcmd.RecommendedConfigOverrideFlags("kube-"))
+	return command
+}
+
+func run[IN, OUT, EV any](ctx context.Context, opts Options[IN, OUT, EV], f flags) error {
+	// restrict the secret files policies can read
+	crypto.SetSecretsRoot(f.secretsRoot)
+	// track errors
+	var probesErr, serverErr, mgrErr error
+	err := func(ctx context.Context) error {
+		logger := ctrl.LoggerFrom(ctx)
+		kubeOk := true
+		// create a rest config
+		kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			clientcmd.NewDefaultClientConfigLoadingRules(),
+			&f.kubeConfigOverrides,
+		)
+		config, err := kubeConfig.ClientConfig()
+		if err != nil {
+			logger.Info("Warning, no kubernetes cluster configuration found, some features will be disabled")
+			kubeOk = false
+		}
+		// create a cancellable context
+		ctx, cancel := context.WithCancel(ctx)
+		// cancel context at the end
+		defer cancel()
+		// create a wait group
+		var group wait.Group
+		// wait all tasks in the group are over
+		defer group.Wait()
+		// readiness is reported as soon as the probes server is up and reflects the
+		// state of the policy sources as they get initialized
+		readiness := probes.NewReadiness(5 * time.Second)
+		var sourcesReady atomic.Bool
+		readiness.Add("sources", func(context.Context) error {
+			if !sourcesReady.Load() {
+				return errors.New("policy sources not initialized")
+			}
+			return nil
+		})
+		// probes server
+		if f.probesAddress != "" {
+			probesServer := probes.NewServer(f.probesAddress, readiness.Ready)
+			group.StartWithContext(ctx, func(ctx context.Context) {
+				defer cancel()
+				probesErr = probesServer.Run(ctx)
+			})
+		}
+
+		// compiler options
+		var compilerOptions []authzcel.Option
+		if opts.CompilerOptions != nil {
+			compilerOptions, err = opts.CompilerOptions()
+			if err != nil {
+				return err
+			}
+		}
+
+		eventHandlers := []events.EventIface[EV]{}
+		eventHandlers = append(eventHandlers, events.NewWriterEventSubscriber[EV](
+			os.Stdout,
+			logger,
+			f.msgFormat,
+		))
+
+		// load sources
+		var source core.Source[policy.Policy[dynamic.Interface, IN, OUT]]
+		var dyn dynamic.Interface
+		if kubeOk {
+			// Create kubernetes client
+			kubeclient, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+			// create dynamic client
+			dynclient, err := dynamic.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+			dyn = dynclient
+
+			// initialize compiler
+			compiler := vpolcompiler.NewCompiler[dynamic.Interface, IN, OUT](dynclient, compilerOptions...)
+
+			namespace, _, err := kubeConfig.Namespace()
+			if err != nil {
+				return fmt.Errorf("failed to get namespace from kubeconfig: %w", err)
+			}
+			if namespace == "" || namespace == "default" {
+				logger.Info(fmt.Sprintf("Using namespace '%s' - consider setting explicit namespace", namespace))
+			}
+
+			if f.eventsEnabled {
+				eventHandlers = append(eventHandlers, events.NewK8sEventSubscriber[EV](
+					ctx,
+					kubeclient,
+					namespace,
+					logger,
+					f.msgFormat,
+				))
+			}
+
+			if f.openreportsEnabled {
+				if exists, err := utils.CrdExists(config, "reports.openreports.io"); err != nil {
+					logger.Error(err, "failed to check if openreports CRD exists")
+				} else if exists {
+					orClient, err := openreportsclient.NewForConfig(config)
+					if err != nil {
+						logger.Error(err, "failed to instantiate openreports client")
+					} else {
+						// the parse duration function returns a zero duration on error
+						// hence why we need to create a pointer variable to easily differentiate the absence of this value
+						var intervalPtr *time.Duration
+						flushInterval, err := time.ParseDuration(f.reportFlushInterval)
+						if err == nil {
+							intervalPtr = &flushInterval
+						} else {
+							logger.Info("error parsing the reports flush interval, will push results to the report immediately")
+						}
+						reportName := opts.ReportName
+						if podName := os.Getenv("POD_NAME"); podName != "" {
+							podNameHash := xxhash.Sum64String(podName)
+							reportName = fmt.Sprintf("%s-%x", reportName, podNameHash)
+						} else {
+							logger.Info("POD_NAME environment variable not set, using default report name. there may be a clash")
+						}
+
+						eventHandlers = append(eventHandlers, events.NewOpenreportsSubscriber[EV](
+							ctx, f.resultBufSize,
+							orClient, intervalPtr, logger,
+							reportName, namespace, f.msgFormat))
+					}
+				}
+			}
+
+			rOpts, nOpts, err := ocifs.RegistryOpts(kubeclient.CoreV1().Secrets(namespace), f.allowInsecureRegistry, f.imagePullSecrets...)
+			if err != nil {
+				return fmt.Errorf("failed to initialize registry opts: %w", err)
+			}
+			extSources, err := utils.GetExternalSources(compiler, nOpts, rOpts, f.externalPolicySources...)
+			if err != nil {
+				return err
+			}
+			source = sdksources.NewComposite(extSources...)
+			// if kube policy source is enabled
+			if f.kubePolicySource {
+				// create a controller manager
+				scheme := runtime.NewScheme()
+				if err := vpol.Install(scheme); err != nil {
+					return err
+				}
+				mgr, err := ctrl.NewManager(config, ctrl.Options{
+					Scheme: scheme,
+					Metrics: metricsserver.Options{
+						BindAddress: f.metricsAddress,
+					},
+					Cache: cache.Options{
+						ByObject: map[client.Object]cache.ByObject{
+							&vpol.ValidatingPolicy{}: {
+								Field: fields.OneTermEqualSelector("spec.evaluation.mode", string(opts.Mode)),
+							},
+						},
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to construct manager: %w", err)
+				}
+				kubeSource, err := sources.NewKube(opts.Name, mgr, compiler)
+				if err != nil {
+					return fmt.Errorf("failed to create %s source: %w", opts.Name, err)
+				}
+				source = sdksources.NewComposite(kubeSource, source)
+				var cacheSynced atomic.Bool
+				readiness.Add("kube-cache", func(context.Context) error {
+					if !cacheSynced.Load() {
+						return errors.New("kube cache not synced")
+					}
+					return nil
+				})
+				// start manager
+				group.StartWithContext(ctx, func(ctx context.Context) {
+					// cancel context at the end
+					defer cancel()
+					mgrErr = mgr.Start(ctx)
+				})
+				if !mgr.GetCache().WaitForCacheSync(ctx) {
+					defer cancel()
+					return fmt.Errorf("failed to wait for %s cache sync", opts.Name)
+				}
+				cacheSynced.Store(true)
+			}
+		} else {
+			compiler := vpolcompiler.NewCompiler[dynamic.Interface, IN, OUT](nil, compilerOptions...)
+			rOpts, nOpts, err := ocifs.RegistryOpts(nil, f.allowInsecureRegistry)
+			if err != nil {
+				return fmt.Errorf("failed to initialize registry opts: %w", err)
+			}
+			extSources, err := utils.GetExternalSources(compiler, nOpts, rOpts, f.externalPolicySources...)
+			if err != nil {
+				return err
+			}
+			source = sdksources.NewComposite(extSources...)
+		}
+		readiness.Add("policies", sources.ReadinessCheck(source, f.readinessMinPolicies))
+		sourcesReady.Store(true)
+		// create and run the server
+		params := Params[IN, OUT, EV]{
+			Logger:              logger,
+			Source:              source,
+			Dyn:                 dyn,
+			Events:              events.NewComposite(eventHandlers...),
+			Ready:               readiness.Ready,
+			CertFile:            f.certFile,
+			KeyFile:             f.keyFile,
+			HealthCheckInterval: f.healthCheckInterval,
+		}
+		if kubeOk {
+			params.KubeConfig = config
+		}
+		s, err := opts.NewServer(ctx, params)
+		if err != nil {
+			return err
+		}
+		group.StartWithContext(ctx, func(ctx context.Context) {
+			defer cancel()
+			serverErr = s.Run(ctx)
+		})
+		return nil
+	}(ctx)
+	return multierr.Combine(err, probesErr, serverErr, mgrErr)
+}
@@ -3,6 +3,9 @@ package serve
 import (
 	"github.com/kyverno/kyverno-authz/pkg/commands/serve/envoy"
 	"github.com/kyverno/kyverno-authz/pkg/commands/serve/http"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/json"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/mcp"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/sar"
 	sidecarinjector "github.com/kyverno/kyverno-authz/pkg/commands/serve/sidecar-injector"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +17,9 @@ func Command() *cobra.Command {
 	}
 	command.AddCommand(envoy.Command())
 	command.AddCommand(http.Command())
+	command.AddCommand(json.Command())
+	command.AddCommand(mcp.Command())
+	command.AddCommand(sar.Command())
 	command.AddCommand(sidecarinjector.Command())
 	return command
 }
@@ -2,277 +2,58 @@ package authzserver
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"time"
 
-	"github.com/cespare/xxhash/v2"
-	vpol "github.com/kyverno/api/api/policies.kyverno.io/v1"
 	"github.com/kyverno/kyverno-authz/apis"
 	"github.com/kyverno/kyverno-authz/pkg/authz/http"
 	httplib "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
-	"github.com/kyverno/kyverno-authz/pkg/engine"
-	vpolcompiler "github.com/kyverno/kyverno-authz/pkg/engine/compiler"
-	"github.com/kyverno/kyverno-authz/pkg/engine/sources"
-	"github.com/kyverno/kyverno-authz/pkg/events"
-	"github.com/kyverno/kyverno-authz/pkg/probes"
-	"github.com/kyverno/kyverno-authz/pkg/signals"
-	"github.com/kyverno/kyverno-authz/pkg/utils"
-	"github.com/kyverno/kyverno-authz/pkg/utils/ocifs"
-	sdksources "github.com/kyverno/sdk/core/sources"
-	openreportsclient "github.com/openreports/reports-api/pkg/client/clientset/versioned/typed/openreports.io/v1alpha1"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/builder"
+	"github.com/kyverno/kyverno-authz/pkg/server"
 	"github.com/spf13/cobra"
-	"go.uber.org/multierr"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"github.com/spf13/pflag"
 )
 
 func Command() *cobra.Command {
-	// TODO: have a more sane way to store flag values
 	var (
-		probesAddress         string
-		metricsAddress        string
-		serverAddress         string
-		kubeConfigOverrides   clientcmd.ConfigOverrides
-		externalPolicySources []string
-		kubePolicySource      bool
-		imagePullSecrets      []string
-		allowInsecureRegistry bool
-		nestedRequest         bool
-		certFile              string
-		keyFile               string
-		inputExpression       string
-		outputExpression      string
-		msgFormat             string
-		eventsEnabled         bool
-		openreportsEnabled    bool
-		reportFlushInterval   string
-		resultBufSize         int
+		serverAddress    string
+		profile          string
+		nestedRequest    bool
+		inputExpression  string
+		outputExpression string
+		maxBodySize      int64
+		maxBatchSize     int
+		batchConcurrency int
 	)
-
-	command := &cobra.Command{
-		Use:   "authz-server",
-		Short: "Start the Kyverno Authz Server",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// setup signals aware context
-			return signals.Do(context.Background(), func(ctx context.Context) error {
-				// track errors
-				var probesErr, serverErr, mgrErr error
-				err := func(ctx context.Context) error {
-					logger := ctrl.LoggerFrom(ctx)
-					kubeOk := true
-					// create a rest config
-					kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-						clientcmd.NewDefaultClientConfigLoadingRules(),
-						&kubeConfigOverrides,
-					)
-					config, err := kubeConfig.ClientConfig()
-					if err != nil {
-						logger.Info("Warning, no kubernetes cluster configuration found, some features will be disabled")
-						kubeOk = false
-					}
-					// create a cancellable context
-					ctx, cancel := context.WithCancel(ctx)
-					// cancel context at the end
-					defer cancel()
-					// create a wait group
-					var group wait.Group
-					// wait all tasks in the group are over
-					defer group.Wait()
-
-					httpEventHandlers := []events.EventIface[httplib.CheckRequest]{}
-					httpEventHandlers = append(httpEventHandlers, events.NewWriterEventSubscriber[httplib.CheckRequest](
-						os.Stdout,
-						logger,
-						msgFormat,
-					))
-
-					// load sources
-					var source engine.HTTPSource
-					var dyn dynamic.Interface
-					if kubeOk {
-						// Create kubernetes client
-						kubeclient, err := kubernetes.NewForConfig(config)
-						if err != nil {
-							return err
-						}
-						// create dynamic client
-						dynclient, err := dynamic.NewForConfig(config)
-						if err != nil {
-							return err
-						}
-						dyn = dynclient
-
-						// initialize compiler
-						compiler := vpolcompiler.NewCompiler[dynamic.Interface, *httplib.CheckRequest, *httplib.CheckResponse](dynclient)
-
-						namespace, _, err := kubeConfig.Namespace()
-						if err != nil {
-							return fmt.Errorf("failed to get namespace from kubeconfig: %w", err)
-						}
-						if namespace == "" || namespace == "default" {
-							logger.Info(fmt.Sprintf("Using namespace '%s' - consider setting explicit namespace", namespace))
-						}
-
-						if eventsEnabled {
-							httpEventHandlers = append(httpEventHandlers, events.NewK8sEventSubscriber[httplib.CheckRequest](
-								ctx,
-								kubeclient,
-								namespace,
-								logger,
-								msgFormat,
-							))
-						}
-
-						if openreportsEnabled {
-							if exists, err := utils.CrdExists(config, "reports.openreports.io"); err != nil {
-								logger.Error(err, "failed to check if openreports CRD exists")
-							} else if exists {
-								orClient, err := openreportsclient.NewForConfig(config)
-								if err != nil {
-									logger.Error(err, "failed to instantiate openreports client")
-								} else {
-									// the parse duration function returns a zero duration on error
-									// hence why we need to create a pointer variable to easily differentiate the absence of this value
-									var intervalPtr *time.Duration
-									flushInterval, err := time.ParseDuration(reportFlushInterval)
-									if err == nil {
-										intervalPtr = &flushInterval
-									} else {
-										logger.Info("error parsing the reports flush interval, will push results to the report immediately")
-									}
-									reportName := "http-authz-report"
-									if podName := os.Getenv("POD_NAME"); podName != "" {
-										podNameHash := xxhash.Sum64String(podName)
-										reportName = fmt.Sprintf("%s-%x", reportName, podNameHash)
-									} else {
-										logger.Info("POD_NAME environment variable not set, using default report name. there may be a clash")
-									}
-
-									httpEventHandlers = append(httpEventHandlers, events.NewOpenreportsSubscriber[httplib.CheckRequest](
-										ctx, resultBufSize,
-										orClient, intervalPtr, logger,
-										reportName, namespace, msgFormat))
-								}
-							}
-						}
-
-						rOpts, nOpts, err := ocifs.RegistryOpts(kubeclient.CoreV1().Secrets(namespace), allowInsecureRegistry, imagePullSecrets...)
-						if err != nil {
-							return fmt.Errorf("failed to initialize registry opts: %w", err)
-						}
-						extSources, err := utils.GetExternalSources(compiler, nOpts, rOpts, externalPolicySources...)
-						if err != nil {
-							return err
-						}
-						source = sdksources.NewComposite(extSources...)
-						// if kube policy source is enabled
-						if kubePolicySource {
-							// create a controller manager
-							scheme := runtime.NewScheme()
-							if err := vpol.Install(scheme); err != nil {
-								return err
-							}
-							mgr, err := ctrl.NewManager(config, ctrl.Options{
-								Scheme: scheme,
-								Metrics: metricsserver.Options{
-									BindAddress: metricsAddress,
-								},
-								Cache: cache.Options{
-									ByObject: map[client.Object]cache.ByObject{
-										&vpol.ValidatingPolicy{}: {
-											Field: fields.OneTermEqualSelector("spec.evaluation.mode", string(apis.EvaluationModeHTTP)),
-										},
-									},
-								},
-							})
-							if err != nil {
-								return fmt.Errorf("failed to construct manager: %w", err)
-							}
-							kubeSource, err := sources.NewKube("http", mgr, compiler)
-							if err != nil {
-								return fmt.Errorf("failed to create http source: %w", err)
-							}
-							source = sdksources.NewComposite(kubeSource, source)
-							// start manager
-							group.StartWithContext(ctx, func(ctx context.Context) {
-								// cancel context at the end
-								defer cancel()
-								mgrErr = mgr.Start(ctx)
-							})
-							if !mgr.GetCache().WaitForCacheSync(ctx) {
-								defer cancel()
-								return fmt.Errorf("failed to wait for http cache sync")
-							}
-						}
-					} else {
-						compiler := vpolcompiler.NewCompiler[dynamic.Interface, *httplib.CheckRequest, *httplib.CheckResponse](nil)
-						rOpts, nOpts, err := ocifs.RegistryOpts(nil, allowInsecureRegistry)
-						if err != nil {
-							return fmt.Errorf("failed to initialize registry opts: %w", err)
-						}
-						extSources, err := utils.GetExternalSources(compiler, nOpts, rOpts, externalPolicySources...)
-						if err != nil {
-							return err
-						}
-						source = sdksources.NewComposite(extSources...)
-					}
-					// probes server
-					if probesAddress != "" {
-						probesServer := probes.NewServer(probesAddress)
-						group.StartWithContext(ctx, func(ctx context.Context) {
-							defer cancel()
-							probesErr = probesServer.Run(ctx)
-						})
-					}
-					// auth server
-					nestedRequest = true
-					httpConfig := http.Config{
-						Address:          serverAddress,
-						NestedRequest:    nestedRequest,
-						CertFile:         certFile,
-						KeyFile:          keyFile,
-						InputExpression:  inputExpression,
-						OutputExpression: outputExpression,
-					}
-
-					ev := events.NewComposite(httpEventHandlers...)
-					authServer := http.NewServer(httpConfig, source, dyn, ev)
-					group.StartWithContext(ctx, func(ctx context.Context) {
-						defer cancel()
-						serverErr = authServer.Run(ctx)
-					})
-					return nil
-				}(ctx)
-				return multierr.Combine(err, probesErr, serverErr, mgrErr)
-			})
+	return builder.Command(builder.Options[*httplib.CheckRequest, *httplib.CheckResponse, httplib.CheckRequest]{
+		Use:        "authz-server",
+		Short:      "Start the Kyverno Authz Server",
+		Name:       "http",
+		Mode:       apis.EvaluationModeHTTP,
+		ReportName: "http-authz-report",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&serverAddress, "server-address", ":9081", "Address to serve the http authorization server on")
+			flags.StringVar(&profile, "profile", "", "Forward auth profile used to map incoming requests and shape responses (caddy, envoy, ingress-nginx, oauth2-proxy, traefik)")
+			flags.BoolVar(&nestedRequest, "nested-request", false, "Expect the requests to validate to be in the body of the original request")
+			flags.StringVar(&inputExpression, "input-expression", "", "CEL expression for transforming the incoming request")
+			flags.StringVar(&outputExpression, "output-expression", "", "CEL expression for transforming responses before being sent to clients")
+			flags.Int64Var(&maxBodySize, "max-body-size", 1<<20, "Maximum size in bytes of the request body, requests with a larger body are rejected (0 means no limit)")
+			flags.IntVar(&maxBatchSize, "max-batch-size", 100, "Maximum number of requests accepted by the batch endpoint (0 means no limit)")
+			flags.IntVar(&batchConcurrency, "batch-concurrency", 10, "Maximum number of requests of a batch evaluated concurrently")
+		},
+		NewServer: func(_ context.Context, params builder.Params[*httplib.CheckRequest, *httplib.CheckResponse, httplib.CheckRequest]) (server.Server, error) {
+			nestedRequest = true
+			httpConfig := http.Config{
+				Address:          serverAddress,
+				Profile:          profile,
+				NestedRequest:    nestedRequest,
+				CertFile:         params.CertFile,
+				KeyFile:          params.KeyFile,
+				InputExpression:  inputExpression,
+				OutputExpression: outputExpression,
+				MaxBodySize:      maxBodySize,
+				MaxBatchSize:     maxBatchSize,
+				BatchConcurrency: batchConcurrency,
+			}
+			return http.NewServer(httpConfig, params.Source, params.Dyn, params.Events), nil
 		},
-	}
-	command.Flags().StringVar(&probesAddress, "probes-address", "", "Address to listen on for health checks")
-	command.Flags().StringVar(&metricsAddress, "metrics-address", ":9082", "Address to listen on for metrics")
-	command.Flags().StringArrayVar(&externalPolicySources, "external-policy-source", nil, "External policy sources")
-	command.Flags().StringArrayVar(&imagePullSecrets, "image-pull-secret", nil, "Image pull secrets")
-	command.Flags().BoolVar(&allowInsecureRegistry, "allow-insecure-registry", false, "Allow insecure registry")
-	command.Flags().BoolVar(&kubePolicySource, "kube-policy-source", true, "Enable in-cluster kubernetes policy source")
-	command.Flags().StringVar(&serverAddress, "server-address", ":9081", "Address to serve the http authorization server on")
-	command.Flags().BoolVar(&nestedRequest, "nested-request", false, "Expect the requests to validate to be in the body of the original request")
-	command.Flags().StringVar(&inputExpression, "input-expression", "", "CEL expression for transforming the incoming request")
-	command.Flags().StringVar(&outputExpression, "output-expression", "", "CEL expression for transforming responses before being sent to clients")
-	command.Flags().StringVar(&certFile, "cert-file", "", "File containing tls certificate")
-	command.Flags().StringVar(&keyFile, "key-file", "", "File containing tls private key")
-	command.Flags().StringVar(&msgFormat, "log-msg-format", "[%s] http: request %s, response: %s\n", "The format in which request logs would be shown in stdout")
-	command.Flags().BoolVar(&eventsEnabled, "events-enabled", false, "Enable k8s events on authz, if not running in k8s this flag won't take effect")
-	command.Flags().BoolVar(&openreportsEnabled, "openreports-enabled", false, "Enable reporting in the openreports format, if not running in k8s or the openreports CRD is not installed this flag won't take effect")
-	command.Flags().StringVar(&reportFlushInterval, "report-flush-interval", "", "how often do results get flushed into the openreports report (if active)")
-	command.Flags().IntVar(&resultBufSize, "result-buffer-size", 500, "Event buffer size for openreports, note that if the total exceeded the 1MB etcd limit, report flushing will error")
-	clientcmd.BindOverrideFlags(&kubeConfigOverrides, command.Flags(), clientcmd.RecommendedConfigOverrideFlags("kube-"))
-	return command
+	})
 }
@@ -2,6 +2,7 @@ package http
 
 import (
 	authzserver "github.com/kyverno/kyverno-authz/pkg/commands/serve/http/authz-server"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/http/proxy"
 	validationwebhook "github.com/kyverno/kyverno-authz/pkg/commands/serve/http/validation-webhook"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,7 @@ func Command() *cobra.Command {
 		Short: "Run Kyverno HTTP servers",
 	}
 	command.AddCommand(authzserver.Command())
+	command.AddCommand(proxy.Command())
 	command.AddCommand(validationwebhook.Command())
 	return command
 }
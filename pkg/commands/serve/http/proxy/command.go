@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/kyverno/kyverno-authz/apis"
+	"github.com/kyverno/kyverno-authz/pkg/authz/http"
+	httplib "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/builder"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func Command() *cobra.Command {
+	var (
+		serverAddress string
+		upstream      string
+		preserveHost  bool
+		maxBodySize   int64
+	)
+	return builder.Command(builder.Options[*httplib.CheckRequest, *httplib.CheckResponse, httplib.CheckRequest]{
+		Use:        "proxy",
+		Short:      "Start the Kyverno Authz reverse proxy",
+		Name:       "http",
+		Mode:       apis.EvaluationModeHTTP,
+		ReportName: "http-proxy-report",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&serverAddress, "server-address", ":9081", "Address to serve the reverse proxy on")
+			flags.StringVar(&upstream, "upstream", "", "URL of the upstream service allowed requests are forwarded to")
+			flags.BoolVar(&preserveHost, "preserve-host", false, "Forward the original host header to the upstream instead of the upstream host")
+			flags.Int64Var(&maxBodySize, "max-body-size", 1<<20, "Maximum size in bytes of the request body, requests with a larger body are rejected (0 means no limit)")
+		},
+		NewServer: func(_ context.Context, params builder.Params[*httplib.CheckRequest, *httplib.CheckResponse, httplib.CheckRequest]) (server.Server, error) {
+			proxyConfig := http.ProxyConfig{
+				Address:      serverAddress,
+				Upstream:     upstream,
+				PreserveHost: preserveHost,
+				MaxBodySize:  maxBodySize,
+				CertFile:     params.CertFile,
+				KeyFile:      params.KeyFile,
+			}
+			return http.NewProxyServer(proxyConfig, params.Source, params.Dyn, params.Events), nil
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package authzserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyverno/kyverno-authz/apis"
+	"github.com/kyverno/kyverno-authz/pkg/authz/generic"
+	genericcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/builder"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func Command() *cobra.Command {
+	var (
+		httpAddress         string
+		grpcAddress         string
+		grpcNetwork         string
+		maxBodySize         int64
+		healthCheckInterval time.Duration
+	)
+	return builder.Command(builder.Options[any, *genericcel.CheckResponse, any]{
+		Use:        "authz-server",
+		Short:      "Start the Kyverno Authz Server for json payloads",
+		Name:       "json",
+		Mode:       apis.EvaluationModeJSON,
+		ReportName: "json-authz-report",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&httpAddress, "http-address", ":9081", "Address to serve the http authorization server on (empty disables the http server)")
+			flags.StringVar(&grpcAddress, "grpc-address", ":9083", "Address to serve the grpc authorization server on (empty disables the grpc server)")
+			flags.StringVar(&grpcNetwork, "grpc-network", "tcp", "Network to listen on for the grpc authorization server")
+			flags.Int64Var(&maxBodySize, "max-body-size", 1<<20, "Maximum size in bytes of the http request body, requests with a larger body are rejected (0 means no limit)")
+			flags.DurationVar(&healthCheckInterval, "health-check-interval", 5*time.Second, "How often the grpc health service status is refreshed")
+		},
+		NewServer: func(_ context.Context, params builder.Params[any, *genericcel.CheckResponse, any]) (server.Server, error) {
+			jsonConfig := generic.Config{
+				HttpAddress:         httpAddress,
+				GrpcNetwork:         grpcNetwork,
+				GrpcAddress:         grpcAddress,
+				MaxBodySize:         maxBodySize,
+				CertFile:            params.CertFile,
+				KeyFile:             params.KeyFile,
+				HealthCheckInterval: healthCheckInterval,
+			}
+			return generic.NewServer(jsonConfig, params.Source, params.Dyn, params.Events, params.Ready), nil
+		},
+	})
+}
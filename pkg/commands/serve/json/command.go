@@ -0,0 +1,15 @@
+package json
+
+import (
+	authzserver "github.com/kyverno/kyverno-authz/pkg/commands/serve/json/authz-server"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "json",
+		Short: "Run Kyverno JSON servers",
+	}
+	command.AddCommand(authzserver.Command())
+	return command
+}
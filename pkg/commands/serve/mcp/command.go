@@ -0,0 +1,15 @@
+package mcp
+
+import (
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/mcp/gateway"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "mcp",
+		Short: "Run Kyverno MCP servers",
+	}
+	command.AddCommand(gateway.Command())
+	return command
+}
@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyverno/kyverno-authz/apis"
+	"github.com/kyverno/kyverno-authz/pkg/authz/mcpgateway"
+	mcpgatewaycel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/builder"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func Command() *cobra.Command {
+	var (
+		serverAddress      string
+		upstream           string
+		sessionIdleTimeout time.Duration
+		maxSessions        int
+		maxBodySize        int64
+	)
+	return builder.Command(builder.Options[*mcpgatewaycel.CheckRequest, *mcpgatewaycel.CheckResponse, mcpgatewaycel.CheckRequest]{
+		Use:        "gateway",
+		Short:      "Start the Kyverno Authz MCP gateway",
+		Name:       "mcp",
+		Mode:       apis.EvaluationModeMCP,
+		ReportName: "mcp-gateway-report",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&serverAddress, "server-address", ":9081", "Address to serve the mcp gateway on")
+			flags.StringVar(&upstream, "upstream", "", "URL of the upstream mcp server allowed messages are forwarded to")
+			flags.DurationVar(&sessionIdleTimeout, "session-idle-timeout", time.Hour, "How long a session is tracked without activity (0 means sessions never expire)")
+			flags.IntVar(&maxSessions, "max-sessions", 10000, "Maximum number of tracked sessions, the least recently used session is forgotten when the limit is reached (0 means no limit)")
+			flags.Int64Var(&maxBodySize, "max-body-size", 1<<20, "Maximum size in bytes of the request body, requests with a larger body are rejected (0 means no limit)")
+		},
+		NewServer: func(_ context.Context, params builder.Params[*mcpgatewaycel.CheckRequest, *mcpgatewaycel.CheckResponse, mcpgatewaycel.CheckRequest]) (server.Server, error) {
+			gatewayConfig := mcpgateway.Config{
+				Address:            serverAddress,
+				Upstream:           upstream,
+				MaxBodySize:        maxBodySize,
+				SessionIdleTimeout: sessionIdleTimeout,
+				MaxSessions:        maxSessions,
+				CertFile:           params.CertFile,
+				KeyFile:            params.KeyFile,
+			}
+			return mcpgateway.NewServer(gatewayConfig, params.Source, params.Dyn, params.Events), nil
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package authzserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kyverno/kyverno-authz/apis"
+	"github.com/kyverno/kyverno-authz/pkg/authz/sar"
+	sarcel "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
+	"github.com/kyverno/kyverno-authz/pkg/certmanager"
+	"github.com/kyverno/kyverno-authz/pkg/commands/serve/builder"
+	"github.com/kyverno/kyverno-authz/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func Command() *cobra.Command {
+	var (
+		serverAddress          string
+		internalCertManagement bool
+		webhookNamespace       string
+		webhookServiceName     string
+	)
+	return builder.Command(builder.Options[*sarcel.CheckRequest, *sarcel.CheckResponse, sarcel.CheckRequest]{
+		Use:        "authz-server",
+		Short:      "Start the Kyverno Authz Server for kube-apiserver authorization webhook requests",
+		Name:       "sar",
+		Mode:       apis.EvaluationModeSubjectAccessReview,
+		ReportName: "sar-authz-report",
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&serverAddress, "server-address", ":9081", "Address to serve the authorization webhook on")
+			flags.BoolVar(&internalCertManagement, "internal-cert-management", false, "Enable Kyverno internal certificate management, takes precedence over --cert-file and --key-file")
+			flags.StringVar(&webhookNamespace, "webhook-namespace", "kyverno", "Namespace where webhook service and secrets are created")
+			flags.StringVar(&webhookServiceName, "webhook-service-name", "kyverno-authz-server-sar", "Webhook service name used for TLS certificate generation")
+		},
+		NewServer: func(ctx context.Context, params builder.Params[*sarcel.CheckRequest, *sarcel.CheckResponse, sarcel.CheckRequest]) (server.Server, error) {
+			// the kube-apiserver only calls webhooks over https, bootstrap certificates if requested
+			if internalCertManagement {
+				if params.KubeConfig == nil {
+					return nil, errors.New("internal certificate management requires a kubernetes cluster")
+				}
+				certDir, _, err := certmanager.BootstrapWebhookCerts(ctx, params.Logger.WithName("certmanager"), params.KubeConfig, webhookNamespace, webhookServiceName)
+				if err != nil {
+					return nil, fmt.Errorf("failed to bootstrap webhook certs: %w", err)
+				}
+				params.CertFile = filepath.Join(certDir, "tls.crt")
+				params.KeyFile = filepath.Join(certDir, "tls.key")
+			}
+			sarConfig := sar.Config{
+				Address:  serverAddress,
+				CertFile: params.CertFile,
+				KeyFile:  params.KeyFile,
+			}
+			return sar.NewServer(sarConfig, params.Source, params.Dyn, params.Events), nil
+		},
+	})
+}
@@ -0,0 +1,15 @@
+package sar
+
+import (
+	authzserver "github.com/kyverno/kyverno-authz/pkg/commands/serve/sar/authz-server"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "sar",
+		Short: "Run Kyverno SubjectAccessReview servers",
+	}
+	command.AddCommand(authzserver.Command())
+	return command
+}
@@ -9,7 +9,10 @@ import (
 	"github.com/kyverno/kyverno-authz/apis"
 	authzcel "github.com/kyverno/kyverno-authz/pkg/cel"
 	envoy "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/envoy"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
 	httpauth "github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
 	"github.com/kyverno/sdk/cel/libs/http"
 	"github.com/kyverno/sdk/cel/libs/imagedata"
 	"github.com/kyverno/sdk/cel/libs/resource"
@@ -28,14 +31,16 @@ const (
 	ResourceKey  = "resource"
 )
 
-func NewCompiler[DATA dynamic.Interface, IN, OUT any](client DATA) *compiler[DATA, IN, OUT] {
+func NewCompiler[DATA dynamic.Interface, IN, OUT any](client DATA, opts ...authzcel.Option) *compiler[DATA, IN, OUT] {
 	return &compiler[DATA, IN, OUT]{
 		client: client,
+		opts:   opts,
 	}
 }
 
 type compiler[DATA dynamic.Interface, IN, OUT any] struct {
 	client DATA
+	opts   []authzcel.Option
 }
 
 // exceptions that are passed here are guaranteed to be matching the policy. they are filtered in the kube policy source
@@ -61,7 +66,7 @@ func (c *compiler[DATA, IN, OUT]) Compile(policy *v1.ValidatingPolicy, exception
 func (c *compiler[DATA, IN, OUT]) compile(policy *v1.ValidatingPolicy, exceptions []*v1.PolicyException) (
 	*compiledPolicy[DATA, IN, OUT], field.ErrorList) {
 	var allErrs field.ErrorList
-	base, err := authzcel.NewEnv(policy.Spec.EvaluationMode(), c.client)
+	base, err := authzcel.NewEnv(policy.Spec.EvaluationMode(), c.client, c.opts...)
 	if err != nil {
 		return nil, append(allErrs, field.InternalError(nil, err))
 	}
@@ -71,6 +76,13 @@ func (c *compiler[DATA, IN, OUT]) compile(policy *v1.ValidatingPolicy, exception
 		objectKey = cel.Variable(ObjectKey, envoy.CheckRequest)
 	case apis.EvaluationModeHTTP:
 		objectKey = cel.Variable(ObjectKey, httpauth.RequestType)
+	case apis.EvaluationModeJSON:
+		// the payload is arbitrary json
+		objectKey = cel.Variable(ObjectKey, types.DynType)
+	case apis.EvaluationModeMCP:
+		objectKey = cel.Variable(ObjectKey, mcpgateway.RequestType)
+	case apis.EvaluationModeSubjectAccessReview:
+		objectKey = cel.Variable(ObjectKey, sar.RequestType)
 	default:
 		return nil, append(allErrs, field.InternalError(nil, fmt.Errorf("invalid policy evaluation mode: %s", policy.Spec.EvaluationMode())))
 	}
@@ -174,6 +186,21 @@ func (c *compiler[DATA, IN, OUT]) compileAuthorization(path *field.Path, evalMod
 				msg := fmt.Sprintf("rule response output is expected to be of type %s", httpauth.ResponseType.TypeName())
 				return nil, append(allErrs, field.Invalid(path, rule.Expression, msg))
 			}
+		case apis.EvaluationModeJSON:
+			if !ast.OutputType().IsExactType(generic.ResponseType) && !ast.OutputType().IsExactType(types.NullType) {
+				msg := fmt.Sprintf("rule response output is expected to be of type %s", generic.ResponseType.TypeName())
+				return nil, append(allErrs, field.Invalid(path, rule.Expression, msg))
+			}
+		case apis.EvaluationModeMCP:
+			if !ast.OutputType().IsExactType(mcpgateway.ResponseType) && !ast.OutputType().IsExactType(types.NullType) {
+				msg := fmt.Sprintf("rule response output is expected to be of type %s", mcpgateway.ResponseType.TypeName())
+				return nil, append(allErrs, field.Invalid(path, rule.Expression, msg))
+			}
+		case apis.EvaluationModeSubjectAccessReview:
+			if !ast.OutputType().IsExactType(sar.ResponseType) && !ast.OutputType().IsExactType(types.NullType) {
+				msg := fmt.Sprintf("rule response output is expected to be of type %s", sar.ResponseType.TypeName())
+				return nil, append(allErrs, field.Invalid(path, rule.Expression, msg))
+			}
 		}
 		prog, err := env.Program(ast)
 		if err != nil {
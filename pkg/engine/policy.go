@@ -2,7 +2,10 @@ package engine
 
 import (
 	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
 	"github.com/kyverno/sdk/extensions/policy"
 	"k8s.io/client-go/dynamic"
 )
@@ -10,6 +13,11 @@ import (
 type EnvoyPolicy = policy.Policy[dynamic.Interface, *authv3.CheckRequest, *authv3.CheckResponse]
 type HTTPPolicy = policy.Policy[dynamic.Interface, *http.CheckRequest, *http.CheckResponse]
 
+// JSONPolicy evaluates arbitrary json payloads, decoded into maps, slices and scalar values
+type JSONPolicy = policy.Policy[dynamic.Interface, any, *generic.CheckResponse]
+type MCPPolicy = policy.Policy[dynamic.Interface, *mcpgateway.CheckRequest, *mcpgateway.CheckResponse]
+type SubjectAccessReviewPolicy = policy.Policy[dynamic.Interface, *sar.CheckRequest, *sar.CheckResponse]
+
 // Named is an optional interface that a Policy may implement to expose its name.
 // This is used for per-policy observability (metrics, logging).
 type Named interface {
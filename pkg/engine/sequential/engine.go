@@ -0,0 +1,51 @@
+package sequential
+
+import (
+	"context"
+
+	"github.com/kyverno/kyverno-authz/pkg/metrics"
+	"github.com/kyverno/sdk/core"
+	"github.com/kyverno/sdk/core/dispatchers"
+	"github.com/kyverno/sdk/core/handlers"
+	"github.com/kyverno/sdk/core/resulters"
+	"github.com/kyverno/sdk/extensions/policy"
+	"k8s.io/client-go/dynamic"
+)
+
+type Engine[REQ, RESP any] = core.Engine[dynamic.Interface, REQ, policy.Evaluation[*RESP]]
+
+// NewEngine builds an engine evaluating policies sequentially until one of them returns a result,
+// decision maps a policy result to metrics.DecisionAllow or metrics.DecisionDeny
+func NewEngine[REQ, RESP any](source core.Source[policy.Policy[dynamic.Interface, REQ, *RESP]], decision func(*RESP) string) Engine[REQ, RESP] {
+	type POLICY = policy.Policy[dynamic.Interface, REQ, *RESP]
+	type OUT = policy.Evaluation[*RESP]
+	return core.NewEngine(
+		source,
+		handlers.Handler(
+			dispatchers.Sequential(
+				metrics.MetricsEvaluatorFactory(
+					policy.EvaluatorFactory[POLICY](),
+					func(out OUT) string {
+						if out.Error != nil {
+							return metrics.DecisionError
+						}
+						if out.Result == nil {
+							return metrics.DecisionNoMatch
+						}
+						return decision(out.Result)
+					},
+				),
+				func(ctx context.Context, fc core.FactoryContext[POLICY, dynamic.Interface, REQ]) core.Breaker[POLICY, REQ, OUT] {
+					return core.MakeBreakerFunc(func(_ context.Context, _ POLICY, _ REQ, out OUT) bool {
+						return out.Result != nil
+					})
+				},
+			),
+			func(ctx context.Context, fc core.FactoryContext[POLICY, dynamic.Interface, REQ]) core.Resulter[POLICY, REQ, OUT, OUT] {
+				return resulters.NewFirst[POLICY, REQ](func(out OUT) bool {
+					return out.Result != nil || out.Error != nil
+				})
+			},
+		),
+	)
+}
@@ -6,3 +6,6 @@ import (
 
 type EnvoySource = core.Source[EnvoyPolicy]
 type HTTPSource = core.Source[HTTPPolicy]
+type JSONSource = core.Source[JSONPolicy]
+type MCPSource = core.Source[MCPPolicy]
+type SubjectAccessReviewSource = core.Source[SubjectAccessReviewPolicy]
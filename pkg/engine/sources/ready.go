@@ -0,0 +1,23 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyverno/sdk/core"
+)
+
+// ReadinessCheck returns a check that succeeds when the source loads without error
+// and provides at least minPolicies compiled policies.
+func ReadinessCheck[POLICY any](source core.Source[POLICY], minPolicies int) func(context.Context) error {
+	return func(ctx context.Context) error {
+		policies, err := source.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load policies: %w", err)
+		}
+		if len(policies) < minPolicies {
+			return fmt.Errorf("%d policies loaded, at least %d required", len(policies), minPolicies)
+		}
+		return nil
+	}
+}
@@ -6,13 +6,18 @@ import (
 	"time"
 
 	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/generic"
 	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/http"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/mcpgateway"
+	"github.com/kyverno/kyverno-authz/pkg/cel/libs/authz/sar"
 )
 
 var (
 	RequestAllowed string = "Allowed"
 	RequestDenied  string = "Denied"
 	RequestErrored string = "Errored"
+	// RequestNoOpinion is reported when the decision is delegated to another authorizer
+	RequestNoOpinion string = "NoOpinion"
 )
 
 type EventIface[Req any] interface {
@@ -56,6 +61,24 @@ func (r *resultAccessorImpl) MustGet() (string, error) {
 			return RequestDenied, nil
 		}
 		return RequestAllowed, nil
+	case generic.CheckResponse:
+		if !res.Allowed {
+			return RequestDenied, nil
+		}
+		return RequestAllowed, nil
+	case mcpgateway.CheckResponse:
+		if !res.Allowed {
+			return RequestDenied, nil
+		}
+		return RequestAllowed, nil
+	case sar.CheckResponse:
+		if res.Denied {
+			return RequestDenied, nil
+		}
+		if res.Allowed {
+			return RequestAllowed, nil
+		}
+		return RequestNoOpinion, nil
 	default:
 		// should never happen, if it does then that's a coding error
 		panic(fmt.Sprintf("got an unknown type of result in the accessor %T", res))
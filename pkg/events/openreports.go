@@ -20,6 +20,7 @@ type openreportsEventSubscriber[Req any] struct {
 	allowed       atomic.Int64
 	denied        atomic.Int64
 	errored       atomic.Int64
+	skipped       atomic.Int64
 	namespace     string
 	reportName    string
 	msgFormat     string
@@ -110,6 +111,9 @@ func (o *openreportsEventSubscriber[Req]) newReportResult(t time.Time, r Req, re
 	case RequestErrored:
 		reportResult.Result = openreportsv1alpha1.Result("error")
 		o.errored.Add(1)
+	case RequestNoOpinion:
+		reportResult.Result = openreportsv1alpha1.Result("skip")
+		o.skipped.Add(1)
 	}
 	reportResult.Timestamp = metav1.Timestamp{
 		Seconds: t.Unix(),
@@ -146,6 +150,7 @@ func (o *openreportsEventSubscriber[Req]) newReport() *openreportsv1alpha1.Repor
 			Error: int(o.errored.Load()),
 			Pass:  int(o.allowed.Load()),
 			Fail:  int(o.denied.Load()),
+			Skip:  int(o.skipped.Load()),
 		},
 		Results: o.results.Values(),
 	}
@@ -177,6 +182,7 @@ func (o *openreportsEventSubscriber[Req]) pushReport(ctx context.Context) {
 	rep.Summary.Error = int(o.errored.Load())
 	rep.Summary.Pass = int(o.allowed.Load())
 	rep.Summary.Fail = int(o.denied.Load())
+	rep.Summary.Skip = int(o.skipped.Load())
 
 	_, err = o.client.Reports(o.namespace).Update(ctx, rep, metav1.UpdateOptions{})
 	if err != nil {
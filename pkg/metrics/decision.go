@@ -10,6 +10,9 @@ import (
 const (
 	ModeHTTP  = "http"
 	ModeEnvoy = "envoy"
+	ModeJSON  = "json"
+	ModeMCP   = "mcp"
+	ModeSAR   = "sar"
 
 	SourcePolicy  = "policy"
 	SourceEngine  = "engine"
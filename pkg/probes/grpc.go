@@ -0,0 +1,43 @@
+package probes
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// RegisterGrpcHealth registers the grpc.health.v1 service on the given server and keeps the serving
+// status of the overall server and of the given services in sync with the readiness function until
+// the context is cancelled.
+func RegisterGrpcHealth(ctx context.Context, s *grpc.Server, ready func() bool, interval time.Duration, services ...string) {
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	update := func() {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		hs.SetServingStatus("", status)
+		for _, service := range services {
+			hs.SetServingStatus(service, status)
+		}
+	}
+	update()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				// report not serving to all watchers while the server drains
+				hs.Shutdown()
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	}()
+}
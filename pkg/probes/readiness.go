@@ -0,0 +1,64 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Check returns an error when the component it watches is not ready.
+type Check func(context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness aggregates named checks, it is ready when all checks succeed.
+type Readiness struct {
+	lock    sync.RWMutex
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{
+		timeout: timeout,
+	}
+}
+
+func (r *Readiness) Add(name string, check Check) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+func (r *Readiness) Check(ctx context.Context) error {
+	r.lock.RLock()
+	checks := r.checks
+	r.lock.RUnlock()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	var errs []error
+	for _, check := range checks {
+		if err := check.check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.name, err))
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+func (r *Readiness) Ready() bool {
+	if err := r.Check(context.Background()); err != nil {
+		ctrl.Log.V(2).Info("not ready", "reason", err.Error())
+		return false
+	}
+	return true
+}
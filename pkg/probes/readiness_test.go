@@ -0,0 +1,51 @@
+package probes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  map[string]Check
+		want    bool
+		wantErr string
+	}{{
+		name: "no checks",
+		want: true,
+	}, {
+		name: "all checks pass",
+		checks: map[string]Check{
+			"foo": func(context.Context) error { return nil },
+			"bar": func(context.Context) error { return nil },
+		},
+		want: true,
+	}, {
+		name: "one check fails",
+		checks: map[string]Check{
+			"foo": func(context.Context) error { return nil },
+			"bar": func(context.Context) error { return errors.New("not synced") },
+		},
+		want:    false,
+		wantErr: "bar: not synced",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readiness := NewReadiness(0)
+			for name, check := range tt.checks {
+				readiness.Add(name, check)
+			}
+			assert.Equal(t, tt.want, readiness.Ready())
+			err := readiness.Check(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,14 +8,14 @@ import (
 	"github.com/kyverno/kyverno-authz/pkg/server/handlers"
 )
 
-func NewServer(addr string) server.ServerFunc {
+func NewServer(addr string, ready func() bool) server.ServerFunc {
 	return func(ctx context.Context) error {
 		// create mux
 		mux := http.NewServeMux()
 		// register health check
 		mux.Handle("GET /livez", handlers.Healthy(True))
 		// register ready check
-		mux.Handle("GET /readyz", handlers.Ready(True))
+		mux.Handle("GET /readyz", handlers.Ready(ready))
 		// create server
 		s := &http.Server{
 			Addr:    addr,
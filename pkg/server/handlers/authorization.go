@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// SubjectAccessReview serves the kube-apiserver authorization webhook protocol, the inner function
+// computes the status of the review and the review is sent back with its status set
+func SubjectAccessReview(inner func(context.Context, *authorizationv1.SubjectAccessReview) authorizationv1.SubjectAccessReviewStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			HttpError(r.Context(), w, r, errors.New("empty body"), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close() //nolint:errcheck
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			HttpError(r.Context(), w, r, err, http.StatusBadRequest)
+			return
+		}
+		contentType := r.Header.Get("Content-Type")
+		if contentType != "application/json" {
+			HttpError(r.Context(), w, r, errors.New("invalid Content-Type"), http.StatusUnsupportedMediaType)
+			return
+		}
+		var review authorizationv1.SubjectAccessReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			HttpError(r.Context(), w, r, err, http.StatusBadRequest)
+			return
+		}
+		// only the v1 version of the protocol is supported, it must be configured in the kube-apiserver
+		// with --authorization-webhook-version=v1 or in the authorization configuration file
+		if gvk := review.GroupVersionKind(); gvk != authorizationv1.SchemeGroupVersion.WithKind("SubjectAccessReview") {
+			HttpError(r.Context(), w, r, fmt.Errorf("unsupported review %s", gvk), http.StatusBadRequest)
+			return
+		}
+		review.Status = inner(r.Context(), &review)
+		responseJSON, err := json.Marshal(review)
+		if err != nil {
+			HttpError(r.Context(), w, r, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := w.Write(responseJSON); err != nil {
+			HttpError(r.Context(), w, r, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package filecache
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache keeps values parsed from files in memory, files are reloaded when they change so that a Secret or a ConfigMap
+// mounted as a volume is picked up when it is updated, without restarting the process
+type Cache[T any] struct {
+	parse   func([]byte) (T, error)
+	maxSize int64
+	group   singleflight.Group
+	lock    sync.Mutex
+	files   map[string]*entry[T]
+}
+
+type entry[T any] struct {
+	info  os.FileInfo
+	value T
+	err   error
+}
+
+// New creates a cache parsing files with parse, files larger than maxSize bytes are rejected (0 means no limit)
+func New[T any](parse func([]byte) (T, error), maxSize int64) *Cache[T] {
+	return &Cache[T]{
+		parse:   parse,
+		maxSize: maxSize,
+		files:   map[string]*entry[T]{},
+	}
+}
+
+// Load returns the value parsed from the file at path.
+// When a file changed but can't be parsed anymore, the value parsed from its previous version is returned.
+func (c *Cache[T]) Load(path string) (T, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if cached, ok := c.get(path); ok && unchanged(cached.info, info) {
+		return cached.value, cached.err
+	}
+	// concurrent callers share the same reload
+	out, _, _ := c.group.Do(path, func() (any, error) {
+		return c.reload(path), nil
+	})
+	loaded := out.(*entry[T])
+	return loaded.value, loaded.err
+}
+
+func (c *Cache[T]) get(path string) (*entry[T], bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached, ok := c.files[path]
+	return cached, ok
+}
+
+func (c *Cache[T]) reload(path string) *entry[T] {
+	info, err := os.Stat(path)
+	if err != nil {
+		return &entry[T]{err: err}
+	}
+	previous, ok := c.get(path)
+	if ok && unchanged(previous.info, info) {
+		return previous
+	}
+	loaded := &entry[T]{info: info}
+	loaded.value, loaded.err = c.read(path, info)
+	if loaded.err != nil && ok && previous.err == nil {
+		// keep serving the previous version, the file is parsed again when it changes
+		loaded.value, loaded.err = previous.value, nil
+	}
+	c.lock.Lock()
+	c.files[path] = loaded
+	c.lock.Unlock()
+	return loaded
+}
+
+func (c *Cache[T]) read(path string, info os.FileInfo) (T, error) {
+	var zero T
+	if c.maxSize > 0 && info.Size() > c.maxSize {
+		return zero, fmt.Errorf("file %q is larger than %d bytes", path, c.maxSize)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return zero, err
+	}
+	defer file.Close()
+	reader := io.Reader(file)
+	if c.maxSize > 0 {
+		reader = io.LimitReader(file, c.maxSize)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return zero, err
+	}
+	value, err := c.parse(data)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	return value, nil
+}
+
+// unchanged compares the files stats, a symlink swapped to another file (like the ..data link of a Kubernetes volume)
+// is a change even when the new file has the same size and modification time
+func unchanged(previous, current os.FileInfo) bool {
+	return os.SameFile(previous, current) && previous.ModTime().Equal(current.ModTime()) && previous.Size() == current.Size()
+}
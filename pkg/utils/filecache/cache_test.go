@@ -0,0 +1,115 @@
+package filecache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	var parsed atomic.Int32
+	cache := New(func(data []byte) (string, error) {
+		parsed.Add(1)
+		if strings.HasPrefix(string(data), "invalid") {
+			return "", errors.New("invalid content")
+		}
+		return string(data), nil
+	}, 8)
+	write := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	now := time.Now()
+	t.Run("unchanged files are not parsed again", func(t *testing.T) {
+		write("v1", now)
+		for range 3 {
+			got, err := cache.Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, "v1", got)
+		}
+		assert.Equal(t, int32(1), parsed.Load())
+	})
+	t.Run("files are reloaded when they change", func(t *testing.T) {
+		write("v2", now.Add(time.Minute))
+		got, err := cache.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, "v2", got)
+	})
+	t.Run("invalid files keep the previous version", func(t *testing.T) {
+		parsed.Store(0)
+		write("invalid", now.Add(2*time.Minute))
+		for range 3 {
+			got, err := cache.Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, "v2", got)
+		}
+		assert.Equal(t, int32(1), parsed.Load())
+		write("v3", now.Add(3*time.Minute))
+		got, err := cache.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, "v3", got)
+	})
+	t.Run("symlinks swapped to another file are reloaded", func(t *testing.T) {
+		link := filepath.Join(dir, "link")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("b"), 0o600))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "a"), now, now))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "b"), now, now))
+		require.NoError(t, os.Symlink("a", link))
+		got, err := cache.Load(link)
+		require.NoError(t, err)
+		assert.Equal(t, "a", got)
+		require.NoError(t, os.Remove(link))
+		require.NoError(t, os.Symlink("b", link))
+		got, err = cache.Load(link)
+		require.NoError(t, err)
+		assert.Equal(t, "b", got)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := cache.Load(filepath.Join(dir, "missing"))
+		assert.Error(t, err)
+		invalid := filepath.Join(dir, "invalid")
+		require.NoError(t, os.WriteFile(invalid, []byte("invalid"), 0o600))
+		_, err = cache.Load(invalid)
+		assert.ErrorContains(t, err, "invalid content")
+		large := filepath.Join(dir, "large")
+		require.NoError(t, os.WriteFile(large, []byte("too large"), 0o600))
+		_, err = cache.Load(large)
+		assert.ErrorContains(t, err, "is larger than 8 bytes")
+	})
+}
+
+func TestCache_SharedReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+	var parsed atomic.Int32
+	release := make(chan struct{})
+	cache := New(func(data []byte) (string, error) {
+		parsed.Add(1)
+		<-release
+		return string(data), nil
+	}, 0)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			got, err := cache.Load(path)
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", got)
+		})
+	}
+	// let the callers join the reload before parsing
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), parsed.Load())
+}